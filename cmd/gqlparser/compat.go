@@ -0,0 +1,67 @@
+package main
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/diff"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   "encoding/json"
+   "flag"
+   "fmt"
+   log "github.com/sirupsen/logrus"
+   "os"
+   "strings"
+)
+
+// compat
+// check the published <type>.json schemas against the ones the current SDL generates with the registry's
+// schema-evolution rules, before cfn submit. Exit 1 when any fails
+func compat(args []string) {
+   flags := flag.NewFlagSet("compat", flag.ExitOnError)
+   schema := flags.String("schema", "schema.graphql", "File containing the current GraphQL Schema")
+   configFile := flags.String("config", "", "JSON file with the generator's settings")
+   mutations := flags.String("mutations", "", "Comma separated list of mutation prefixes the types are generated from. Empty == all")
+   report := flags.String("report", "", "File to write the verdicts to as JSON")
+   logLevel := flags.String("logLevel", "warn", "logrus logging level panic | fatal | error | warn | info | debug | trace")
+   flags.Usage = func() {
+      fmt.Fprintf(flags.Output(), "Usage: gqlparser compat [flags] <published type>.json...\n")
+      flags.PrintDefaults()
+   }
+   flags.Parse(args)
+   if flags.NArg() == 0 {
+      flags.Usage()
+      os.Exit(2)
+   }
+
+   setLogLevel(*logLevel)
+   config := loadConfig(*configFile)
+   services := newServices(loadSchema(*schema), config, strings.Split(*mutations, ","), false, false)
+   current := make(map[string]*model.Document, len(services))
+   for _, service := range services {
+      doc := service.Document()
+      current[doc.TypeName] = doc
+   }
+
+   verdicts := make([]*diff.Verdict, 0, flags.NArg())
+   pass := true
+   for _, file := range flags.Args() {
+      published, err := model.ReadDocument(file)
+      if err != nil {
+         log.Fatalf("compat: %v", err)
+      }
+      verdict := diff.Compat(file, published, current[published.TypeName])
+      fmt.Print(verdict.Text())
+      verdicts = append(verdicts, verdict)
+      pass = pass && verdict.Pass
+   }
+   if *report != "" {
+      b, err := json.MarshalIndent(verdicts, "", "   ")
+      if err != nil {
+         log.Fatalf("compat: %v", err)
+      }
+      if err = os.WriteFile(*report, append(b, '\n'), 0644); err != nil {
+         log.Fatalf("compat: %v", err)
+      }
+   }
+   if !pass {
+      os.Exit(1)
+   }
+}
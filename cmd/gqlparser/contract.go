@@ -0,0 +1,92 @@
+package main
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/contract"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+   "context"
+   "encoding/json"
+   "flag"
+   "fmt"
+   log "github.com/sirupsen/logrus"
+   "os"
+   "path/filepath"
+   "sort"
+   "strings"
+)
+
+// contractTest
+// run the resource lifecycle against an endpoint (e.g. mock-server) or a handler command, with each project's
+// inputs/inputs_1_create.json and inputs_1_update.json
+func contractTest(args []string) {
+   flags := flag.NewFlagSet("contract", flag.ExitOnError)
+   schema := flags.String("schema", "schema.graphql", "File containing the GraphQL Schema")
+   configFile := flags.String("config", "", "JSON file with the generator's settings")
+   mutations := flags.String("mutations", "", "Comma separated list of mutation prefixes to test. Empty == all")
+   projects := flags.String("out", ".", "Directory holding the generated projects, <out>/<project>/inputs")
+   endpoint := flags.String("endpoint", "", "NerdGraph endpoint to run the handlers' operations against, e.g. http://localhost:8080/graphql")
+   apiKey := flags.String("apiKey", os.Getenv("NEW_RELIC_API_KEY"), "API key for -endpoint")
+   command := flags.String("handler", "", "Command invoking the real handlers: request JSON on stdin, ProgressEvent JSON on stdout. PROJECT is set to the project directory")
+   logLevel := flags.String("logLevel", "warn", "logrus logging level panic | fatal | error | warn | info | debug | trace")
+   flags.Parse(args)
+
+   setLogLevel(*logLevel)
+   if (*endpoint == "") == (*command == "") {
+      log.Fatalf("contract: one of -endpoint or -handler is required")
+   }
+   config := loadConfig(*configFile)
+   services := newServices(loadSchema(*schema), config, strings.Split(*mutations, ","), false, false)
+
+   names := make([]string, 0, len(services))
+   for name := range services {
+      names = append(names, name)
+   }
+   sort.Strings(names)
+   passed := true
+   for _, name := range names {
+      service := services[name]
+      doc := service.Document()
+      project := filepath.Join(*projects, doc.BaseName())
+      create, err := readInput(filepath.Join(project, "inputs", "inputs_1_create.json"))
+      if err != nil {
+         log.Warnf("contract: %s: skipped: %v", name, err)
+         continue
+      }
+      update, err := readInput(filepath.Join(project, "inputs", "inputs_1_update.json"))
+      if err != nil && !os.IsNotExist(err) {
+         log.Fatalf("contract: %s: %v", name, err)
+      }
+
+      var handler contract.Handler
+      if *command != "" {
+         handler = contract.NewCommandHandler(*command, "PROJECT="+project)
+      } else {
+         c, err := client.New(client.Access{ApiKey: *apiKey, Endpoint: *endpoint})
+         if err != nil {
+            log.Fatalf("contract: %v", err)
+         }
+         if handler, err = contract.NewEndpointHandler(service, c); err != nil {
+            log.Fatalf("contract: %s: %v", name, err)
+         }
+      }
+      report := contract.NewHarness(doc, handler).Run(context.Background(), create, update)
+      fmt.Print(report)
+      passed = passed && report.Passed()
+   }
+   if !passed {
+      os.Exit(1)
+   }
+}
+
+// readInput
+// a contract test input, the resource model
+func readInput(file string) (map[string]interface{}, error) {
+   b, err := os.ReadFile(file)
+   if err != nil {
+      return nil, err
+   }
+   input := make(map[string]interface{})
+   if err = json.Unmarshal(b, &input); err != nil {
+      return nil, fmt.Errorf("%s: %w", file, err)
+   }
+   return input, nil
+}
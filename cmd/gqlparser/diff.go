@@ -0,0 +1,55 @@
+package main
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/diff"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   "flag"
+   "fmt"
+   log "github.com/sirupsen/logrus"
+   "os"
+   "strings"
+)
+
+// diffSchemas
+// generate the resources from two schema snapshots and write what changed, diff.md and diff.json. Exit 1 when a
+// change is breaking
+func diffSchemas(args []string) {
+   flags := flag.NewFlagSet("diff", flag.ExitOnError)
+   oldSchema := flags.String("old", "", "File containing the previous GraphQL Schema")
+   newSchema := flags.String("new", "schema.graphql", "File containing the current GraphQL Schema")
+   configFile := flags.String("config", "", "JSON file with the generator's settings, used for both snapshots")
+   mutations := flags.String("mutations", "", "Comma separated list of mutation prefixes to compare. Empty == all")
+   outDir := flags.String("out", ".", "Directory to write diff.md and diff.json to")
+   logLevel := flags.String("logLevel", "warn", "logrus logging level panic | fatal | error | warn | info | debug | trace")
+   flags.Parse(args)
+
+   setLogLevel(*logLevel)
+   if *oldSchema == "" {
+      log.Fatalf("diff: -old is required")
+   }
+   config := loadConfig(*configFile)
+   documents := func(schema string) []*model.Document {
+      services := newServices(loadSchema(schema), config, strings.Split(*mutations, ","), false, false)
+      docs := make([]*model.Document, 0, len(services))
+      for _, service := range services {
+         docs = append(docs, service.Document())
+      }
+      return docs
+   }
+   report := diff.NewReport(*oldSchema, *newSchema, diff.Compare(documents(*oldSchema), documents(*newSchema)))
+   if err := report.Write(*outDir); err != nil {
+      log.Fatalf("diff: %v", err)
+   }
+
+   breaking := 0
+   for _, change := range report.Changes {
+      if change.Breaking {
+         breaking++
+      }
+   }
+   fmt.Printf("diff: %d changes, %d breaking\n", len(report.Changes), breaking)
+   if report.Breaking {
+      os.Exit(1)
+   }
+}
+
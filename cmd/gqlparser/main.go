@@ -1,34 +1,73 @@
 package main
 
 import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/custom"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/docs"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/example"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/gomodel"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/guard"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/handlers"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/inputs"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/hashicorp/terraform"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/jsonschema"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/kubernetes/crd"
    "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/pulumi"
    "flag"
    "fmt"
    log "github.com/sirupsen/logrus"
    "github.com/vektah/gqlparser/v2/ast"
    "github.com/vektah/gqlparser/v2/parser"
    "os"
+   "path/filepath"
    "strings"
 )
 
 func main() {
+   // Subcommands have their own flags
+   if len(os.Args) > 1 {
+      switch os.Args[1] {
+      case "mock-server":
+         mockServer(os.Args[2:])
+         return
+      case "contract":
+         contractTest(os.Args[2:])
+         return
+      case "validate-template":
+         validateTemplate(os.Args[2:])
+         return
+      case "reverse":
+         reverse(os.Args[2:])
+         return
+      case "diff":
+         diffSchemas(os.Args[2:])
+         return
+      case "compat":
+         compat(os.Args[2:])
+         return
+      }
+   }
 
    // Command line params
    schema := flag.String("schema", "schema.graphql", "File containing the GraphQL Schema to parse")
+   configFile := flag.String("config", "", "JSON file with handler, tagging, etc. settings. Tagging flags override it")
    list := flag.Bool("list", false, "Set to true to list available mutations and queries")
    mutations := flag.String("mutations", "", "Comma separated list of mutation prefixes to process. Empty == all")
    queries := flag.String("queries", "", "Comma separated list of queries to process. Empty == all")
+   applyTagging := taggingFlags(flag.CommandLine)
+   emit := flag.String("emit", "schema", "Comma separated list of outputs: schema | handlers | operations | models | mapping | inputs | examples | docs | terraform | crd | jsonschema | openapi | pulumi | custom | guard | ide")
+   seed := flag.Int64("seed", 1, "Seed for -emit inputs, the same seed generates the same contract test inputs")
+   outDir := flag.String("out", ".", "Output directory")
    logLevel := flag.String("logLevel", "info", "logrus logging level panic | fatal | error | warn | info | debug | trace")
    flag.Parse()
 
-   level, err := log.ParseLevel(*logLevel)
+   setLogLevel(*logLevel)
+   config := loadConfig(*configFile)
+   err := applyTagging(config)
    if err != nil {
-      log.Warnf("main: invalid logLevel: %s err: %v", *logLevel, err)
-      log.SetLevel(log.InfoLevel)
-   } else {
-      log.SetLevel(level)
+      log.Fatalf("main: %v", err)
    }
-   log.Infof("main: logLevel: %v", log.GetLevel())
 
    mutationList := strings.Split(*mutations, ",")
    queryList := strings.Split(*queries, ",")
@@ -42,11 +81,172 @@ func main() {
    }
    _ = allQueries
 
+   schemaDocument := loadSchema(*schema)
+   services := newServices(schemaDocument, config, mutationList, allMutations, *list)
+
+   // We've loaded and grouped the services, tell them to parse, link to each other and marshal
+   nerdgraph.LinkRelationships(services)
+   outputs := strings.Split(*emit, ",")
+   documented := make([]*model.Document, 0)
+   packaged := make([]*nerdgraph.Service, 0)
+   customized := make([]*nerdgraph.Service, 0)
+   completed := make([]*model.Document, 0)
+   for _, service := range services {
+      for _, output := range outputs {
+         switch output {
+         case "schema":
+            service.Emit(*outDir)
+         case "handlers":
+            // cfn project layout, the project is named after the schema file
+            if err = handlers.Generate(service, filepath.Join(*outDir, service.Document().BaseName())); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "models":
+            if err = gomodel.Generate(service.Document(), filepath.Join(*outDir, service.Document().BaseName())); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "operations":
+            if err = service.EmitOperations(filepath.Join(*outDir, service.Document().BaseName(), "graphql")); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "mapping":
+            // Loaded by the handlers at runtime
+            if err = service.EmitMapping(filepath.Join(*outDir, service.Document().BaseName())); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "inputs":
+            // inputs/inputs_1_create.json etc. for cfn test and the contract subcommand
+            if err = inputs.Generate(service.Document(), filepath.Join(*outDir, service.Document().BaseName()), *seed); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "examples":
+            // example_inputs/<base name>.yaml and .json templates declaring the resource
+            if err = example.Generate(service.Document(), filepath.Join(*outDir, service.Document().BaseName())); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "docs":
+            // docs/README.md and index.html, the index of all of them comes after
+            if err = docs.Generate(service.Document(), filepath.Join(*outDir, service.Document().BaseName())); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+            documented = append(documented, service.Document())
+         case "terraform":
+            // Terraform Plugin Framework resource schemas, the provider's package
+            if err = terraform.Generate(service, filepath.Join(*outDir, "terraform")); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "crd":
+            // CustomResourceDefinitions, kubectl apply -f <out>/crds
+            if err = crd.Generate(service.Document(), filepath.Join(*outDir, "crds")); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "jsonschema":
+            // Plain draft 2020-12, GraphQL names, no CloudFormation keywords
+            if err = jsonschema.Generate(service.Document(), filepath.Join(*outDir, "jsonschema")); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "openapi":
+            // The same schemas as OpenAPI 3.1 components
+            if err = jsonschema.GenerateOpenAPI(service.Document(), filepath.Join(*outDir, "openapi")); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "pulumi":
+            // One package schema for all of them, written after
+            packaged = append(packaged, service)
+         case "guard":
+            // cfn-guard rules for templates using the type, cfn-guard validate -r <out>/guard
+            if err = guard.Generate(service, filepath.Join(*outDir, "guard")); err != nil {
+               log.Errorf("main: %s: %v", service.GetName(), err)
+            }
+         case "custom":
+            // Custom:: resources, one Lambda for all of them, written after
+            customized = append(customized, service)
+         case "ide":
+            // One template schema for editor completion, written after
+            completed = append(completed, service.Document())
+         default:
+            log.Fatalf("main: unknown output: %s", output)
+         }
+      }
+   }
+   if len(documented) > 0 {
+      if err = docs.Index(documented, *outDir); err != nil {
+         log.Errorf("main: %v", err)
+      }
+   }
+   if len(packaged) > 0 {
+      if err = pulumi.Generate(packaged, filepath.Join(*outDir, "pulumi")); err != nil {
+         log.Errorf("main: %v", err)
+      }
+   }
+   if len(customized) > 0 {
+      if err = custom.Generate(customized, filepath.Join(*outDir, "custom")); err != nil {
+         log.Errorf("main: %v", err)
+      }
+   }
+   if len(completed) > 0 {
+      if err = jsonschema.GenerateTemplate(completed, filepath.Join(*outDir, "ide")); err != nil {
+         log.Errorf("main: %v", err)
+      }
+   }
+}
+
+// setLogLevel
+// logrus level by name, info if it's invalid
+func setLogLevel(logLevel string) {
+   level, err := log.ParseLevel(logLevel)
+   if err != nil {
+      log.Warnf("main: invalid logLevel: %s err: %v", logLevel, err)
+      log.SetLevel(log.InfoLevel)
+   } else {
+      log.SetLevel(level)
+   }
+   log.Infof("main: logLevel: %v", log.GetLevel())
+}
+
+// taggingFlags
+// define -tagging, -tagArgument and -systemTags, the returned func applies the ones set on the command line to the config
+func taggingFlags(flags *flag.FlagSet) func(config *nerdgraph.Config) error {
+   tagging := flags.String("tagging", string(nerdgraph.TaggingEntity), "Tagging strategy none | entity | argument")
+   tagArgument := flags.String("tagArgument", "", "Mutation argument holding the tags when -tagging=argument")
+   systemTags := flags.Bool("systemTags", true, "Set to false to not propagate CloudFormation system tags")
+   return func(config *nerdgraph.Config) (err error) {
+      // Only explicitly set flags override the config file
+      flags.Visit(func(f *flag.Flag) {
+         switch f.Name {
+         case "tagging":
+            config.Tagging.Strategy, err = nerdgraph.ParseTaggingStrategy(*tagging)
+         case "tagArgument":
+            config.Tagging.Argument = *tagArgument
+         case "systemTags":
+            config.Tagging.SystemTags = *systemTags
+         }
+      })
+      return
+   }
+}
+
+// loadConfig
+// the config file, defaults when there's none
+func loadConfig(configFile string) *nerdgraph.Config {
+   if configFile == "" {
+      return nerdgraph.NewConfig()
+   }
+   config, err := nerdgraph.LoadConfig(configFile)
+   if err != nil {
+      log.Fatalf("main: %v", err)
+   }
+   return config
+}
+
+// loadSchema
+// parse the GraphQL schema file
+func loadSchema(schema string) *ast.SchemaDocument {
    // Read the schema file
-   source, err := os.ReadFile(*schema) // ex: schema.graphql
-   log.Debugf("Reading schema: %s", *schema)
+   source, err := os.ReadFile(schema) // ex: schema.graphql
+   log.Debugf("Reading schema: %s", schema)
    if err != nil {
-      log.Fatalf("error reading file %v: %v", *schema, err)
+      log.Fatalf("error reading file %v: %v", schema, err)
    }
 
    // Load the GraphQL Schema into an AST
@@ -61,29 +261,30 @@ func main() {
    if err != nil {
       log.Fatalf("error parsing schemaDocument: %+v", err)
    }
+   return schemaDocument
+}
 
+// newServices
+// group the mutations matching mutationList into services
+func newServices(schemaDocument *ast.SchemaDocument, config *nerdgraph.Config, mutationList []string, allMutations bool, list bool) map[string]*nerdgraph.Service {
    services := make(map[string]*nerdgraph.Service)
    for _, mutationDefinition := range getMutationDefinitions(schemaDocument) {
       // At this point we have a HIGH LEVEL (e.g. RootMutationType), the mutations we're interested in are buried in that object's Fields
       for _, fieldDefinition := range mutationDefinition.Fields {
-         if *list {
+         if list {
             fmt.Printf("mutation: %s\n", fieldDefinition.Type)
          }
 
          if allMutations || process(nerdgraph.ParseServiceName(fieldDefinition.Name), mutationList) {
             var service *nerdgraph.Service
-            service = nerdgraph.NewService(fieldDefinition, schemaDocument)
+            service = nerdgraph.NewService(fieldDefinition, schemaDocument, config)
             if service != nil {
                services[service.GetName()] = service
             }
          }
       }
    }
-
-   // We've loaded and grouped the services, tell them to parse and marshal
-   for _, service := range services {
-      service.Emit()
-   }
+   return services
 }
 
 func process(mutation string, list []string) bool {
@@ -0,0 +1,43 @@
+package main
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mock"
+   "flag"
+   log "github.com/sirupsen/logrus"
+   "net/http"
+   "strings"
+)
+
+// mockServer
+// serve the resources' NerdGraph operations from memory, for testing handlers without a New Relic account
+func mockServer(args []string) {
+   flags := flag.NewFlagSet("mock-server", flag.ExitOnError)
+   schema := flags.String("schema", "schema.graphql", "File containing the GraphQL Schema to serve")
+   configFile := flags.String("config", "", "JSON file with the generator's settings. Tagging flags override it")
+   mutations := flags.String("mutations", "", "Comma separated list of mutation prefixes to serve. Empty == all")
+   addr := flags.String("addr", "localhost:8080", "Address to listen on, point the type configuration's Endpoint at http://<addr>/graphql")
+   pageSize := flags.Int("pageSize", 0, "Entities per list page, 0 == all")
+   logLevel := flags.String("logLevel", "info", "logrus logging level panic | fatal | error | warn | info | debug | trace")
+   // The same tagging as the handlers were generated with, the tag mutations and arguments depend on it
+   applyTagging := taggingFlags(flags)
+   flags.Parse(args)
+
+   setLogLevel(*logLevel)
+   config := loadConfig(*configFile)
+   if err := applyTagging(config); err != nil {
+      log.Fatalf("mock-server: %v", err)
+   }
+   schemaDocument := loadSchema(*schema)
+   services := newServices(schemaDocument, config, strings.Split(*mutations, ","), false, false)
+
+   server, err := mock.New(services)
+   if err != nil {
+      log.Fatalf("mock-server: %v", err)
+   }
+   server.PageSize = *pageSize
+   for _, line := range server.Describe() {
+      log.Infof("mock-server: %s", line)
+   }
+   log.Infof("mock-server: listening on %s", *addr)
+   log.Fatal(http.ListenAndServe(*addr, server))
+}
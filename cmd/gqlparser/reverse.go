@@ -0,0 +1,48 @@
+package main
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/sdl"
+   "flag"
+   "fmt"
+   log "github.com/sirupsen/logrus"
+   "os"
+)
+
+// reverse
+// GraphQL SDL for resource schemas, to compare with the original API after a round trip or a hand edit
+func reverse(args []string) {
+   flags := flag.NewFlagSet("reverse", flag.ExitOnError)
+   out := flags.String("out", "", "File to write the SDL to, stdout when empty")
+   logLevel := flags.String("logLevel", "warn", "logrus logging level panic | fatal | error | warn | info | debug | trace")
+   flags.Usage = func() {
+      fmt.Fprintf(flags.Output(), "Usage: gqlparser reverse [flags] <resource schema>.json...\n")
+      flags.PrintDefaults()
+   }
+   flags.Parse(args)
+   if flags.NArg() == 0 {
+      flags.Usage()
+      os.Exit(2)
+   }
+
+   setLogLevel(*logLevel)
+   docs := make([]*model.Document, 0, flags.NArg())
+   for _, file := range flags.Args() {
+      doc, err := model.ReadDocument(file)
+      if err != nil {
+         log.Fatalf("reverse: %v", err)
+      }
+      docs = append(docs, doc)
+   }
+   // Invalid SDL is still written, it's what needs reviewing
+   s, err := sdl.New(docs...)
+   if *out == "" {
+      fmt.Print(s)
+   } else if werr := os.WriteFile(*out, []byte(s), 0644); werr != nil {
+      log.Fatalf("reverse: %v", werr)
+   }
+   if err != nil {
+      log.Errorf("reverse: %v", err)
+      os.Exit(1)
+   }
+}
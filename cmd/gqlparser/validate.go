@@ -0,0 +1,56 @@
+package main
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/validate"
+   "flag"
+   "fmt"
+   log "github.com/sirupsen/logrus"
+   "os"
+   "strings"
+)
+
+// validateTemplate
+// check the templates' resources of the generated types offline, one line per problem, exit 1 when there's any
+func validateTemplate(args []string) {
+   flags := flag.NewFlagSet("validate-template", flag.ExitOnError)
+   schema := flags.String("schema", "schema.graphql", "File containing the GraphQL Schema")
+   configFile := flags.String("config", "", "JSON file with the generator's settings")
+   mutations := flags.String("mutations", "", "Comma separated list of mutation prefixes the types are generated from. Empty == all")
+   logLevel := flags.String("logLevel", "warn", "logrus logging level panic | fatal | error | warn | info | debug | trace")
+   flags.Usage = func() {
+      fmt.Fprintf(flags.Output(), "Usage: gqlparser validate-template [flags] template.yaml...\n")
+      flags.PrintDefaults()
+   }
+   flags.Parse(args)
+   if flags.NArg() == 0 {
+      flags.Usage()
+      os.Exit(2)
+   }
+
+   setLogLevel(*logLevel)
+   config := loadConfig(*configFile)
+   services := newServices(loadSchema(*schema), config, strings.Split(*mutations, ","), false, false)
+   docs := make([]*model.Document, 0, len(services))
+   for _, service := range services {
+      docs = append(docs, service.Document())
+   }
+   validator := validate.New(docs)
+
+   failed := false
+   for _, file := range flags.Args() {
+      errs, err := validator.File(file)
+      if err != nil {
+         log.Errorf("validate-template: %v", err)
+         failed = true
+         continue
+      }
+      for _, e := range errs {
+         fmt.Println(e)
+      }
+      failed = failed || len(errs) > 0
+   }
+   if failed {
+      os.Exit(1)
+   }
+}
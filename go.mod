@@ -6,6 +6,10 @@ require (
 	github.com/sirupsen/logrus v1.9.3
 	github.com/vektah/gqlparser/v2 v2.5.10
 	golang.org/x/text v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require golang.org/x/sys v0.13.0 // indirect
+require (
+	github.com/agnivade/levenshtein v1.1.1 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
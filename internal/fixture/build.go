@@ -0,0 +1,29 @@
+package fixture
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// Build
+// go build and go vet the generated module in dir from the module cache only, skipping the test when the cache doesn't
+// have its requirements
+func Build(t *testing.T, dir string) {
+	t.Helper()
+	env := append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOSUMDB=off", "GOWORK=off")
+	for _, args := range [][]string{{"build", "./..."}, {"vet", "./..."}} {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		cmd.Env = env
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			continue
+		}
+		if strings.Contains(string(output), "module lookup disabled") {
+			t.Skipf("go %s: the module cache doesn't have the requirements: %s", args[0], output)
+		}
+		t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+}
@@ -0,0 +1,98 @@
+package fixture
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	_ "embed"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+	"strings"
+)
+
+/*
+The NerdGraph SDL the tests generate from: the aiNotifications channel (create, update, delete, list query) and
+destination (create, delete) mutations, their input types, enums and payloads, and the entity tagging mutation
+*/
+
+//go:embed schema.graphql
+var Schema string
+
+// Prefix the fixture's mutation prefix, -mutations aiNotifications
+const Prefix = "aiNotifications"
+
+func init() {
+	// The generator logs every argument at info, tests only want to see errors
+	log.SetLevel(log.ErrorLevel)
+}
+
+// EntitySchema the SDL with the channel and destination as NerdGraph entities, identified by the guid the tagging
+// mutations take. NerdGraph's own have an id, so they're not taggable
+var EntitySchema = strings.NewReplacer(
+	"type AiNotificationsChannel {\n  id: ID!\n", "type AiNotificationsChannel implements Entity {\n  guid: EntityGuid!\n  tags: [EntityTag]\n",
+	"type AiNotificationsDestination {\n  id: ID!\n", "type AiNotificationsDestination implements Entity {\n  guid: EntityGuid!\n  tags: [EntityTag]\n",
+).Replace(Schema)
+
+// Services
+// the fixture's services by name, linked like gqlparser does. nil config is the default one
+func Services(config *nerdgraph.Config) (map[string]*nerdgraph.Service, error) {
+	return ServicesFrom(Schema, config)
+}
+
+// ServicesFrom
+// the services in sdl whose mutations start with Prefix
+func ServicesFrom(sdl string, config *nerdgraph.Config) (map[string]*nerdgraph.Service, error) {
+	if config == nil {
+		config = nerdgraph.NewConfig()
+	}
+	doc, err := parser.ParseSchema(&ast.Source{Name: "schema.graphql", Input: sdl, BuiltIn: true})
+	if err != nil {
+		return nil, fmt.Errorf("fixture: %w", err)
+	}
+	services := make(map[string]*nerdgraph.Service)
+	for _, sd := range doc.Schema {
+		for _, op := range sd.OperationTypes {
+			if op.Operation != ast.Mutation {
+				continue
+			}
+			for _, field := range doc.Definitions.ForName(op.Type).Fields {
+				if !strings.HasPrefix(field.Name, Prefix) {
+					continue
+				}
+				if service := nerdgraph.NewService(field, doc, config); service != nil {
+					services[service.GetName()] = service
+				}
+			}
+		}
+	}
+	nerdgraph.LinkRelationships(services)
+	return services, nil
+}
+
+// Service
+// one of the fixture's services, aiNotificationsChannel or aiNotificationsDestination
+func Service(name string, config *nerdgraph.Config) (*nerdgraph.Service, error) {
+	services, err := Services(config)
+	if err != nil {
+		return nil, err
+	}
+	service := services[name]
+	if service == nil {
+		return nil, fmt.Errorf("fixture: no service %s", name)
+	}
+	return service, nil
+}
+
+// Entity
+// one of the services in EntitySchema, tagged with the entity tagging mutations
+func Entity(name string, config *nerdgraph.Config) (*nerdgraph.Service, error) {
+	services, err := ServicesFrom(EntitySchema, config)
+	if err != nil {
+		return nil, err
+	}
+	service := services[name]
+	if service == nil {
+		return nil, fmt.Errorf("fixture: no service %s", name)
+	}
+	return service, nil
+}
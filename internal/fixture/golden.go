@@ -0,0 +1,72 @@
+package fixture
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update rewrites the golden files with what the tests generate: go test ./pkg/<package> -update
+var update = flag.Bool("update", false, "rewrite the golden files under testdata")
+
+// Golden
+// fail unless got is the golden file's content, write it instead with -update
+func Golden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v, go test -update writes it", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("differs from %s, go test -update rewrites it:\n%s", golden, got)
+	}
+}
+
+// GoldenFile
+// Golden for a generated file
+func GoldenFile(t *testing.T, golden string, file string) {
+	t.Helper()
+	got, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Golden(t, golden, got)
+}
+
+// GoldenDir
+// the files matching pattern in dir are the ones in the golden directory, -update replaces those
+func GoldenDir(t *testing.T, golden string, dir string, pattern string) {
+	t.Helper()
+	got, err := filepath.Glob(filepath.Join(dir, pattern))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := filepath.Glob(filepath.Join(golden, pattern))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		for _, file := range want {
+			if err = os.Remove(file); err != nil {
+				t.Fatal(err)
+			}
+		}
+	} else if len(want) == 0 {
+		t.Fatalf("no %s in %s, go test -update writes them", pattern, golden)
+	} else if len(got) != len(want) {
+		t.Errorf("got %d files in %s, want the %d in %s", len(got), dir, len(want), golden)
+	}
+	for _, file := range got {
+		GoldenFile(t, filepath.Join(golden, filepath.Base(file)), file)
+	}
+}
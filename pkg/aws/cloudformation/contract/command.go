@@ -0,0 +1,44 @@
+package contract
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// CommandHandler
+// a handler run as a command per request: the Request as JSON on stdin, the Event as JSON on stdout. Wraps whatever
+// invokes the real handlers, e.g. a script around `sam local invoke`
+type CommandHandler struct {
+	command string
+	env     []string
+}
+
+// NewCommandHandler
+// command runs with sh -c, env is added to the environment
+func NewCommandHandler(command string, env ...string) *CommandHandler {
+	return &CommandHandler{command: command, env: env}
+}
+
+func (h *CommandHandler) Handle(ctx context.Context, req *Request) (*Event, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("contract: %w", err)
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.command)
+	cmd.Env = append(cmd.Environ(), h.env...)
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("contract: %s: %w: %s", req.Action, err, stderr.String())
+	}
+	event := &Event{}
+	if err = json.Unmarshal(out, event); err != nil {
+		return nil, fmt.Errorf("contract: %s: invalid event: %w", req.Action, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,343 @@
+package contract
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*
+Lifecycle contract harness, the part of `cfn test` we can run locally: create -> read -> update -> read -> list -> delete
+-> read, checking the resource schema's invariants along the way
+
+- readOnlyProperties come back populated
+- createOnlyProperties aren't changed by update
+- writeOnlyProperties are never returned
+- the primary identifier stays the same
+- read and delete after delete are NotFound
+*/
+
+// Actions
+const (
+	Create = "CREATE"
+	Read   = "READ"
+	Update = "UPDATE"
+	Delete = "DELETE"
+	List   = "LIST"
+)
+
+// Statuses
+const (
+	Success    = "SUCCESS"
+	Failed     = "FAILED"
+	InProgress = "IN_PROGRESS"
+)
+
+// maxInProgress re-invocations of an IN_PROGRESS handler before giving up
+const maxInProgress = 20
+
+// Request what CloudFormation passes a handler
+type Request struct {
+	Action                string                 `json:"action"`
+	DesiredResourceState  map[string]interface{} `json:"desiredResourceState"`
+	PreviousResourceState map[string]interface{} `json:"previousResourceState,omitempty"`
+	CallbackContext       map[string]interface{} `json:"callbackContext,omitempty"`
+	NextToken             string                 `json:"nextToken,omitempty"`
+}
+
+// Event the handler's ProgressEvent
+type Event struct {
+	Status          string                   `json:"status"`
+	ErrorCode       string                   `json:"errorCode,omitempty"`
+	Message         string                   `json:"message,omitempty"`
+	ResourceModel   map[string]interface{}   `json:"resourceModel,omitempty"`
+	ResourceModels  []map[string]interface{} `json:"resourceModels,omitempty"`
+	CallbackContext map[string]interface{}   `json:"callbackContext,omitempty"`
+	NextToken       string                   `json:"nextToken,omitempty"`
+}
+
+// Handler the resource's handlers, see NewEndpointHandler and NewCommandHandler
+type Handler interface {
+	Handle(ctx context.Context, req *Request) (*Event, error)
+}
+
+// Check a step's outcome
+type Check struct {
+	Step    string
+	Name    string
+	Passed  bool
+	Message string
+}
+
+type Report struct {
+	TypeName string
+	Checks   []*Check
+}
+
+// Passed
+// every check passed
+func (r *Report) Passed() bool {
+	for _, c := range r.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	for _, c := range r.Checks {
+		status := "PASS"
+		if !c.Passed {
+			status = "FAIL"
+		}
+		b.WriteString(fmt.Sprintf("%s %s %s: %s", status, r.TypeName, c.Step, c.Name))
+		if c.Message != "" {
+			b.WriteString(": " + c.Message)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func (r *Report) check(step string, name string, passed bool, format string, args ...interface{}) bool {
+	c := &Check{Step: step, Name: name, Passed: passed}
+	if !passed {
+		c.Message = fmt.Sprintf(format, args...)
+	}
+	r.Checks = append(r.Checks, c)
+	return passed
+}
+
+// Harness runs the lifecycle for a resource schema
+type Harness struct {
+	doc     *model.Document
+	handler Handler
+	report  *Report
+}
+
+func NewHarness(doc *model.Document, handler Handler) *Harness {
+	return &Harness{doc: doc, handler: handler}
+}
+
+// Run
+// the lifecycle with the create and update inputs (resource models, CloudFormation property names). update is
+// skipped when it's nil or the schema has no update handler
+func (h *Harness) Run(ctx context.Context, create map[string]interface{}, update map[string]interface{}) *Report {
+	h.report = &Report{TypeName: h.doc.TypeName, Checks: make([]*Check, 0)}
+
+	created, ok := h.invoke(ctx, Create, create, nil)
+	if !ok {
+		return h.report
+	}
+	h.returned(Create, created)
+	identifier := h.identifier(created)
+	h.report.check(Create, "primary identifier", len(identifier) > 0 && !hasEmpty(identifier), "primary identifier %v not populated", h.doc.PrimaryIdentifier)
+
+	current := h.read(ctx, "READ after CREATE", created, identifier, create)
+
+	if update != nil && h.doc.Handlers["update"] != nil && current != nil {
+		desired := copyModel(update)
+		// The identifiers aren't in the input, CloudFormation passes them along
+		for _, path := range append(append([]string{}, h.doc.PrimaryIdentifier...), h.doc.ReadOnlyProperties...) {
+			name := propertyName(path)
+			if v, ok := current[name]; ok {
+				desired[name] = v
+			}
+		}
+		updated, ok := h.invoke(ctx, Update, desired, current)
+		if ok {
+			h.returned(Update, updated)
+			h.report.check(Update, "primary identifier unchanged", reflect.DeepEqual(identifier, h.identifier(updated)), "%v became %v", identifier, h.identifier(updated))
+			for _, path := range h.doc.CreateOnlyProperties {
+				name := propertyName(path)
+				h.report.check(Update, "createOnlyProperty "+name+" unchanged", equalJSON(current[name], updated[name]), "%v became %v", current[name], updated[name])
+			}
+			h.read(ctx, "READ after UPDATE", updated, identifier, update)
+		}
+	}
+
+	if h.doc.Handlers["list"] != nil {
+		// List is scoped by what was created with, e.g. the account
+		if event := h.handle(ctx, &Request{Action: List, DesiredResourceState: copyModel(create)}); h.succeeded(List, event) {
+			found := false
+			for _, m := range event.ResourceModels {
+				h.returned(List, m)
+				if reflect.DeepEqual(identifier, h.identifier(m)) {
+					found = true
+				}
+			}
+			h.report.check(List, "contains the resource", found, "%v not among %d models", identifier, len(event.ResourceModels))
+		}
+	}
+
+	if _, ok = h.invoke(ctx, Delete, copyModel(created), nil); !ok {
+		return h.report
+	}
+	for _, action := range []string{Read, Delete} {
+		event := h.handle(ctx, &Request{Action: action, DesiredResourceState: copyModel(created)})
+		step := action + " after DELETE"
+		if event == nil {
+			continue
+		}
+		h.report.check(step, "NotFound", event.Status == Failed && event.ErrorCode == "NotFound", "status %s, errorCode %q: %s", event.Status, event.ErrorCode, event.Message)
+	}
+	return h.report
+}
+
+// invoke
+// the action until it's done, its model when it succeeds
+func (h *Harness) invoke(ctx context.Context, action string, desired map[string]interface{}, previous map[string]interface{}) (map[string]interface{}, bool) {
+	event := h.handle(ctx, &Request{Action: action, DesiredResourceState: copyModel(desired), PreviousResourceState: previous})
+	if !h.succeeded(action, event) {
+		return nil, false
+	}
+	if event.ResourceModel == nil && action != Delete {
+		h.report.check(action, "returns the model", false, "no resourceModel")
+		return nil, false
+	}
+	return event.ResourceModel, true
+}
+
+// handle
+// call the handler, again while it's IN_PROGRESS
+func (h *Harness) handle(ctx context.Context, req *Request) *Event {
+	for i := 0; i < maxInProgress; i++ {
+		event, err := h.handler.Handle(ctx, req)
+		if err != nil {
+			h.report.check(req.Action, "handler error", false, "%v", err)
+			return nil
+		}
+		if event.Status != InProgress {
+			return event
+		}
+		req.CallbackContext = event.CallbackContext
+		if event.ResourceModel != nil {
+			req.DesiredResourceState = event.ResourceModel
+		}
+	}
+	h.report.check(req.Action, "completes", false, "still IN_PROGRESS after %d calls", maxInProgress)
+	return nil
+}
+
+func (h *Harness) succeeded(step string, event *Event) bool {
+	if event == nil {
+		return false
+	}
+	return h.report.check(step, "succeeds", event.Status == Success, "status %s, errorCode %q: %s", event.Status, event.ErrorCode, event.Message)
+}
+
+// read
+// read the resource and check it's the same one with what was written. Returns the read model
+func (h *Harness) read(ctx context.Context, step string, model map[string]interface{}, identifier []interface{}, input map[string]interface{}) map[string]interface{} {
+	event := h.handle(ctx, &Request{Action: Read, DesiredResourceState: copyModel(model)})
+	if !h.succeeded(step, event) {
+		return nil
+	}
+	read := event.ResourceModel
+	h.returned(step, read)
+	h.report.check(step, "primary identifier unchanged", reflect.DeepEqual(identifier, h.identifier(read)), "%v became %v", identifier, h.identifier(read))
+	writeOnly := make(map[string]bool)
+	for _, path := range h.doc.WriteOnlyProperties {
+		writeOnly[propertyName(path)] = true
+	}
+	for name, expected := range input {
+		if writeOnly[name] {
+			continue
+		}
+		h.report.check(step, "returns "+name, subset(expected, read[name]), "wrote %s, read %s", toJSON(expected), toJSON(read[name]))
+	}
+	return read
+}
+
+// returned
+// what every returned model must satisfy
+func (h *Harness) returned(step string, m map[string]interface{}) {
+	for _, path := range h.doc.ReadOnlyProperties {
+		name := propertyName(path)
+		h.report.check(step, "readOnlyProperty "+name+" populated", m[name] != nil, "missing from %s", toJSON(m))
+	}
+	for _, path := range h.doc.WriteOnlyProperties {
+		name := propertyName(path)
+		_, returned := m[name]
+		h.report.check(step, "writeOnlyProperty "+name+" not returned", !returned, "returned %s", toJSON(m[name]))
+	}
+}
+
+func (h *Harness) identifier(m map[string]interface{}) []interface{} {
+	identifier := make([]interface{}, 0, len(h.doc.PrimaryIdentifier))
+	for _, path := range h.doc.PrimaryIdentifier {
+		identifier = append(identifier, m[propertyName(path)])
+	}
+	return identifier
+}
+
+func hasEmpty(values []interface{}) bool {
+	for _, v := range values {
+		if v == nil || v == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// propertyName
+// /properties/Name -> Name
+func propertyName(path string) string {
+	return strings.TrimPrefix(path, "/properties/")
+}
+
+// subset
+// actual has everything expected has, objects may have more properties
+func subset(expected interface{}, actual interface{}) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range e {
+			if !subset(v, a[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || len(a) != len(e) {
+			return false
+		}
+		for i := range e {
+			if !subset(e[i], a[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return equalJSON(expected, actual)
+}
+
+// equalJSON
+// equal once both are JSON, e.g. int 1 and float64 1
+func equalJSON(a interface{}, b interface{}) bool {
+	return toJSON(a) == toJSON(b)
+}
+
+func toJSON(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func copyModel(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	b, _ := json.Marshal(m)
+	_ = json.Unmarshal(b, &c)
+	return c
+}
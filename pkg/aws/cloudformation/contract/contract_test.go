@@ -0,0 +1,116 @@
+package contract_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/contract"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mock"
+	"context"
+	"net/http/httptest"
+	"testing"
+)
+
+// harness
+// the channel's lifecycle against the mock server
+func harness(t *testing.T, config *nerdgraph.Config) *contract.Harness {
+	t.Helper()
+	services, err := fixture.Services(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := mock.New(services)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	c, err := client.New(client.Access{ApiKey: "key", Endpoint: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := services["aiNotificationsChannel"]
+	handler, err := contract.NewEndpointHandler(service, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return contract.NewHarness(service.Document(), handler)
+}
+
+// channel
+// a channel model, create's input type has no active
+func channel(name string, active interface{}) map[string]interface{} {
+	c := map[string]interface{}{"Name": name, "Type": "EMAIL", "Product": "IINT", "DestinationId": "d", "Properties": []interface{}{}}
+	if active != nil {
+		c["Active"] = active
+	}
+	return map[string]interface{}{
+		"AccountId": 1,
+		"Channel":   c,
+		"Tags":      []interface{}{map[string]interface{}{"Key": "team", "Value": name}},
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	writeOnly := nerdgraph.NewConfig()
+	writeOnly.Services[fixture.Prefix] = &nerdgraph.ServiceConfig{WriteOnly: []string{"channel"}}
+	tests := []struct {
+		name   string
+		config *nerdgraph.Config
+		checks []string // Checks that must have run
+	}{
+		{"default", nil, []string{"returns Channel", "returns Tags", "readOnlyProperty Guid populated", "primary identifier unchanged", "contains the resource", "NotFound"}},
+		{"writeOnly", writeOnly, []string{"writeOnlyProperty Channel not returned", "NotFound"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := harness(t, tt.config).Run(context.Background(), channel("a", nil), channel("b", true))
+			if !report.Passed() {
+				t.Errorf("failed:\n%s", report)
+			}
+			ran := make(map[string]bool)
+			for _, c := range report.Checks {
+				ran[c.Name] = true
+			}
+			for _, name := range tt.checks {
+				if !ran[name] {
+					t.Errorf("no %q check:\n%s", name, report)
+				}
+			}
+		})
+	}
+}
+
+// failing
+// a handler that never returns the identifier
+type failing struct {
+	contract.Handler
+}
+
+func (f failing) Handle(ctx context.Context, req *contract.Request) (*contract.Event, error) {
+	event, err := f.Handler.Handle(ctx, req)
+	if event != nil && event.ResourceModel != nil {
+		delete(event.ResourceModel, "Guid")
+	}
+	return event, err
+}
+
+func TestLifecycleFails(t *testing.T) {
+	services, err := fixture.Services(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, _ := mock.New(services)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+	c, _ := client.New(client.Access{ApiKey: "key", Endpoint: ts.URL})
+	service := services["aiNotificationsChannel"]
+	handler, err := contract.NewEndpointHandler(service, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := contract.NewHarness(service.Document(), failing{handler}).Run(context.Background(), channel("a", nil), nil)
+	if report.Passed() {
+		t.Errorf("passed without a primary identifier:\n%s", report)
+	}
+}
@@ -0,0 +1,164 @@
+package contract
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mapping"
+	"context"
+	"fmt"
+)
+
+// EndpointHandler
+// the generated handlers' logic in process, straight against a NerdGraph endpoint (e.g. mock-server). It sends the same
+// operation documents through pkg/nerdgraph/client and converts with pkg/nerdgraph/mapping, the packages the generated
+// handlers carry copies of, but it doesn't run the generated code: NewCommandHandler does
+type EndpointHandler struct {
+	service   *nerdgraph.Service
+	client    *client.Client
+	mapping   *mapping.Mapping
+	documents map[string]string
+}
+
+func NewEndpointHandler(service *nerdgraph.Service, c *client.Client) (*EndpointHandler, error) {
+	documents, err := service.OperationDocuments()
+	if err != nil {
+		return nil, fmt.Errorf("contract: %w", err)
+	}
+	return &EndpointHandler{service: service, client: c, mapping: service.Mapping(), documents: documents}, nil
+}
+
+func (h *EndpointHandler) Handle(ctx context.Context, req *Request) (*Event, error) {
+	switch req.Action {
+	case Create:
+		return h.mutate(ctx, nerdgraph.CreateOperation, nerdgraph.CreateTagsOperation, req)
+	case Update:
+		return h.mutate(ctx, nerdgraph.UpdateOperation, nerdgraph.UpdateTagsOperation, req)
+	case Delete:
+		return h.mutate(ctx, nerdgraph.DeleteOperation, "", req)
+	case Read:
+		return h.read(ctx, req)
+	case List:
+		return h.list(ctx, req)
+	}
+	return nil, fmt.Errorf("contract: unknown action %s", req.Action)
+}
+
+func (h *EndpointHandler) mutate(ctx context.Context, operation string, tagOperation string, req *Request) (*Event, error) {
+	field := h.service.GetOperation(operation)
+	if field == nil {
+		return failed(client.InvalidRequest, fmt.Sprintf("%s: not supported by NerdGraph", operation)), nil
+	}
+	// Delete of something that's gone is NotFound, whatever the mutation says. Without a query there's nothing to read
+	if operation == nerdgraph.DeleteOperation && h.service.ListQuery() != nil {
+		if event, err := h.read(ctx, req); err != nil || event.Status != Success {
+			return event, err
+		}
+	}
+	variables, err := h.mapping.Variables(field.Name, req.DesiredResourceState, req.PreviousResourceState)
+	if err != nil {
+		return failed(client.InvalidRequest, err.Error()), nil
+	}
+	entity, err := h.client.Mutate(ctx, h.documents[operation], variables, field.Name, h.service.EntityField(field), h.service.ErrorsField(field))
+	if err != nil {
+		return failed(client.ErrorCode(err), err.Error()), nil
+	}
+	if operation == nerdgraph.DeleteOperation {
+		return &Event{Status: Success}, nil
+	}
+	model := h.mapping.Model(entity, req.DesiredResourceState)
+	// Like the generated handlers: create only tags when there are tags, update replaces them even with none
+	if document, ok := h.documents[tagOperation]; ok {
+		if tags := h.mapping.Tags(req.DesiredResourceState); len(tags) > 0 || (tags != nil && operation == nerdgraph.UpdateOperation) {
+			tagMutation := nerdgraph.TagAddMutation
+			if operation == nerdgraph.UpdateOperation {
+				tagMutation = nerdgraph.TagReplaceMutation
+			}
+			variables := map[string]interface{}{"guid": h.identifier(model), "tags": tags}
+			if _, err = h.client.Mutate(ctx, document, variables, tagMutation, "", h.service.ErrorsField(h.service.RootMutation(tagMutation))); err != nil {
+				return failed(client.ErrorCode(err), err.Error()), nil
+			}
+		}
+	}
+	return &Event{Status: Success, ResourceModel: model}, nil
+}
+
+func (h *EndpointHandler) read(ctx context.Context, req *Request) (*Event, error) {
+	q := h.service.ListQuery()
+	if q == nil {
+		return failed(client.InvalidRequest, "read: no NerdGraph query"), nil
+	}
+	identifier := h.identifier(req.DesiredResourceState)
+	variables := h.queryVariables(q, req.DesiredResourceState)
+	document := h.documents[nerdgraph.ListOperation]
+	if q.FilterArgument != "" {
+		document = h.documents[nerdgraph.ReadOperation]
+		variables[q.FilterField] = identifier
+	}
+	var found map[string]interface{}
+	err := h.client.Paginate(ctx, document, variables, page(q), func(entities []map[string]interface{}) bool {
+		for _, entity := range entities {
+			if fmt.Sprint(entity[h.mapping.IdentifierField]) == fmt.Sprint(identifier) {
+				found = entity
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return failed(client.ErrorCode(err), err.Error()), nil
+	}
+	if found == nil {
+		return failed(client.NotFound, fmt.Sprintf("read: %v not found", identifier)), nil
+	}
+	return &Event{Status: Success, ResourceModel: h.mapping.Model(found, req.DesiredResourceState)}, nil
+}
+
+func (h *EndpointHandler) list(ctx context.Context, req *Request) (*Event, error) {
+	q := h.service.ListQuery()
+	if q == nil {
+		return failed(client.InvalidRequest, "list: no NerdGraph query"), nil
+	}
+	models := make([]map[string]interface{}, 0)
+	err := h.client.Paginate(ctx, h.documents[nerdgraph.ListOperation], h.queryVariables(q, req.DesiredResourceState), page(q), func(entities []map[string]interface{}) bool {
+		for _, entity := range entities {
+			models = append(models, h.mapping.Model(entity, req.DesiredResourceState))
+		}
+		return true
+	})
+	if err != nil {
+		return failed(client.ErrorCode(err), err.Error()), nil
+	}
+	return &Event{Status: Success, ResourceModels: models}, nil
+}
+
+// queryVariables
+// the query path's variables from the model, e.g. accountId <- AccountId
+func (h *EndpointHandler) queryVariables(q *nerdgraph.QueryPath, model map[string]interface{}) map[string]interface{} {
+	variables := make(map[string]interface{})
+	for _, v := range q.Variables() {
+		for name, field := range h.mapping.Properties {
+			if field.Name == v.Name {
+				variables[v.Name] = model[name]
+			}
+		}
+	}
+	return variables
+}
+
+// identifier
+// the model's primary identifier, Guid
+func (h *EndpointHandler) identifier(model map[string]interface{}) interface{} {
+	return model[propertyName(h.service.Document().PrimaryIdentifier[0])]
+}
+
+func page(q *nerdgraph.QueryPath) client.Page {
+	path := make([]string, 0, len(q.Fields))
+	for _, f := range q.Fields {
+		path = append(path, f.Name)
+	}
+	return client.Page{Path: path, EntitiesField: q.EntitiesField, CursorArgument: q.CursorArgument, NextCursorField: q.NextCursorField}
+}
+
+func failed(code string, message string) *Event {
+	return &Event{Status: Failed, ErrorCode: code, Message: message}
+}
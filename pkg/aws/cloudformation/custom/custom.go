@@ -0,0 +1,226 @@
+package custom
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/custom/customresource"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/gomodel"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+/*
+Lambda-backed custom resources (Custom::NewRelic<Service>), for accounts that can't register third-party registry types
+
+- The property contract per Service: ServiceToken plus the resource's writable properties. CloudFormation passes every
+  scalar as a string, they're coerced back to the schema's types before the mutation variables are built
+- Create/Update/Delete dispatch to the Service's mutations with the same operation documents, mapping and client as
+  the registry handlers. The primary identifier is the PhysicalResourceId, the readOnlyProperties the Data attributes
+  for Fn::GetAtt
+- Changing a createOnlyProperty (anything, without an update mutation) creates a new resource, CloudFormation deletes
+  the old one when the new PhysicalResourceId comes back
+
+Generate writes resources/<base name>.json for each Service and the single Lambda, main.go, embedding them. The Lambda is
+its own module: the customresource package and the NerdGraph client and mapping it uses are copied into its internal
+directory, and its go.mod only requires aws-lambda-go.
+*/
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+//go:embed customresource/*.go
+var runtime embed.FS
+
+// Module the generated Lambda's module path
+const Module = "newrelic-custom-resources"
+
+const runtimeHeader = "// Code copied by gqlparser from pkg/aws/cloudformation/custom/customresource. DO NOT EDIT.\n\n"
+
+// runtimeImport the import prefix of the NerdGraph packages customresource uses, the copy imports the Lambda's copies
+const runtimeImport = `"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/`
+
+var lambdaTemplate = template.Must(template.ParseFS(templates, "templates/*.tmpl"))
+
+type lambdaData struct {
+	Types         []string // The resource types it serves
+	RuntimeImport string
+	ClientImport  string
+}
+
+// ResourceType
+// Custom::NewRelic<Service>, aiNotificationsChannel -> Custom::NewRelicAiNotificationsChannel
+func ResourceType(service *nerdgraph.Service) string {
+	return "Custom::NewRelic" + upperFirst(service.GetName())
+}
+
+// New
+// the Service's custom resource
+func New(service *nerdgraph.Service) (*customresource.Resource, error) {
+	documents, err := service.OperationDocuments()
+	if err != nil {
+		return nil, fmt.Errorf("custom: %w", err)
+	}
+	doc := service.Document()
+	r := &customresource.Resource{
+		ResourceType:      ResourceType(service),
+		TypeName:          doc.TypeName,
+		Properties:        map[string]*customresource.Property{customresource.ServiceToken: {Type: "string"}},
+		Required:          []string{customresource.ServiceToken},
+		Definitions:       make(map[string]*customresource.Property),
+		PrimaryIdentifier: strings.TrimPrefix(doc.PrimaryIdentifier[0], "/properties/"),
+		Operations:        make(map[string]*customresource.Operation),
+		Mapping:           service.Mapping(),
+	}
+	readOnly := make(map[string]bool)
+	for _, path := range doc.ReadOnlyProperties {
+		name := strings.TrimPrefix(path, "/properties/")
+		readOnly[name] = true
+		r.Attributes = append(r.Attributes, name)
+	}
+	sort.Strings(r.Attributes)
+	for name, property := range doc.Properties {
+		if !readOnly[name] {
+			r.Properties[name] = newProperty(property)
+		}
+	}
+	r.Required = append(r.Required, doc.Required...)
+	for _, path := range doc.CreateOnlyProperties {
+		r.CreateOnly = append(r.CreateOnly, strings.TrimPrefix(path, "/properties/"))
+	}
+	for name, def := range doc.Definitions {
+		if name != model.AccessDefinitionName {
+			r.Definitions[name] = newProperty(def)
+		}
+	}
+
+	tagOperations := map[string]string{nerdgraph.CreateOperation: nerdgraph.CreateTagsOperation, nerdgraph.UpdateOperation: nerdgraph.UpdateTagsOperation}
+	tagMutations := map[string]string{nerdgraph.CreateOperation: nerdgraph.TagAddMutation, nerdgraph.UpdateOperation: nerdgraph.TagReplaceMutation}
+	for _, operation := range []string{nerdgraph.CreateOperation, nerdgraph.UpdateOperation, nerdgraph.DeleteOperation} {
+		field := service.GetOperation(operation)
+		if field == nil {
+			continue
+		}
+		o := &customresource.Operation{Name: field.Name, Document: documents[operation], EntityField: service.EntityField(field), ErrorsField: service.ErrorsField(field)}
+		if tagDocument, ok := documents[tagOperations[operation]]; ok && r.Mapping.TagProperty != "" {
+			o.TagDocument = tagDocument
+			o.TagName = tagMutations[operation]
+			o.TagErrorsField = service.ErrorsField(service.RootMutation(o.TagName))
+		}
+		r.Operations[operation] = o
+	}
+	return r, nil
+}
+
+func newProperty(property *model.Property) *customresource.Property {
+	p := &customresource.Property{Type: property.Type, Ref: strings.TrimPrefix(property.Ref, "#/definitions/")}
+	if property.Items != nil {
+		p.Items = &customresource.Property{Type: property.Items.Type, Ref: strings.TrimPrefix(property.Items.Ref, "#/definitions/")}
+	}
+	if len(property.Properties) > 0 {
+		p.Properties = make(map[string]*customresource.Property, len(property.Properties))
+		for name, field := range property.Properties {
+			p.Properties[name] = newProperty(field)
+		}
+	}
+	return p
+}
+
+// Generate
+// write dir/resources/<base name>.json for each service and dir/main.go, the Lambda serving all of them, with its go.mod
+// and the runtime packages in dir/internal
+func Generate(services []*nerdgraph.Service, dir string) error {
+	resourceDir := filepath.Join(dir, "resources")
+	if err := os.MkdirAll(resourceDir, 0755); err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	types := make([]string, 0, len(services))
+	for _, service := range services {
+		r, err := New(service)
+		if err != nil {
+			return err
+		}
+		b, err := json.MarshalIndent(r, "", "   ")
+		if err != nil {
+			return fmt.Errorf("custom: %s: %w", r.ResourceType, err)
+		}
+		if err = os.WriteFile(filepath.Join(resourceDir, service.Document().BaseName()+".json"), append(b, '\n'), 0644); err != nil {
+			return fmt.Errorf("custom: %w", err)
+		}
+		types = append(types, r.ResourceType)
+	}
+	sort.Strings(types)
+
+	data := &lambdaData{
+		Types:         types,
+		RuntimeImport: path.Join(Module, "internal", "customresource"),
+		ClientImport:  nerdgraph.RuntimeImport(Module, "client"),
+	}
+	var b bytes.Buffer
+	if err := lambdaTemplate.ExecuteTemplate(&b, "main.go.tmpl", data); err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	// Formatting is also the syntax check
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("custom: main.go: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "main.go"), source, 0644); err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "go.mod"), gomodel.GoMod(Module, "github.com/aws/aws-lambda-go"), 0644); err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	if err = nerdgraph.EmitRuntime(dir); err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	return emitRuntime(dir)
+}
+
+// emitRuntime
+// copy customresource, tests left out, into dir/internal/customresource importing the Lambda's NerdGraph packages
+func emitRuntime(dir string) error {
+	files, err := fs.Glob(runtime, "customresource/*.go")
+	if err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	pkgDir := filepath.Join(dir, "internal", "customresource")
+	if err = os.MkdirAll(pkgDir, 0755); err != nil {
+		return fmt.Errorf("custom: %w", err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		b, err := runtime.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("custom: %w", err)
+		}
+		b = bytes.ReplaceAll(b, []byte(runtimeImport), []byte(`"`+nerdgraph.RuntimeImport(Module, "")+"/"))
+		// The rewritten imports sort differently
+		source, err := format.Source(append([]byte(runtimeHeader), b...))
+		if err != nil {
+			return fmt.Errorf("custom: %s: %w", file, err)
+		}
+		if err = os.WriteFile(filepath.Join(pkgDir, path.Base(file)), source, 0644); err != nil {
+			return fmt.Errorf("custom: %w", err)
+		}
+	}
+	return nil
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
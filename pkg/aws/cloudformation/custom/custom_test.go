@@ -0,0 +1,109 @@
+package custom_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/custom"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedLambdaBuilds(t *testing.T) {
+	services, err := fixture.Services(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := make([]*nerdgraph.Service, 0, len(services))
+	for _, service := range services {
+		list = append(list, service)
+	}
+	dir := t.TempDir()
+	if err = custom.Generate(list, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"go.mod", "main.go", "resources/newrelic-observability-ainotificationschannel.json", "resources/newrelic-observability-ainotificationsdestination.json", "internal/customresource/handler.go", "internal/customresource/resource.go", "internal/nerdgraph/client/client.go", "internal/nerdgraph/mapping/mapping.go"} {
+		if _, err = os.Stat(filepath.Join(dir, file)); err != nil {
+			t.Error(err)
+		}
+	}
+	goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if !strings.Contains(string(goMod), "module "+custom.Module) || !strings.Contains(string(goMod), "github.com/aws/aws-lambda-go v1.55.1") || strings.Contains(string(goMod), "cloudformation-cli-go-plugin") {
+		t.Errorf("go.mod:\n%s", goMod)
+	}
+	// Nothing imports this module, the Lambda only has its copies
+	files := make([]string, 0)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		b, _ := os.ReadFile(file)
+		if strings.Contains(string(b), `"GraphQLSchema-to-CloudFormationSchema/`) {
+			t.Errorf("%s imports the generator's module", file)
+		}
+	}
+	fixture.Build(t, dir)
+}
+
+func TestNew(t *testing.T) {
+	service, err := fixture.Entity("aiNotificationsChannel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := custom.New(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ResourceType != "Custom::NewRelicAiNotificationsChannel" || r.PrimaryIdentifier != "Guid" {
+		t.Errorf("resource type %s, primary identifier %s", r.ResourceType, r.PrimaryIdentifier)
+	}
+	// Read-only properties are attributes, not properties
+	if strings.Join(r.Attributes, ",") != "ChannelId,Guid" {
+		t.Errorf("attributes %v", r.Attributes)
+	}
+	for _, name := range []string{"ServiceToken", "AccountId", "Channel", "Tags"} {
+		if r.Properties[name] == nil {
+			t.Errorf("no property %s", name)
+		}
+	}
+	if r.Properties["Guid"] != nil {
+		t.Error("read-only property Guid is a property")
+	}
+	if r.Properties["AccountId"].Type != "integer" {
+		t.Errorf("AccountId %+v", r.Properties["AccountId"])
+	}
+	create := r.Operations["create"]
+	if create == nil || create.Name != "aiNotificationsCreateChannel" || create.EntityField != "channel" || create.TagName != "taggingAddTagsToEntity" || create.TagErrorsField != "errors" {
+		t.Errorf("create %+v", create)
+	}
+	if update := r.Operations["update"]; update == nil || update.TagName != "taggingReplaceTagsOnEntity" {
+		t.Errorf("update %+v", update)
+	}
+	if remove := r.Operations["delete"]; remove == nil || remove.TagDocument != "" {
+		t.Errorf("delete %+v", remove)
+	}
+}
+
+func TestNewNotTaggable(t *testing.T) {
+	// The fixture's channel has no guid to tag
+	service, err := fixture.Service("aiNotificationsChannel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := custom.New(service)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Properties["Tags"] != nil || r.Operations["create"].TagDocument != "" || r.Operations["update"].TagDocument != "" {
+		t.Errorf("tags %+v, create %+v", r.Properties["Tags"], r.Operations["create"])
+	}
+}
@@ -0,0 +1,292 @@
+package customresource
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// Request types
+const (
+	Create = "Create"
+	Update = "Update"
+	Delete = "Delete"
+)
+
+// NotCreated the PhysicalResourceId of a failed create, the Delete CloudFormation sends when rolling back has nothing to do
+const NotCreated = "not-created"
+
+// Event
+// the parts of the custom resource request the handler uses
+type Event struct {
+	RequestType           string
+	ResourceType          string
+	PhysicalResourceID    string
+	ResourceProperties    map[string]interface{}
+	OldResourceProperties map[string]interface{}
+}
+
+// Handler
+// the Lambda's handler, Client is called per request so the credentials come from the environment each time
+type Handler struct {
+	Resources map[string]*Resource
+	Client    func() (*client.Client, error)
+}
+
+// Handle
+// the PhysicalResourceId and Data for the event. The id comes back on failure too, CloudFormation would take a
+// different one for a replacement
+func (h *Handler) Handle(ctx context.Context, event *Event) (string, map[string]interface{}, error) {
+	id := event.PhysicalResourceID
+	if event.RequestType == Create {
+		id = NotCreated
+	}
+	if event.RequestType == Delete && id == NotCreated {
+		return id, nil, nil
+	}
+	r, ok := h.Resources[event.ResourceType]
+	if !ok {
+		return id, nil, fmt.Errorf("custom: unknown resource type %s", event.ResourceType)
+	}
+	c, err := h.Client()
+	if err != nil {
+		return id, nil, err
+	}
+
+	switch event.RequestType {
+	case Create:
+		return r.create(ctx, c, event)
+	case Update:
+		replace, err := r.replaced(event)
+		if err != nil {
+			return id, nil, err
+		}
+		if replace {
+			// A failed replacement leaves the resource as it was, NotCreated would have CloudFormation delete nothing
+			// when it rolls back and the old one when it cleans up
+			replacement, data, err := r.create(ctx, c, event)
+			if err != nil {
+				return id, nil, err
+			}
+			return replacement, data, nil
+		}
+		return r.update(ctx, c, event)
+	case Delete:
+		return id, nil, r.delete(ctx, c, event)
+	}
+	return id, nil, fmt.Errorf("custom: unknown request type %s", event.RequestType)
+}
+
+func (r *Resource) create(ctx context.Context, c *client.Client, event *Event) (string, map[string]interface{}, error) {
+	current, err := r.Coerce(event.ResourceProperties)
+	if err != nil {
+		return NotCreated, nil, err
+	}
+	model, err := r.mutate(ctx, c, r.Operations["create"], current, nil)
+	if err != nil {
+		return NotCreated, nil, err
+	}
+	id := model[r.PrimaryIdentifier]
+	if id == nil {
+		return NotCreated, nil, fmt.Errorf("custom: %s: create returned no %s", r.ResourceType, r.PrimaryIdentifier)
+	}
+	return fmt.Sprint(id), r.Data(model), nil
+}
+
+func (r *Resource) update(ctx context.Context, c *client.Client, event *Event) (string, map[string]interface{}, error) {
+	current, err := r.identified(event.ResourceProperties, event.PhysicalResourceID)
+	if err != nil {
+		return event.PhysicalResourceID, nil, err
+	}
+	previous, err := r.identified(event.OldResourceProperties, event.PhysicalResourceID)
+	if err != nil {
+		return event.PhysicalResourceID, nil, err
+	}
+	model, err := r.mutate(ctx, c, r.Operations["update"], current, previous)
+	if err != nil {
+		return event.PhysicalResourceID, nil, err
+	}
+	return event.PhysicalResourceID, r.Data(model), nil
+}
+
+// delete
+// the resource, it being gone already is fine
+func (r *Resource) delete(ctx context.Context, c *client.Client, event *Event) error {
+	operation := r.Operations["delete"]
+	if operation == nil {
+		return fmt.Errorf("custom: %s: no NerdGraph mutation to delete it", r.ResourceType)
+	}
+	current, err := r.identified(event.ResourceProperties, event.PhysicalResourceID)
+	if err != nil {
+		return err
+	}
+	variables, err := r.Mapping.Variables(operation.Name, current, nil)
+	if err != nil {
+		return err
+	}
+	if _, err = c.Mutate(ctx, operation.Document, variables, operation.Name, "", operation.ErrorsField); err != nil && client.ErrorCode(err) != client.NotFound {
+		return err
+	}
+	return nil
+}
+
+// mutate
+// the operation with the model's variables, then the tags. The model with the entity copied in
+func (r *Resource) mutate(ctx context.Context, c *client.Client, operation *Operation, current map[string]interface{}, previous map[string]interface{}) (map[string]interface{}, error) {
+	if operation == nil {
+		return nil, fmt.Errorf("custom: %s: not supported by NerdGraph", r.ResourceType)
+	}
+	variables, err := r.Mapping.Variables(operation.Name, current, previous)
+	if err != nil {
+		return nil, err
+	}
+	entity, err := c.Mutate(ctx, operation.Document, variables, operation.Name, operation.EntityField, operation.ErrorsField)
+	if err != nil {
+		return nil, err
+	}
+	// From the request, the returned model may leave the tags out. Creating with none has nothing to tag, updating
+	// with none removes them
+	tags := r.Mapping.Tags(current)
+	model := r.Mapping.Model(entity, current)
+	if operation.TagDocument != "" && (len(tags) > 0 || previous != nil && tags != nil) {
+		tagVariables := map[string]interface{}{"guid": model[r.PrimaryIdentifier], "tags": tags}
+		if _, err = c.Mutate(ctx, operation.TagDocument, tagVariables, operation.TagName, "", operation.TagErrorsField); err != nil {
+			return nil, err
+		}
+	}
+	return model, nil
+}
+
+// replaced
+// whether the update needs a new resource: no update mutation, or a createOnly property changed
+func (r *Resource) replaced(event *Event) (bool, error) {
+	if r.Operations["update"] == nil {
+		return true, nil
+	}
+	current, err := r.Coerce(event.ResourceProperties)
+	if err != nil {
+		return false, err
+	}
+	previous, err := r.Coerce(event.OldResourceProperties)
+	if err != nil {
+		return false, err
+	}
+	for _, name := range r.CreateOnly {
+		if !reflect.DeepEqual(current[name], previous[name]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// identified
+// the coerced properties with the PhysicalResourceId in every identifier property
+func (r *Resource) identified(properties map[string]interface{}, id string) (map[string]interface{}, error) {
+	m, err := r.Coerce(properties)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range r.Mapping.IdentifierProperties {
+		m[name] = id
+	}
+	return m, nil
+}
+
+// Coerce
+// the resource model from the properties CloudFormation sent: ServiceToken dropped, the strings it made of numbers and
+// booleans converted back. Unknown and missing required properties are errors
+func (r *Resource) Coerce(properties map[string]interface{}) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(properties))
+	for name, value := range properties {
+		property, ok := r.Properties[name]
+		if !ok {
+			return nil, fmt.Errorf("custom: %s: unknown property %s", r.ResourceType, name)
+		}
+		if name == ServiceToken {
+			continue
+		}
+		v, err := r.coerce(value, property, make(map[string]bool))
+		if err != nil {
+			return nil, fmt.Errorf("custom: %s: %s: %w", r.ResourceType, name, err)
+		}
+		m[name] = v
+	}
+	for _, name := range r.Required {
+		if _, ok := properties[name]; !ok && name != ServiceToken {
+			return nil, fmt.Errorf("custom: %s: missing required property %s", r.ResourceType, name)
+		}
+	}
+	return m, nil
+}
+
+func (r *Resource) coerce(value interface{}, property *Property, visiting map[string]bool) (interface{}, error) {
+	if property.Ref != "" {
+		def := r.Definitions[property.Ref]
+		if def == nil || visiting[property.Ref] {
+			return value, nil
+		}
+		visiting[property.Ref] = true
+		defer delete(visiting, property.Ref)
+		property = def
+	}
+	switch v := value.(type) {
+	case string:
+		switch property.Type {
+		case "integer":
+			return strconv.ParseInt(v, 10, 64)
+		case "number":
+			return strconv.ParseFloat(v, 64)
+		case "boolean":
+			return strconv.ParseBool(v)
+		}
+	case []interface{}:
+		if property.Items == nil {
+			return value, nil
+		}
+		l := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			c, err := r.coerce(e, property.Items, visiting)
+			if err != nil {
+				return nil, err
+			}
+			l = append(l, c)
+		}
+		return l, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for name, e := range v {
+			field, ok := property.Properties[name]
+			if !ok {
+				m[name] = e
+				continue
+			}
+			c, err := r.coerce(e, field, visiting)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			m[name] = c
+		}
+		return m, nil
+	}
+	return value, nil
+}
+
+// Data
+// the attributes Fn::GetAtt reads, scalars as strings and anything else as JSON
+func (r *Resource) Data(model map[string]interface{}) map[string]interface{} {
+	data := make(map[string]interface{}, len(r.Attributes))
+	for _, name := range r.Attributes {
+		switch v := model[name].(type) {
+		case nil:
+		case map[string]interface{}, []interface{}:
+			b, _ := json.Marshal(v)
+			data[name] = string(b)
+		default:
+			data[name] = fmt.Sprint(v)
+		}
+	}
+	return data
+}
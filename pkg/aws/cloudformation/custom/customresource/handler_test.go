@@ -0,0 +1,195 @@
+package customresource_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/custom"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/custom/customresource"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mock"
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const resourceType = "Custom::NewRelicAiNotificationsChannel"
+
+// handler
+// a Handler for the fixture's channel entity against the mock server, and the number of clients it asked for
+func handler(t *testing.T) (*customresource.Handler, *int) {
+	t.Helper()
+	services, err := fixture.ServicesFrom(fixture.EntitySchema, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := mock.New(services)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	r, err := custom.New(services["aiNotificationsChannel"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	clients := 0
+	return &customresource.Handler{
+		Resources: map[string]*customresource.Resource{r.ResourceType: r},
+		Client: func() (*client.Client, error) {
+			clients++
+			return client.New(client.Access{ApiKey: "key", Endpoint: ts.URL})
+		},
+	}, &clients
+}
+
+// properties
+// the channel as CloudFormation sends it, every scalar a string
+func properties(name string, active string) map[string]interface{} {
+	channel := map[string]interface{}{"Name": name, "Type": "EMAIL", "Product": "IINT", "DestinationId": "d", "Properties": []interface{}{}}
+	if active != "" {
+		channel["Active"] = active
+	}
+	return map[string]interface{}{
+		"ServiceToken": "arn:aws:lambda:us-east-1:123456789012:function:newrelic",
+		"AccountId":    "1",
+		"Channel":      channel,
+		"Tags":         []interface{}{map[string]interface{}{"Key": "team", "Value": name}},
+	}
+}
+
+func TestHandle(t *testing.T) {
+	h, _ := handler(t)
+	ctx := context.Background()
+	id, data, err := h.Handle(ctx, &customresource.Event{RequestType: customresource.Create, ResourceType: resourceType, ResourceProperties: properties("a", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id == customresource.NotCreated || data["Guid"] != id || data["ChannelId"] != id {
+		t.Fatalf("created %s with %v", id, data)
+	}
+
+	updated, data, err := h.Handle(ctx, &customresource.Event{RequestType: customresource.Update, ResourceType: resourceType, PhysicalResourceID: id, ResourceProperties: properties("b", "true"), OldResourceProperties: properties("a", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != id || data["Guid"] != id {
+		t.Errorf("updated %s with %v, want %s", updated, data, id)
+	}
+
+	deleted, _, err := h.Handle(ctx, &customresource.Event{RequestType: customresource.Delete, ResourceType: resourceType, PhysicalResourceID: id, ResourceProperties: properties("b", "true")})
+	if err != nil || deleted != id {
+		t.Fatalf("deleted %s: %v", deleted, err)
+	}
+	// It being gone already is fine
+	if _, _, err = h.Handle(ctx, &customresource.Event{RequestType: customresource.Delete, ResourceType: resourceType, PhysicalResourceID: id, ResourceProperties: properties("b", "true")}); err != nil {
+		t.Errorf("deleting again: %v", err)
+	}
+}
+
+func TestHandleFails(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   *customresource.Event
+		wantID  string
+		wantErr string
+	}{
+		{
+			name:    "unknown resource type",
+			event:   &customresource.Event{RequestType: customresource.Update, ResourceType: "Custom::Other", PhysicalResourceID: "c"},
+			wantID:  "c",
+			wantErr: "unknown resource type Custom::Other",
+		},
+		{
+			name:    "unknown property",
+			event:   &customresource.Event{RequestType: customresource.Create, ResourceType: resourceType, ResourceProperties: map[string]interface{}{"AccountId": "1", "Channel": map[string]interface{}{}, "Other": "o"}},
+			wantID:  customresource.NotCreated,
+			wantErr: "unknown property Other",
+		},
+		{
+			name:    "missing required property",
+			event:   &customresource.Event{RequestType: customresource.Create, ResourceType: resourceType, ResourceProperties: map[string]interface{}{"AccountId": "1"}},
+			wantID:  customresource.NotCreated,
+			wantErr: "missing required property Channel",
+		},
+		{
+			name:    "a string that isn't an integer",
+			event:   &customresource.Event{RequestType: customresource.Create, ResourceType: resourceType, ResourceProperties: map[string]interface{}{"AccountId": "one", "Channel": map[string]interface{}{}}},
+			wantID:  customresource.NotCreated,
+			wantErr: "AccountId",
+		},
+		{
+			name:    "unknown request type",
+			event:   &customresource.Event{RequestType: "Read", ResourceType: resourceType, PhysicalResourceID: "c"},
+			wantID:  "c",
+			wantErr: "unknown request type Read",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := handler(t)
+			id, _, err := h.Handle(context.Background(), tt.event)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("got error %v, want %q", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Errorf("got id %s, want %s", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestReplaceFails(t *testing.T) {
+	h, _ := handler(t)
+	ctx := context.Background()
+	id, _, err := h.Handle(ctx, &customresource.Event{RequestType: customresource.Create, ResourceType: resourceType, ResourceProperties: properties("a", "")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changing a createOnly property replaces the channel, NerdGraph rejects the new one
+	h.Resources[resourceType].CreateOnly = []string{"AccountId"}
+	replaced := properties("a", "")
+	replaced["AccountId"] = "2"
+	replaced["Channel"].(map[string]interface{})["Type"] = "NOPE"
+	got, _, err := h.Handle(ctx, &customresource.Event{RequestType: customresource.Update, ResourceType: resourceType, PhysicalResourceID: id, ResourceProperties: replaced, OldResourceProperties: properties("a", "")})
+	if err == nil {
+		t.Fatal("replaced with an invalid channel type")
+	}
+	if got != id {
+		t.Errorf("got id %s, want the existing %s", got, id)
+	}
+}
+
+func TestDeleteNotCreated(t *testing.T) {
+	// The rollback of a failed create, there's nothing to delete and no credentials needed
+	h, clients := handler(t)
+	id, _, err := h.Handle(context.Background(), &customresource.Event{RequestType: customresource.Delete, ResourceType: resourceType, PhysicalResourceID: customresource.NotCreated})
+	if err != nil || id != customresource.NotCreated || *clients != 0 {
+		t.Errorf("got %s, %v after %d clients", id, err, *clients)
+	}
+}
+
+func TestCoerce(t *testing.T) {
+	h, _ := handler(t)
+	got, err := h.Resources[resourceType].Coerce(properties("a", "false"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"AccountId": int64(1),
+		"Channel":   map[string]interface{}{"Name": "a", "Type": "EMAIL", "Product": "IINT", "DestinationId": "d", "Properties": []interface{}{}, "Active": false},
+		"Tags":      []interface{}{map[string]interface{}{"Key": "team", "Value": "a"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestData(t *testing.T) {
+	r := &customresource.Resource{Attributes: []string{"Guid", "Count", "Labels", "Missing"}}
+	got := r.Data(map[string]interface{}{"Guid": "g", "Count": 2, "Labels": []interface{}{"a"}, "Other": "o"})
+	want := map[string]interface{}{"Guid": "g", "Count": "2", "Labels": `["a"]`}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
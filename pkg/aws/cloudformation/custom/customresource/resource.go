@@ -0,0 +1,76 @@
+package customresource
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mapping"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+)
+
+/*
+The custom resource Lambda's runtime: the resources' property contracts and the handler serving them. The generated
+Lambda is its own module, it gets a copy of the package with the imports pointing at its copies of the NerdGraph client
+and mapping, so it only imports those and the standard library.
+*/
+
+// ServiceToken the property every custom resource has, the Lambda's ARN
+const ServiceToken = "ServiceToken"
+
+// Resource
+// a Custom:: resource type: the property contract and how it maps to NerdGraph
+type Resource struct {
+	ResourceType      string                `json:"resourceType"` // Custom::NewRelic<Service>
+	TypeName          string                `json:"typeName"`     // The registry type it stands in for
+	Properties        map[string]*Property  `json:"properties"`
+	Required          []string              `json:"required"`
+	Definitions       map[string]*Property  `json:"definitions,omitempty"`
+	Attributes        []string              `json:"attributes"` // Data keys, Fn::GetAtt <logical id>.<attribute>
+	PrimaryIdentifier string                `json:"primaryIdentifier"`
+	CreateOnly        []string              `json:"createOnly,omitempty"`
+	Operations        map[string]*Operation `json:"operations"` // create | update | delete
+	Mapping           *mapping.Mapping      `json:"mapping"`
+}
+
+// Property
+// a property's JSON type, what its strings are coerced to
+type Property struct {
+	Type       string               `json:"type,omitempty"`
+	Ref        string               `json:"definition,omitempty"` // Definition name
+	Items      *Property            `json:"items,omitempty"`
+	Properties map[string]*Property `json:"properties,omitempty"`
+}
+
+// Operation
+// a mutation and where its payload keeps the entity
+type Operation struct {
+	Name        string `json:"name"`
+	Document    string `json:"document"`
+	EntityField string `json:"entityField,omitempty"`
+	ErrorsField string `json:"errorsField,omitempty"`
+	// The tag mutation tagging the entity afterwards
+	TagDocument    string `json:"tagDocument,omitempty"`
+	TagName        string `json:"tagName,omitempty"`
+	TagErrorsField string `json:"tagErrorsField,omitempty"`
+}
+
+// Load
+// the resources Generate wrote, by resource type
+func Load(fsys fs.FS) (map[string]*Resource, error) {
+	files, err := fs.Glob(fsys, "resources/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("custom: %w", err)
+	}
+	resources := make(map[string]*Resource, len(files))
+	for _, file := range files {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("custom: %w", err)
+		}
+		r := &Resource{}
+		if err = json.Unmarshal(b, r); err != nil {
+			return nil, fmt.Errorf("custom: %s: %w", file, err)
+		}
+		resources[r.ResourceType] = r
+	}
+	return resources, nil
+}
@@ -0,0 +1,79 @@
+package diff
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// remediations how to keep a published type compatible, by breaking kind
+var remediations = map[string]string{
+	ResourceRemoved:          "the SDL no longer has the service's mutations: keep the published version registered until stacks have moved off it, then deregister it",
+	PropertyRemoved:          "keep the property in the schema and ignore it in the handlers, or publish the change under a new TypeName",
+	RequiredPropertyAdded:    "keep the property optional in the published schema and default it in the create handler, or publish under a new TypeName",
+	MadeRequired:             "keep the property optional in the published schema and default it in the create handler, or publish under a new TypeName",
+	TypeChanged:              "keep the published JSON type and convert in the handlers, or add a property with the new type and deprecate the old one",
+	EnumNarrowed:             "keep the removed values in the schema and reject them in the handlers with a clear message, existing stacks still use them",
+	CreateOnlyAdded:          "keep it updatable: NerdGraph's update mutation has to take it, otherwise stack updates changing it replace resources",
+	UpdateRemoved:            "keep an update mutation for the service, without it every stack update replaces the resource",
+	ReadOnlyAdded:            "keep it writable and copy NerdGraph's value back, or add a new read-only property for the assigned value",
+	ReadOnlyRemoved:          "keep it read-only for Fn::GetAtt, add a new writable property for the input",
+	PrimaryIdentifierChanged: "a published type's primaryIdentifier can't change, existing resources are tracked by it: publish under a new TypeName",
+}
+
+// Verdict
+// whether the current type can replace the published one in the registry
+type Verdict struct {
+	TypeName  string    `json:"typeName"`
+	Published string    `json:"published"` // The published schema's file
+	Pass      bool      `json:"pass"`
+	Changes   []*Change `json:"changes"`
+}
+
+// Compat
+// the published schema against the one generated from the current SDL, nil current when the SDL no longer has the
+// service. Breaking changes fail and come with a remediation
+func Compat(published string, old *model.Document, current *model.Document) *Verdict {
+	v := &Verdict{TypeName: old.TypeName, Published: published, Pass: true}
+	if current == nil {
+		v.Changes = []*Change{newChange(old.TypeName, "", ResourceRemoved, nil, nil, "no service in the SDL generates the type")}
+	} else {
+		v.Changes = Documents(old, current)
+	}
+	sort.SliceStable(v.Changes, func(i, j int) bool { return v.Changes[i].Path < v.Changes[j].Path })
+	for _, change := range v.Changes {
+		if change.Breaking {
+			v.Pass = false
+			change.Remediation = remediations[change.Kind]
+		}
+	}
+	return v
+}
+
+// Text
+// PASS or FAIL with the type, then the breaking changes and their remediation, the compatible ones after
+func (v *Verdict) Text() string {
+	var b strings.Builder
+	verdict := "PASS"
+	if !v.Pass {
+		verdict = "FAIL"
+	}
+	b.WriteString(fmt.Sprintf("%s %s (%s)\n", verdict, v.TypeName, v.Published))
+	for _, breaking := range []bool{true, false} {
+		for _, change := range v.Changes {
+			if change.Breaking != breaking {
+				continue
+			}
+			label := "compatible"
+			if breaking {
+				label = "breaking"
+			}
+			b.WriteString(fmt.Sprintf("  %s: %s\n", label, change.line()))
+			if change.Remediation != "" {
+				b.WriteString(fmt.Sprintf("    fix: %s\n", change.Remediation))
+			}
+		}
+	}
+	return b.String()
+}
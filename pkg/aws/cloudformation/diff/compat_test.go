@@ -0,0 +1,125 @@
+package diff_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/diff"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"strings"
+	"testing"
+)
+
+func TestCompat(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(d *model.Document)
+		removed bool // The SDL no longer has the service
+		pass    bool
+		kind    string // The change, breaking unless pass
+	}{
+		{
+			name: "unchanged",
+			pass: true,
+		},
+		{
+			name: "optional property added",
+			edit: func(d *model.Document) { d.Properties["Description"] = &model.Property{Type: "string"} },
+			pass: true,
+			kind: diff.PropertyAdded,
+		},
+		{
+			name: "createOnly removed",
+			edit: func(d *model.Document) { d.CreateOnlyProperties = nil },
+			pass: true,
+			kind: diff.CreateOnlyRemoved,
+		},
+		{
+			name: "primaryIdentifier change",
+			edit: func(d *model.Document) { d.PrimaryIdentifier = []string{"/properties/Name"} },
+			kind: diff.PrimaryIdentifierChanged,
+		},
+		{
+			name: "newly required",
+			edit: func(d *model.Document) { d.Required = append(d.Required, "Region") },
+			kind: diff.MadeRequired,
+		},
+		{
+			name: "required property added",
+			edit: func(d *model.Document) {
+				d.Properties["Owner"] = &model.Property{Type: "string"}
+				d.Required = append(d.Required, "Owner")
+			},
+			kind: diff.RequiredPropertyAdded,
+		},
+		{
+			name: "removed property",
+			edit: func(d *model.Document) { delete(d.Properties, "Region") },
+			kind: diff.PropertyRemoved,
+		},
+		{
+			name: "made create-only",
+			edit: func(d *model.Document) { d.CreateOnlyProperties = append(d.CreateOnlyProperties, "/properties/Name") },
+			kind: diff.CreateOnlyAdded,
+		},
+		{
+			name: "made read-only",
+			edit: func(d *model.Document) { d.ReadOnlyProperties = append(d.ReadOnlyProperties, "/properties/Name") },
+			kind: diff.ReadOnlyAdded,
+		},
+		{
+			name: "no longer read-only",
+			edit: func(d *model.Document) { d.ReadOnlyProperties = nil },
+			kind: diff.ReadOnlyRemoved,
+		},
+		{
+			name:    "service removed",
+			removed: true,
+			kind:    diff.ResourceRemoved,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var current *model.Document
+			if !tt.removed {
+				current = document(t, tt.edit)
+			}
+			v := diff.Compat("thing.json", document(t, nil), current)
+			if v.Pass != tt.pass {
+				t.Errorf("pass %t, want %t:\n%s", v.Pass, tt.pass, v.Text())
+			}
+			if tt.kind == "" {
+				if len(v.Changes) > 0 {
+					t.Errorf("changes:\n%s", v.Text())
+				}
+				return
+			}
+			if len(v.Changes) != 1 || v.Changes[0].Kind != tt.kind {
+				t.Fatalf("want one %s:\n%s", tt.kind, v.Text())
+			}
+			// Only breaking changes need fixing, each with a hint
+			change := v.Changes[0]
+			if change.Breaking == tt.pass || (change.Remediation != "") == tt.pass {
+				t.Errorf("breaking %t with remediation %q", change.Breaking, change.Remediation)
+			}
+		})
+	}
+}
+
+func TestVerdictText(t *testing.T) {
+	v := diff.Compat("thing.json", document(t, nil), document(t, func(d *model.Document) {
+		d.Properties["Description"] = &model.Property{Type: "string"}
+		d.PrimaryIdentifier = []string{"/properties/Name"}
+	}))
+	text := v.Text()
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got\n%s", text)
+	}
+	// The verdict, breaking changes with their fix, then the compatible ones
+	for i, prefix := range []string{"FAIL NewRelic::Observability::thing (thing.json)", "  breaking: primaryIdentifier changed", "    fix: ", "  compatible: `/properties/Description`"} {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d: got %q, want %q...", i, lines[i], prefix)
+		}
+	}
+	if pass := diff.Compat("thing.json", document(t, nil), document(t, nil)).Text(); pass != "PASS NewRelic::Observability::thing (thing.json)\n" {
+		t.Errorf("got %q", pass)
+	}
+}
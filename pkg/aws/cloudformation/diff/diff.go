@@ -0,0 +1,403 @@
+package diff
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+Resource by resource comparison of the Documents generated from two NerdGraph schema snapshots, classified by
+CloudFormation compatibility: a change is breaking when a template or stack that worked with the old type can fail
+with the new one
+
+- Breaking: removed resource or property, added required property, a property made required, type change (the JSON
+  type, renaming the GraphQL type behind a $ref isn't a change), narrowed enum, new createOnlyProperty (updates
+  replace the resource), no update handler any more (every property is create-only), a property made read-only or
+  writable (Fn::GetAtt reads only read-only ones), a different primaryIdentifier
+- Compatible: added resource or optional property, a property made optional, widened enum, removed createOnlyProperty,
+  a new update handler
+
+Nested properties are compared through the definitions, paths are CloudFormation's: /properties/Channel/Type, list
+items /*.
+*/
+
+// Change kinds
+const (
+	ResourceAdded            = "resource-added"
+	ResourceRemoved          = "resource-removed"
+	PropertyAdded            = "property-added"
+	RequiredPropertyAdded    = "required-property-added"
+	PropertyRemoved          = "property-removed"
+	MadeRequired             = "made-required"
+	MadeOptional             = "made-optional"
+	TypeChanged              = "type-changed"
+	EnumNarrowed             = "enum-narrowed"
+	EnumWidened              = "enum-widened"
+	CreateOnlyAdded          = "create-only-added"
+	CreateOnlyRemoved        = "create-only-removed"
+	UpdateRemoved            = "update-removed"
+	UpdateAdded              = "update-added"
+	ReadOnlyAdded            = "read-only-added"
+	ReadOnlyRemoved          = "read-only-removed"
+	PrimaryIdentifierChanged = "primary-identifier-changed"
+)
+
+// breaking the kinds existing templates or stacks can fail on
+var breaking = map[string]bool{
+	ResourceRemoved:          true,
+	RequiredPropertyAdded:    true,
+	PropertyRemoved:          true,
+	MadeRequired:             true,
+	TypeChanged:              true,
+	EnumNarrowed:             true,
+	CreateOnlyAdded:          true,
+	UpdateRemoved:            true,
+	ReadOnlyAdded:            true,
+	ReadOnlyRemoved:          true,
+	PrimaryIdentifierChanged: true,
+}
+
+// Change
+// one difference in a resource type
+type Change struct {
+	Resource string      `json:"resource"` // TypeName
+	Path     string      `json:"path,omitempty"`
+	Kind     string      `json:"kind"`
+	Breaking bool        `json:"breaking"`
+	Old      interface{} `json:"old,omitempty"`
+	New      interface{} `json:"new,omitempty"`
+	Message  string      `json:"message"`
+	// How to keep the published type compatible, set by Compat
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// Report
+// the changes between two snapshots
+type Report struct {
+	Old      string    `json:"old"`
+	New      string    `json:"new"`
+	Breaking bool      `json:"breaking"`
+	Changes  []*Change `json:"changes"`
+}
+
+// Compare
+// the changes from the old documents to the new ones, matched by TypeName, sorted by resource and path
+func Compare(old []*model.Document, new []*model.Document) []*Change {
+	oldDocs := byTypeName(old)
+	newDocs := byTypeName(new)
+	changes := make([]*Change, 0)
+	for _, typeName := range union(keys(oldDocs), keys(newDocs)) {
+		o, n := oldDocs[typeName], newDocs[typeName]
+		switch {
+		case o == nil:
+			changes = append(changes, newChange(typeName, "", ResourceAdded, nil, nil, "new resource type"))
+		case n == nil:
+			changes = append(changes, newChange(typeName, "", ResourceRemoved, nil, nil, "resource type removed, stacks using it can't be updated"))
+		default:
+			changes = append(changes, Documents(o, n)...)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Resource != changes[j].Resource {
+			return changes[i].Resource < changes[j].Resource
+		}
+		return changes[i].Path < changes[j].Path
+	})
+	return changes
+}
+
+// Documents
+// the changes between two versions of a resource type
+func Documents(old *model.Document, new *model.Document) []*Change {
+	c := &comparer{resource: new.TypeName, old: old, new: new, visiting: make(map[string]bool)}
+	c.properties("/properties/", old.Properties, new.Properties, old.Required, new.Required)
+	c.status()
+	switch {
+	case old.Handlers["update"] != nil && new.Handlers["update"] == nil:
+		c.add("", UpdateRemoved, nil, nil, "no update handler, changing any property replaces the resource")
+	case old.Handlers["update"] == nil && new.Handlers["update"] != nil:
+		c.add("", UpdateAdded, nil, nil, "new update handler, properties that aren't create-only are updated in place")
+	}
+	return c.changes
+}
+
+// NewReport
+// the report, breaking when any change is
+func NewReport(old string, new string, changes []*Change) *Report {
+	r := &Report{Old: old, New: new, Changes: changes}
+	for _, change := range changes {
+		r.Breaking = r.Breaking || change.Breaking
+	}
+	return r
+}
+
+// Write
+// dir/diff.md, the changelog, and dir/diff.json
+func (r *Report) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	b, err := json.MarshalIndent(r, "", "   ")
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "diff.json"), append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "diff.md"), []byte(r.Markdown()), 0644); err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	return nil
+}
+
+// Markdown
+// the changelog, breaking changes first for each resource
+func (r *Report) Markdown() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("# Resource type changes: %s -> %s\n\n", r.Old, r.New))
+	if len(r.Changes) == 0 {
+		b.WriteString("No changes.\n")
+		return b.String()
+	}
+	if r.Breaking {
+		b.WriteString("**Breaking changes**, existing templates or stacks can fail with the new types.\n")
+	} else {
+		b.WriteString("All changes are compatible.\n")
+	}
+	resources := make([]string, 0)
+	byResource := make(map[string][]*Change)
+	for _, change := range r.Changes {
+		if _, ok := byResource[change.Resource]; !ok {
+			resources = append(resources, change.Resource)
+		}
+		byResource[change.Resource] = append(byResource[change.Resource], change)
+	}
+	for _, resource := range resources {
+		b.WriteString(fmt.Sprintf("\n## %s\n", resource))
+		for _, section := range []struct {
+			title    string
+			breaking bool
+		}{{"Breaking", true}, {"Compatible", false}} {
+			lines := make([]string, 0)
+			for _, change := range byResource[resource] {
+				if change.Breaking == section.breaking {
+					lines = append(lines, "- "+change.line())
+				}
+			}
+			if len(lines) > 0 {
+				b.WriteString(fmt.Sprintf("\n### %s\n\n%s\n", section.title, strings.Join(lines, "\n")))
+			}
+		}
+	}
+	return b.String()
+}
+
+// line
+// the change as a changelog entry
+func (c *Change) line() string {
+	if c.Path == "" {
+		return fmt.Sprintf("%s (`%s`)", c.Message, c.Kind)
+	}
+	return fmt.Sprintf("`%s`: %s (`%s`)", c.Path, c.Message, c.Kind)
+}
+
+func newChange(resource string, path string, kind string, old interface{}, new interface{}, message string) *Change {
+	return &Change{Resource: resource, Path: path, Kind: kind, Breaking: breaking[kind], Old: old, New: new, Message: message}
+}
+
+type comparer struct {
+	resource string
+	old      *model.Document
+	new      *model.Document
+	visiting map[string]bool // old|new definition pairs being compared
+	changes  []*Change
+}
+
+func (c *comparer) add(path string, kind string, old interface{}, new interface{}, format string, args ...interface{}) {
+	c.changes = append(c.changes, newChange(c.resource, path, kind, old, new, fmt.Sprintf(format, args...)))
+}
+
+// properties
+// an object's properties, prefix is the object's path
+func (c *comparer) properties(prefix string, old map[string]*model.Property, new map[string]*model.Property, oldRequired []string, newRequired []string) {
+	wasRequired := set(oldRequired)
+	isRequired := set(newRequired)
+	for _, name := range union(keys(old), keys(new)) {
+		path := prefix + name
+		o, n := old[name], new[name]
+		switch {
+		case o == nil && isRequired[name]:
+			c.add(path, RequiredPropertyAdded, nil, nil, "new required property, existing templates don't set it")
+		case o == nil:
+			c.add(path, PropertyAdded, nil, nil, "new optional property")
+		case n == nil:
+			c.add(path, PropertyRemoved, nil, nil, "property removed, templates setting it fail")
+		default:
+			if !wasRequired[name] && isRequired[name] {
+				c.add(path, MadeRequired, nil, nil, "now required, templates that don't set it fail")
+			} else if wasRequired[name] && !isRequired[name] {
+				c.add(path, MadeOptional, nil, nil, "now optional")
+			}
+			c.property(path, o, n)
+		}
+	}
+}
+
+// property
+// the JSON type, enum values, list items and nested properties
+func (c *comparer) property(path string, old *model.Property, new *model.Property) {
+	oldType, newType := jsonType(c.old, old), jsonType(c.new, new)
+	if oldType != newType {
+		c.add(path, TypeChanged, oldType, newType, "type changed from %s to %s", oldType, newType)
+		return
+	}
+	if oldType == "array" {
+		if old.Items != nil && new.Items != nil {
+			c.property(path+"/*", &model.Property{Type: old.Items.Type, Ref: old.Items.Ref}, &model.Property{Type: new.Items.Type, Ref: new.Items.Ref})
+		}
+		return
+	}
+	oldDef, oldName := resolve(c.old, old)
+	newDef, newName := resolve(c.new, new)
+	switch {
+	case len(oldDef.Enum) == 0 && len(newDef.Enum) == 0:
+	case len(newDef.Enum) == 0:
+		c.add(path, EnumWidened, oldDef.Enum, nil, "any value allowed, it was one of %s", strings.Join(oldDef.Enum, ", "))
+	case len(oldDef.Enum) == 0:
+		c.add(path, EnumNarrowed, nil, newDef.Enum, "only %s allowed", strings.Join(newDef.Enum, ", "))
+	default:
+		if removed := difference(oldDef.Enum, newDef.Enum); len(removed) > 0 {
+			c.add(path, EnumNarrowed, oldDef.Enum, newDef.Enum, "%s no longer allowed", strings.Join(removed, ", "))
+		}
+		if added := difference(newDef.Enum, oldDef.Enum); len(added) > 0 {
+			c.add(path, EnumWidened, oldDef.Enum, newDef.Enum, "new allowed values %s", strings.Join(added, ", "))
+		}
+	}
+	if len(oldDef.Properties) > 0 || len(newDef.Properties) > 0 {
+		pair := oldName + "|" + newName
+		if c.visiting[pair] {
+			return
+		}
+		if oldName != "" || newName != "" {
+			c.visiting[pair] = true
+			defer delete(c.visiting, pair)
+		}
+		c.properties(path+"/", oldDef.Properties, newDef.Properties, oldDef.Required, newDef.Required)
+	}
+}
+
+// status
+// createOnly and readOnly status changes of properties both versions have, and a different primaryIdentifier
+func (c *comparer) status() {
+	changed := make(map[string]bool)
+	for _, change := range c.changes {
+		if change.Kind == PropertyAdded || change.Kind == RequiredPropertyAdded || change.Kind == PropertyRemoved {
+			changed[change.Path] = true
+		}
+	}
+	for _, s := range []struct {
+		old, new        []string
+		added, removed  string
+		onAdd, onRemove string
+	}{
+		{c.old.CreateOnlyProperties, c.new.CreateOnlyProperties, CreateOnlyAdded, CreateOnlyRemoved,
+			"now create-only, changing it replaces the resource", "no longer create-only, it's updated in place"},
+		{c.old.ReadOnlyProperties, c.new.ReadOnlyProperties, ReadOnlyAdded, ReadOnlyRemoved,
+			"now read-only, templates setting it fail", "no longer read-only, Fn::GetAtt on it fails"},
+	} {
+		for _, path := range difference(s.new, s.old) {
+			if !changed[path] {
+				c.add(path, s.added, nil, nil, s.onAdd)
+			}
+		}
+		for _, path := range difference(s.old, s.new) {
+			if !changed[path] {
+				c.add(path, s.removed, nil, nil, s.onRemove)
+			}
+		}
+	}
+	if strings.Join(c.old.PrimaryIdentifier, ",") != strings.Join(c.new.PrimaryIdentifier, ",") {
+		c.add("", PrimaryIdentifierChanged, c.old.PrimaryIdentifier, c.new.PrimaryIdentifier, "primaryIdentifier changed from %s to %s",
+			strings.Join(c.old.PrimaryIdentifier, ", "), strings.Join(c.new.PrimaryIdentifier, ", "))
+	}
+}
+
+// resolve
+// the referenced definition and its name, the property itself when it isn't a reference
+func resolve(doc *model.Document, property *model.Property) (*model.Property, string) {
+	name := strings.TrimPrefix(property.Ref, "#/definitions/")
+	if def := doc.Definitions[name]; property.Ref != "" && def != nil {
+		return def, name
+	}
+	return property, ""
+}
+
+// jsonType
+// the property's JSON type after following its $ref
+func jsonType(doc *model.Document, property *model.Property) string {
+	if property.Type == "array" {
+		return "array"
+	}
+	def, _ := resolve(doc, property)
+	switch {
+	case def.Type != "":
+		return def.Type
+	case len(def.Properties) > 0 || len(def.AnyOf) > 0 || len(def.OneOf) > 0:
+		return "object"
+	}
+	return "string"
+}
+
+func byTypeName(docs []*model.Document) map[string]*model.Document {
+	m := make(map[string]*model.Document, len(docs))
+	for _, doc := range docs {
+		m[doc.TypeName] = doc
+	}
+	return m
+}
+
+func keys[V any](m map[string]V) []string {
+	k := make([]string, 0, len(m))
+	for name := range m {
+		k = append(k, name)
+	}
+	return k
+}
+
+// union
+// the names in either list, sorted
+func union(a []string, b []string) []string {
+	s := set(a)
+	for _, name := range b {
+		s[name] = true
+	}
+	names := keys(s)
+	sort.Strings(names)
+	return names
+}
+
+// difference
+// the values in a that aren't in b, in a's order
+func difference(a []string, b []string) []string {
+	in := set(b)
+	d := make([]string, 0)
+	for _, v := range a {
+		if !in[v] {
+			d = append(d, v)
+		}
+	}
+	return d
+}
+
+func set(list []string) map[string]bool {
+	s := make(map[string]bool, len(list))
+	for _, v := range list {
+		s[v] = true
+	}
+	return s
+}
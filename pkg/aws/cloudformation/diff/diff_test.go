@@ -0,0 +1,284 @@
+package diff_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/diff"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const thing = `{
+   "typeName": "NewRelic::Observability::thing",
+   "definitions": {
+      "Kind": {"type": "string", "enum": ["A", "B"]},
+      "Settings": {"type": "object", "properties": {"Size": {"type": "integer"}, "Label": {"type": "string"}}, "required": ["Size"]}
+   },
+   "properties": {
+      "Guid": {"type": "string"},
+      "Name": {"type": "string"},
+      "Kind": {"$ref": "#/definitions/Kind"},
+      "Settings": {"$ref": "#/definitions/Settings"},
+      "Labels": {"type": "array", "items": {"type": "string"}},
+      "Region": {"type": "string"}
+   },
+   "required": ["Name"],
+   "readOnlyProperties": ["/properties/Guid"],
+   "createOnlyProperties": ["/properties/Region"],
+   "primaryIdentifier": ["/properties/Guid"],
+   "handlers": {"create": {"permissions": []}, "read": {"permissions": []}, "update": {"permissions": []}, "delete": {"permissions": []}}
+}`
+
+// document
+// thing, changed by edit
+func document(t *testing.T, edit func(d *model.Document)) *model.Document {
+	t.Helper()
+	d := &model.Document{}
+	if err := json.Unmarshal([]byte(thing), d); err != nil {
+		t.Fatal(err)
+	}
+	if edit != nil {
+		edit(d)
+	}
+	return d
+}
+
+// kinds
+// the changes as "<kind> <path>"
+func kinds(changes []*diff.Change) []string {
+	l := make([]string, 0, len(changes))
+	for _, change := range changes {
+		l = append(l, strings.TrimSpace(change.Kind+" "+change.Path))
+	}
+	return l
+}
+
+func TestDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     func(d *model.Document)
+		want     []string
+		breaking bool
+	}{
+		{
+			name: "no change",
+			want: []string{},
+		},
+		{
+			name: "added optional property",
+			edit: func(d *model.Document) {
+				d.Properties["Description"] = &model.Property{Type: "string"}
+			},
+			want: []string{"property-added /properties/Description"},
+		},
+		{
+			name: "added required property",
+			edit: func(d *model.Document) {
+				d.Properties["Owner"] = &model.Property{Type: "string"}
+				d.Required = append(d.Required, "Owner")
+			},
+			want:     []string{"required-property-added /properties/Owner"},
+			breaking: true,
+		},
+		{
+			name: "removed property",
+			edit: func(d *model.Document) {
+				delete(d.Properties, "Labels")
+			},
+			want:     []string{"property-removed /properties/Labels"},
+			breaking: true,
+		},
+		{
+			name: "removed nested property",
+			edit: func(d *model.Document) {
+				delete(d.Definitions["Settings"].Properties, "Label")
+			},
+			want:     []string{"property-removed /properties/Settings/Label"},
+			breaking: true,
+		},
+		{
+			name: "made required",
+			edit: func(d *model.Document) {
+				d.Required = append(d.Required, "Labels")
+			},
+			want:     []string{"made-required /properties/Labels"},
+			breaking: true,
+		},
+		{
+			name: "made optional",
+			edit: func(d *model.Document) {
+				d.Definitions["Settings"].Required = nil
+			},
+			want: []string{"made-optional /properties/Settings/Size"},
+		},
+		{
+			name: "type change",
+			edit: func(d *model.Document) {
+				d.Definitions["Settings"].Properties["Size"].Type = "string"
+			},
+			want:     []string{"type-changed /properties/Settings/Size"},
+			breaking: true,
+		},
+		{
+			name: "list item type change",
+			edit: func(d *model.Document) {
+				d.Properties["Labels"].Items.Type = "integer"
+			},
+			want:     []string{"type-changed /properties/Labels/*"},
+			breaking: true,
+		},
+		{
+			// Renaming the GraphQL type behind the $ref isn't a change
+			name: "renamed definition",
+			edit: func(d *model.Document) {
+				d.Definitions["ThingKind"] = d.Definitions["Kind"]
+				delete(d.Definitions, "Kind")
+				d.Properties["Kind"].Ref = "#/definitions/ThingKind"
+			},
+			want: []string{},
+		},
+		{
+			name: "narrowed enum",
+			edit: func(d *model.Document) {
+				d.Definitions["Kind"].Enum = []string{"A"}
+			},
+			want:     []string{"enum-narrowed /properties/Kind"},
+			breaking: true,
+		},
+		{
+			name: "widened enum",
+			edit: func(d *model.Document) {
+				d.Definitions["Kind"].Enum = []string{"A", "B", "C"}
+			},
+			want: []string{"enum-widened /properties/Kind"},
+		},
+		{
+			name: "new enum",
+			edit: func(d *model.Document) {
+				d.Properties["Name"].Enum = []string{"X"}
+			},
+			want:     []string{"enum-narrowed /properties/Name"},
+			breaking: true,
+		},
+		{
+			name: "new createOnly",
+			edit: func(d *model.Document) {
+				d.CreateOnlyProperties = append(d.CreateOnlyProperties, "/properties/Name")
+			},
+			want:     []string{"create-only-added /properties/Name"},
+			breaking: true,
+		},
+		{
+			name: "createOnly removed",
+			edit: func(d *model.Document) {
+				d.CreateOnlyProperties = nil
+			},
+			want: []string{"create-only-removed /properties/Region"},
+		},
+		{
+			// A new property that's create-only is only the new property
+			name: "new createOnly property",
+			edit: func(d *model.Document) {
+				d.Properties["Zone"] = &model.Property{Type: "string"}
+				d.CreateOnlyProperties = append(d.CreateOnlyProperties, "/properties/Zone")
+			},
+			want: []string{"property-added /properties/Zone"},
+		},
+		{
+			name: "made read-only",
+			edit: func(d *model.Document) {
+				d.ReadOnlyProperties = append(d.ReadOnlyProperties, "/properties/Name")
+			},
+			want:     []string{"read-only-added /properties/Name"},
+			breaking: true,
+		},
+		{
+			name: "made writable",
+			edit: func(d *model.Document) {
+				d.ReadOnlyProperties = nil
+			},
+			want:     []string{"read-only-removed /properties/Guid"},
+			breaking: true,
+		},
+		{
+			name: "primaryIdentifier change",
+			edit: func(d *model.Document) {
+				d.PrimaryIdentifier = []string{"/properties/Name"}
+			},
+			want:     []string{"primary-identifier-changed"},
+			breaking: true,
+		},
+		{
+			name: "update handler removed",
+			edit: func(d *model.Document) {
+				delete(d.Handlers, "update")
+			},
+			want:     []string{"update-removed"},
+			breaking: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := diff.Documents(document(t, nil), document(t, tt.edit))
+			if got := kinds(changes); strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if report := diff.NewReport("old", "new", changes); report.Breaking != tt.breaking {
+				t.Errorf("breaking %t, want %t", report.Breaking, tt.breaking)
+			}
+		})
+	}
+}
+
+func TestDocumentsUpdateAdded(t *testing.T) {
+	old := document(t, func(d *model.Document) { delete(d.Handlers, "update") })
+	changes := diff.Documents(old, document(t, nil))
+	if got := kinds(changes); len(got) != 1 || got[0] != diff.UpdateAdded || changes[0].Breaking {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	other := func(d *model.Document) { d.TypeName = "NewRelic::Observability::other" }
+	old := []*model.Document{document(t, nil), document(t, other)}
+	changed := document(t, func(d *model.Document) { delete(d.Properties, "Labels") })
+	added := document(t, func(d *model.Document) { d.TypeName = "NewRelic::Observability::added" })
+	changes := diff.Compare(old, []*model.Document{changed, added})
+	want := []string{"resource-added", "resource-removed", "property-removed /properties/Labels"}
+	if got := kinds(changes); strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestReportWrite(t *testing.T) {
+	changes := diff.Documents(document(t, nil), document(t, func(d *model.Document) {
+		delete(d.Properties, "Labels")
+		d.Properties["Description"] = &model.Property{Type: "string"}
+	}))
+	dir := t.TempDir()
+	if err := diff.NewReport("old.graphql", "new.graphql", changes).Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	markdown, err := os.ReadFile(filepath.Join(dir, "diff.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"NewRelic::Observability::thing", "`/properties/Labels`", "`property-removed`", "`/properties/Description`"} {
+		if !strings.Contains(string(markdown), want) {
+			t.Errorf("diff.md has no %s:\n%s", want, markdown)
+		}
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "diff.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := &diff.Report{}
+	if err = json.Unmarshal(b, report); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Breaking || report.Old != "old.graphql" || len(report.Changes) != 2 {
+		t.Errorf("diff.json %+v", report)
+	}
+}
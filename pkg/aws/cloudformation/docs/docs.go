@@ -0,0 +1,413 @@
+package docs
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+/*
+Reference documentation in the `cfn generate` docs style, Markdown and HTML
+
+- <project>/docs/README.md (index.html) for the resource: syntax in JSON and YAML, the properties with their type,
+  whether they're required, update behaviour and allowed values, and the Ref/Fn::GetAtt return values
+- <project>/docs/<definition>.md (.html) for each nested object definition, linked from where it's used
+- README.md (index.html) in the output directory listing every resource
+
+Descriptions are the schema's, otherwise the GraphQL ones.
+*/
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var (
+	funcs             = map[string]interface{}{"anchor": anchor, "last": last, "allowed": allowed, "updateLink": updateLink, "cell": cell}
+	markdownTemplates = template.Must(template.New("").Funcs(funcs).ParseFS(templates, "templates/*.md.tmpl"))
+	htmlTemplates     = htmltemplate.Must(htmltemplate.New("").Funcs(funcs).ParseFS(templates, "templates/*.html.tmpl"))
+)
+
+// Update behaviours, https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/using-cfn-updating-stacks-update-behaviors.html
+const (
+	NoInterruption = "No interruption"
+	Replacement    = "Replacement"
+)
+
+// format Markdown or HTML
+type format struct {
+	extension string
+	index     string // the resource page and the index page
+	render    func(w *bytes.Buffer, name string, data interface{}) error
+}
+
+var formats = []*format{
+	{extension: ".md", index: "README.md", render: func(w *bytes.Buffer, name string, data interface{}) error {
+		return markdownTemplates.ExecuteTemplate(w, name+".md.tmpl", data)
+	}},
+	{extension: ".html", index: "index.html", render: func(w *bytes.Buffer, name string, data interface{}) error {
+		return htmlTemplates.ExecuteTemplate(w, name+".html.tmpl", data)
+	}},
+}
+
+// Page a resource or definition
+type Page struct {
+	Title       string
+	Description string
+	TypeName    string // Resource pages only
+	Properties  []*Row
+	Ref         []string
+	Attributes  []*Row
+	Indent      string // Of the properties in the syntax blocks
+}
+
+// Row a property
+type Row struct {
+	Name        string
+	Description string
+	Type        *Type
+	Required    bool
+	Update      string
+	Allowed     []string
+	Pattern     string
+	Default     string
+}
+
+// Type what a property holds, Link is the page of an object definition
+type Type struct {
+	Name    string
+	Link    string
+	Array   bool
+	Members []*Type // Unions
+}
+
+// Entry a resource on the index page
+type Entry struct {
+	TypeName    string
+	Description string
+	Link        string
+}
+
+// Generate
+// write the resource's pages into dir/docs
+func Generate(doc *model.Document, dir string) error {
+	docsDir := filepath.Join(dir, "docs")
+	if err := os.MkdirAll(docsDir, 0755); err != nil {
+		return fmt.Errorf("docs: %w", err)
+	}
+	for _, f := range formats {
+		g := &generator{doc: doc, format: f}
+		if err := g.write(filepath.Join(docsDir, f.index), "page", g.resource()); err != nil {
+			return err
+		}
+		for _, name := range g.objectDefinitions() {
+			if err := g.write(filepath.Join(docsDir, g.page(name)), "page", g.definition(name)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Index
+// write dir/README.md and dir/index.html listing the resources, their pages are in dir/<base name>/docs
+func Index(docs []*model.Document, dir string) error {
+	sorted := append([]*model.Document{}, docs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TypeName < sorted[j].TypeName })
+	for _, f := range formats {
+		entries := make([]*Entry, 0, len(sorted))
+		for _, doc := range sorted {
+			entries = append(entries, &Entry{TypeName: doc.TypeName, Description: description(doc.Description, doc.GraphQLDescription), Link: doc.BaseName() + "/docs/" + f.index})
+		}
+		g := &generator{format: f}
+		if err := g.write(filepath.Join(dir, f.index), "index", entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type generator struct {
+	doc    *model.Document
+	format *format
+}
+
+func (g *generator) write(file string, name string, data interface{}) error {
+	var b bytes.Buffer
+	if err := g.format.render(&b, name, data); err != nil {
+		return fmt.Errorf("docs: %s: %w", file, err)
+	}
+	if err := os.WriteFile(file, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("docs: %w", err)
+	}
+	return nil
+}
+
+// resource
+// the resource page
+func (g *generator) resource() *Page {
+	p := &Page{Title: g.doc.TypeName, TypeName: g.doc.TypeName, Description: description(g.doc.Description, g.doc.GraphQLDescription), Indent: "        "}
+	readOnly := paths(g.doc.ReadOnlyProperties)
+	createOnly := paths(g.doc.CreateOnlyProperties)
+	for _, name := range sortedNames(g.doc.Properties) {
+		property := g.doc.Properties[name]
+		if readOnly[name] {
+			p.Attributes = append(p.Attributes, &Row{Name: name, Description: description(property.Description, property.GraphQLDescription)})
+			continue
+		}
+		update := NoInterruption
+		if createOnly[name] || g.doc.Handlers["update"] == nil {
+			update = Replacement
+		}
+		p.Properties = append(p.Properties, g.row(name, property, contains(g.doc.Required, name), update))
+	}
+	for _, path := range g.doc.PrimaryIdentifier {
+		p.Ref = append(p.Ref, strings.TrimPrefix(path, "/properties/"))
+	}
+	return p
+}
+
+// definition
+// a nested object's page
+func (g *generator) definition(name string) *Page {
+	def := g.doc.Definitions[name]
+	p := &Page{Title: name, Description: description(def.Description, def.GraphQLDescription), Indent: "    "}
+	update := Replacement
+	if g.mutable()[name] {
+		update = NoInterruption
+	}
+	for _, field := range sortedNames(def.Properties) {
+		p.Properties = append(p.Properties, g.row(field, def.Properties[field], contains(def.Required, field), update))
+	}
+	return p
+}
+
+func (g *generator) row(name string, property *model.Property, required bool, update string) *Row {
+	r := &Row{Name: name, Description: description(property.Description, property.GraphQLDescription), Type: g.typeOf(property), Required: required, Update: update}
+	def := g.resolve(property)
+	if property.Items != nil {
+		def = g.resolve(&model.Property{Ref: property.Items.Ref, Type: property.Items.Type})
+	}
+	r.Allowed = def.Enum
+	r.Pattern = property.Pattern
+	if r.Pattern == "" {
+		r.Pattern = def.Pattern
+	}
+	if property.Default != nil {
+		r.Default = fmt.Sprint(property.Default)
+	}
+	if r.Description == "" {
+		r.Description = description(def.Description, def.GraphQLDescription)
+	}
+	return r
+}
+
+// typeOf
+// the property's type as the docs show it: String, Integer, a definition, a list of them
+func (g *generator) typeOf(property *model.Property) *Type {
+	if property.Type == "array" && property.Items != nil {
+		t := g.typeOf(&model.Property{Type: property.Items.Type, Ref: property.Items.Ref, AnyOf: property.Items.AnyOf})
+		t.Array = true
+		return t
+	}
+	name := strings.TrimPrefix(property.Ref, "#/definitions/")
+	def := g.resolve(property)
+	members := append(append([]*model.Item{}, def.AnyOf...), def.OneOf...)
+	if len(members) > 0 {
+		t := &Type{Name: name}
+		for _, m := range members {
+			t.Members = append(t.Members, g.typeOf(&model.Property{Type: m.Type, Ref: m.Ref}))
+		}
+		return t
+	}
+	if isObject(def) {
+		return &Type{Name: name, Link: g.page(name)}
+	}
+	return &Type{Name: typeName(def.Type)}
+}
+
+// objectDefinitions
+// the definitions with a page, enums and scalars are shown where they're used. Definitions nothing refers to (e.g.
+// merged into another) don't get one
+func (g *generator) objectDefinitions() []string {
+	properties := make([]*model.Property, 0, len(g.doc.Properties))
+	for _, property := range g.doc.Properties {
+		properties = append(properties, property)
+	}
+	if g.doc.TypeConfiguration != nil {
+		for _, property := range g.doc.TypeConfiguration.Properties {
+			properties = append(properties, property)
+		}
+	}
+	reached := g.reachable(properties)
+	names := make([]string, 0, len(g.doc.Definitions))
+	for _, name := range sortedNames(g.doc.Definitions) {
+		if reached[name] && isObject(g.doc.Definitions[name]) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// mutable
+// the definitions reachable from properties update can change
+func (g *generator) mutable() map[string]bool {
+	if g.doc.Handlers["update"] == nil {
+		return make(map[string]bool)
+	}
+	createOnly := paths(g.doc.CreateOnlyProperties)
+	readOnly := paths(g.doc.ReadOnlyProperties)
+	properties := make([]*model.Property, 0, len(g.doc.Properties))
+	for name, property := range g.doc.Properties {
+		if !createOnly[name] && !readOnly[name] {
+			properties = append(properties, property)
+		}
+	}
+	return g.reachable(properties)
+}
+
+// reachable
+// the definitions the properties refer to, directly or through other definitions
+func (g *generator) reachable(properties []*model.Property) map[string]bool {
+	reached := make(map[string]bool)
+	var walk func(property *model.Property)
+	walk = func(property *model.Property) {
+		refs := []string{property.Ref}
+		if property.Items != nil {
+			refs = append(refs, property.Items.Ref)
+		}
+		for _, item := range append(append([]*model.Item{}, property.AnyOf...), property.OneOf...) {
+			refs = append(refs, item.Ref)
+		}
+		for _, ref := range refs {
+			name := strings.TrimPrefix(ref, "#/definitions/")
+			def := g.doc.Definitions[name]
+			if ref == "" || def == nil || reached[name] {
+				continue
+			}
+			reached[name] = true
+			walk(def)
+			for _, p := range def.Properties {
+				walk(p)
+			}
+		}
+	}
+	for _, property := range properties {
+		walk(property)
+	}
+	return reached
+}
+
+func (g *generator) resolve(property *model.Property) *model.Property {
+	if property.Ref == "" {
+		return property
+	}
+	if def := g.doc.Definitions[strings.TrimPrefix(property.Ref, "#/definitions/")]; def != nil {
+		return def
+	}
+	return property
+}
+
+// page
+// the definition's file, cfn names them in lowercase
+func (g *generator) page(name string) string {
+	return strings.ToLower(name) + g.format.extension
+}
+
+func isObject(def *model.Property) bool {
+	return len(def.Enum) == 0 && (len(def.Properties) > 0 || def.Type == "object") && len(def.AnyOf) == 0 && len(def.OneOf) == 0
+}
+
+// typeName
+// JSON schema type as cfn docs show it
+func typeName(t string) string {
+	if t == "" {
+		return "String"
+	}
+	return strings.ToUpper(t[:1]) + t[1:]
+}
+
+// description
+// the schema's description, the GraphQL one when there's none
+func description(schema string, graphQL string) string {
+	if schema != "" {
+		return schema
+	}
+	return strings.TrimSpace(graphQL)
+}
+
+// allowed
+// the enum values, pattern and default
+func allowed(r *Row) string {
+	parts := make([]string, 0, 3)
+	if len(r.Allowed) > 0 {
+		parts = append(parts, strings.Join(r.Allowed, ", "))
+	}
+	if r.Pattern != "" {
+		parts = append(parts, "Pattern: "+r.Pattern)
+	}
+	if r.Default != "" {
+		parts = append(parts, "Default: "+r.Default)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// updateLink
+// where the AWS docs explain the update behaviour
+func updateLink(update string) string {
+	const behaviors = "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/using-cfn-updating-stacks-update-behaviors.html"
+	if update == Replacement {
+		return behaviors + "#update-replacement"
+	}
+	return behaviors + "#update-no-interrupt"
+}
+
+// cell
+// a Markdown table cell, on one line with its pipes escaped
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "|", "\\|")
+}
+
+// anchor
+// the heading's id, as GitHub makes them
+func anchor(name string) string {
+	return strings.ToLower(name)
+}
+
+// last
+// i is the last index of the rows
+func last(i int, rows []*Row) bool {
+	return i == len(rows)-1
+}
+
+func paths(p []string) map[string]bool {
+	m := make(map[string]bool, len(p))
+	for _, path := range p {
+		m[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	return m
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedNames(properties map[string]*model.Property) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,132 @@
+package docs_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/docs"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// generate
+// the fixture's pages and index in a temporary directory, the fixture's documents by name
+func generate(t *testing.T) (string, map[string]*model.Document) {
+	t.Helper()
+	services, err := fixture.Services(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	documents := make(map[string]*model.Document)
+	list := make([]*model.Document, 0, len(services))
+	for name, service := range services {
+		doc := service.Document()
+		if err = docs.Generate(doc, filepath.Join(dir, doc.BaseName())); err != nil {
+			t.Fatal(err)
+		}
+		documents[name] = doc
+		list = append(list, doc)
+	}
+	if err = docs.Index(list, dir); err != nil {
+		t.Fatal(err)
+	}
+	return dir, documents
+}
+
+func TestGenerateGolden(t *testing.T) {
+	dir, documents := generate(t)
+	for file, golden := range map[string]string{
+		filepath.Join(documents["aiNotificationsChannel"].BaseName(), "docs", "README.md"): "testdata/channel.md",
+		"README.md": "testdata/index.md",
+	} {
+		fixture.GoldenFile(t, golden, filepath.Join(dir, file))
+	}
+}
+
+func TestLinks(t *testing.T) {
+	// Every page a Markdown or HTML page links to is there
+	dir, _ := generate(t)
+	links := regexp.MustCompile(`(?:href="|\]\()([^"#):]+\.(?:md|html))`)
+	pages := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		pages++
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, match := range links.FindAllStringSubmatch(string(b), -1) {
+			if filepath.Ext(match[1]) != filepath.Ext(path) {
+				t.Errorf("%s links to %s", path, match[1])
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(path), match[1])); err != nil {
+				t.Errorf("%s: %v", path, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages == 0 {
+		t.Error("no pages")
+	}
+}
+
+func TestUpdateBehaviour(t *testing.T) {
+	const schema = `{
+   "typeName": "NewRelic::Observability::thing",
+   "properties": {"Name": {"type": "string", "description": "<b>name</b> | label"}, "Region": {"type": "string"}},
+   "createOnlyProperties": ["/properties/Region"],
+   "handlers": {%s}
+}`
+	tests := []struct {
+		name     string
+		handlers string
+		want     []string
+	}{
+		{
+			name:     "update handler",
+			handlers: `"update": {"permissions": []}`,
+			want:     []string{docs.NoInterruption, docs.Replacement},
+		},
+		{
+			// Without one any change replaces the resource
+			name: "no update handler",
+			want: []string{docs.Replacement, docs.Replacement},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &model.Document{}
+			if err := json.Unmarshal([]byte(strings.Replace(schema, "%s", tt.handlers, 1)), doc); err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			if err := docs.Generate(doc, dir); err != nil {
+				t.Fatal(err)
+			}
+			markdown, _ := os.ReadFile(filepath.Join(dir, "docs", "README.md"))
+			for i, name := range []string{"Name", "Region"} {
+				row := regexp.MustCompile(`(?m)^\| <a id="` + strings.ToLower(name) + `"></a>` + name + ` .*$`).FindString(string(markdown))
+				if !strings.Contains(row, "["+tt.want[i]+"]") {
+					t.Errorf("%s: got %q, want %s", name, row, tt.want[i])
+				}
+			}
+			// Descriptions can't break the table or the HTML
+			if !strings.Contains(string(markdown), `<b>name</b> \| label`) {
+				t.Errorf("the Markdown description isn't escaped:\n%s", markdown)
+			}
+			page, _ := os.ReadFile(filepath.Join(dir, "docs", "index.html"))
+			if strings.Contains(string(page), "<b>name</b>") {
+				t.Errorf("the HTML description isn't escaped:\n%s", page)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package example
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/inputs"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Example CloudFormation templates declaring the resource, for the registry docs and smoke tests
+
+- Every required property is set, with example values from the contract test inputs generator
+- Optional properties are there commented out in YAML; JSON has no comments, they're under the resource's
+  Metadata.OptionalProperties instead
+- Outputs for each readOnlyProperty through Fn::GetAtt
+*/
+
+// seed the example values are generated with, fixed so the examples don't change between runs
+const seed = 1
+
+// Generate
+// write dir/example_inputs/<base name>.yaml and .json
+func Generate(doc *model.Document, dir string) error {
+	exampleDir := filepath.Join(dir, "example_inputs")
+	if err := os.MkdirAll(exampleDir, 0755); err != nil {
+		return fmt.Errorf("example: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(exampleDir, doc.BaseName()+".yaml"), YAML(doc), 0644); err != nil {
+		return fmt.Errorf("example: %w", err)
+	}
+	b, err := JSON(doc)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(exampleDir, doc.BaseName()+".json"), b, 0644); err != nil {
+		return fmt.Errorf("example: %w", err)
+	}
+	return nil
+}
+
+// example
+// the template's parts, shared by YAML and JSON
+type example struct {
+	logicalId string
+	required  []string
+	optional  []string
+	readOnly  []string
+	values    map[string]interface{}
+}
+
+func newExample(doc *model.Document) *example {
+	e := &example{logicalId: LogicalId(doc), values: make(map[string]interface{})}
+	readOnly := make(map[string]bool)
+	for _, path := range doc.ReadOnlyProperties {
+		name := strings.TrimPrefix(path, "/properties/")
+		readOnly[name] = true
+		e.readOnly = append(e.readOnly, name)
+	}
+	required := make(map[string]bool)
+	for _, name := range doc.Required {
+		required[name] = true
+	}
+
+	g := inputs.New(doc, seed)
+	names := make([]string, 0, len(doc.Properties))
+	for name := range doc.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if readOnly[name] {
+			continue
+		}
+		if required[name] {
+			e.required = append(e.required, name)
+		} else {
+			e.optional = append(e.optional, name)
+		}
+		e.values[name] = g.Value(name)
+	}
+	return e
+}
+
+// LogicalId
+// the resource's name in the template, the last part of TypeName: NewRelic::Observability::aiNotificationsChannel
+// -> AiNotificationsChannel
+func LogicalId(doc *model.Document) string {
+	parts := strings.Split(doc.TypeName, "::")
+	name := parts[len(parts)-1]
+	if name == "" {
+		return "Resource"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// YAML
+// the template, optional properties commented out
+func YAML(doc *model.Document) []byte {
+	e := newExample(doc)
+	var b strings.Builder
+	b.WriteString("AWSTemplateFormatVersion: \"2010-09-09\"\n")
+	b.WriteString(fmt.Sprintf("Description: %s\n", scalar(fmt.Sprintf("Example %s", doc.TypeName))))
+	b.WriteString("Resources:\n")
+	b.WriteString(fmt.Sprintf("  %s:\n", e.logicalId))
+	b.WriteString(fmt.Sprintf("    Type: %s\n", doc.TypeName))
+	if len(e.required) > 0 {
+		b.WriteString("    Properties:\n")
+	} else if len(e.optional) > 0 {
+		b.WriteString("    # Properties:\n")
+	}
+	for _, name := range e.required {
+		writeProperty(&b, doc.Properties[name], name, e.values[name], "")
+	}
+	for _, name := range e.optional {
+		writeProperty(&b, doc.Properties[name], name, e.values[name], "# ")
+	}
+	if len(e.readOnly) > 0 {
+		b.WriteString("Outputs:\n")
+		for _, name := range e.readOnly {
+			b.WriteString(fmt.Sprintf("  %s:\n", name))
+			if description := doc.Properties[name].Description; description != "" {
+				b.WriteString(fmt.Sprintf("    Description: %s\n", scalar(description)))
+			}
+			b.WriteString(fmt.Sprintf("    Value: !GetAtt %s.%s\n", e.logicalId, name))
+		}
+	}
+	return []byte(b.String())
+}
+
+// writeProperty
+// a property of the resource, prefix comments it out
+func writeProperty(b *strings.Builder, property *model.Property, name string, value interface{}, prefix string) {
+	const indent = "      "
+	if property.Description != "" {
+		b.WriteString(indent + "# " + strings.ReplaceAll(property.Description, "\n", " ") + "\n")
+	}
+	for _, line := range yamlLines(name, value) {
+		b.WriteString(indent + prefix + line + "\n")
+	}
+}
+
+// yamlLines
+// key: value as block YAML, nested two spaces at a time
+func yamlLines(key string, value interface{}) []string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return []string{key + ": {}"}
+		}
+		lines := []string{key + ":"}
+		for _, k := range sortedKeys(v) {
+			for _, line := range yamlLines(k, v[k]) {
+				lines = append(lines, "  "+line)
+			}
+		}
+		return lines
+	case []interface{}:
+		if len(v) == 0 {
+			return []string{key + ": []"}
+		}
+		lines := []string{key + ":"}
+		for _, e := range v {
+			for i, line := range itemLines(e) {
+				if i == 0 {
+					lines = append(lines, "  - "+line)
+				} else {
+					lines = append(lines, "    "+line)
+				}
+			}
+		}
+		return lines
+	}
+	return []string{key + ": " + scalar(value)}
+}
+
+// itemLines
+// a list item, objects start on the dash line
+func itemLines(value interface{}) []string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return []string{"{}"}
+		}
+		lines := make([]string, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			lines = append(lines, yamlLines(k, v[k])...)
+		}
+		return lines
+	case []interface{}:
+		b, _ := json.Marshal(v)
+		return []string{string(b)}
+	}
+	return []string{scalar(value)}
+}
+
+// scalar
+// plain when YAML reads it back as the same string, quoted otherwise
+func scalar(value interface{}) string {
+	s, ok := value.(string)
+	if !ok {
+		if value == nil {
+			return "null"
+		}
+		return fmt.Sprint(value)
+	}
+	switch strings.ToLower(s) {
+	case "", "true", "false", "yes", "no", "on", "off", "null", "~", "y", "n":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	if strings.ContainsAny(s[:1], "!&*-?{}[],#|>@`\"'%: ") || strings.ContainsAny(s, "#\n\t\"\\") || strings.Contains(s, ": ") || strings.HasSuffix(s, ":") || strings.HasSuffix(s, " ") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// JSON
+// the template, optional properties under the resource's Metadata.OptionalProperties
+func JSON(doc *model.Document) ([]byte, error) {
+	e := newExample(doc)
+	properties := make(map[string]interface{})
+	for _, name := range e.required {
+		properties[name] = e.values[name]
+	}
+	resource := map[string]interface{}{"Type": doc.TypeName}
+	if len(properties) > 0 {
+		resource["Properties"] = properties
+	}
+	if len(e.optional) > 0 {
+		optional := make(map[string]interface{})
+		for _, name := range e.optional {
+			optional[name] = e.values[name]
+		}
+		resource["Metadata"] = map[string]interface{}{"OptionalProperties": optional}
+	}
+	template := map[string]interface{}{
+		"AWSTemplateFormatVersion": "2010-09-09",
+		"Description":              fmt.Sprintf("Example %s", doc.TypeName),
+		"Resources":                map[string]interface{}{e.logicalId: resource},
+	}
+	if len(e.readOnly) > 0 {
+		outputs := make(map[string]interface{})
+		for _, name := range e.readOnly {
+			output := map[string]interface{}{"Value": map[string]interface{}{"Fn::GetAtt": []string{e.logicalId, name}}}
+			if description := doc.Properties[name].Description; description != "" {
+				output["Description"] = description
+			}
+			outputs[name] = output
+		}
+		template["Outputs"] = outputs
+	}
+	b, err := json.MarshalIndent(template, "", "   ")
+	if err != nil {
+		return nil, fmt.Errorf("example: %w", err)
+	}
+	return append(b, '\n'), nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
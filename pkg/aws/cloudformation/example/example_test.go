@@ -0,0 +1,60 @@
+package example_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/example"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/validate"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range []string{"aiNotificationsChannel", "aiNotificationsDestination"} {
+		t.Run(name, func(t *testing.T) {
+			service, err := fixture.Service(name, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc := service.Document()
+			dir := t.TempDir()
+			if err = example.Generate(doc, dir); err != nil {
+				t.Fatal(err)
+			}
+			v := validate.New([]*model.Document{doc})
+			for _, ext := range []string{".yaml", ".json"} {
+				file := filepath.Join(dir, "example_inputs", doc.BaseName()+ext)
+				got, err := os.ReadFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fixture.Golden(t, filepath.Join("testdata", doc.BaseName()+ext), got)
+				// The example is a valid template for the type
+				errs, err := v.Validate(file, got)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, e := range errs {
+					t.Errorf("%s", e)
+				}
+			}
+		})
+	}
+}
+
+func TestLogicalId(t *testing.T) {
+	tests := []struct {
+		typeName string
+		want     string
+	}{
+		{"NewRelic::Observability::aiNotificationsChannel", "AiNotificationsChannel"},
+		{"NewRelic::Observability::Dashboard", "Dashboard"},
+		{"NewRelic::Observability::", "Resource"},
+	}
+	for _, tt := range tests {
+		if got := example.LogicalId(&model.Document{TypeName: tt.typeName}); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.typeName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package gomodel
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+/*
+Generate the Go resource model (cmd/resource/model.go) straight from a Document, standing in for `cfn generate`.
+
+- Optional values are pointers, required ones aren't
+- json tags are the CloudFormation property names
+- graphql tags are the original names: mutation.argument paths for the Model, field names for definitions
+- Enums are string types with a constant per value
+- Unions are wrappers with a pointer per member type
+
+The project's go.mod is written too, pinning the plugin version the handlers and config.go need (UnmarshalTypeConfig
+arrived in v1.2.0). `go mod tidy` fills in go.sum.
+*/
+
+const header = "// Code generated by gqlparser from the CloudFormation resource schema. DO NOT EDIT.\n\n"
+
+// Requirements the generated project's modules and versions
+var Requirements = [][2]string{
+	{"github.com/aws-cloudformation/cloudformation-cli-go-plugin", "v1.2.0"},
+	{"github.com/aws/aws-lambda-go", "v1.55.1"},
+	{"github.com/aws/aws-sdk-go", "v1.55.8"},
+}
+
+// ModulePath
+// the generated project's module, its directory name
+func ModulePath(doc *model.Document) string {
+	return doc.BaseName()
+}
+
+// Generate
+// write go.mod into dir, and model.go, and config.go when the resource has a type configuration, into dir/cmd/resource
+func Generate(doc *model.Document, dir string) error {
+	resourceDir := filepath.Join(dir, "cmd", "resource")
+	if err := os.MkdirAll(resourceDir, 0755); err != nil {
+		return fmt.Errorf("gomodel: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), GoMod(ModulePath(doc)), 0644); err != nil {
+		return fmt.Errorf("gomodel: %w", err)
+	}
+
+	source, err := Source(doc)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(filepath.Join(resourceDir, "model.go"), source, 0644); err != nil {
+		return fmt.Errorf("gomodel: %w", err)
+	}
+
+	if doc.TypeConfiguration == nil {
+		return nil
+	}
+	config, err := format.Source([]byte(header + configSource))
+	if err != nil {
+		return fmt.Errorf("gomodel: config.go: %w", err)
+	}
+	return os.WriteFile(filepath.Join(resourceDir, "config.go"), config, 0644)
+}
+
+// Source
+// the formatted model.go, type checked so a broken model never gets written
+func Source(doc *model.Document) ([]byte, error) {
+	g := &generator{doc: doc}
+	g.writeStruct("Model", doc.TypeName, doc.Properties, doc.Required, true)
+	if doc.TypeConfiguration != nil {
+		g.writeStruct("TypeConfiguration", "set with `aws cloudformation set-type-configuration`", doc.TypeConfiguration.Properties, doc.TypeConfiguration.Required, false)
+	}
+
+	names := make([]string, 0, len(doc.Definitions))
+	for name := range doc.Definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def := doc.Definitions[name]
+		switch {
+		case len(def.Enum) > 0:
+			g.writeEnum(name, def)
+		case len(def.AnyOf) > 0 || len(def.OneOf) > 0:
+			g.writeUnion(name, def)
+		case isPrimitive(def):
+			// Inlined where it's used
+		default:
+			g.writeStruct(name, def.Description, def.Properties, def.Required, false)
+		}
+	}
+
+	file := header + "package resource\n\n"
+	// Only the union wrappers need imports
+	if g.unions {
+		file += "import (\n\"bytes\"\n\"encoding/json\"\n\"fmt\"\n)\n\n"
+	}
+	source, err := format.Source([]byte(file + g.String()))
+	if err != nil {
+		return nil, fmt.Errorf("gomodel: %s: %w", doc.TypeName, err)
+	}
+	if err = typeCheck(source); err != nil {
+		return nil, fmt.Errorf("gomodel: %s: %w", doc.TypeName, err)
+	}
+	return source, nil
+}
+
+// GoMod
+// the go.mod for a generated module requiring modules, all the Requirements when none are given
+func GoMod(path string, modules ...string) []byte {
+	var b strings.Builder
+	b.WriteString("module " + path + "\n\ngo 1.21\n\nrequire (\n")
+	for _, r := range Requirements {
+		if len(modules) == 0 || contains(modules, r[0]) {
+			b.WriteString("\t" + r[0] + " " + r[1] + "\n")
+		}
+	}
+	b.WriteString(")\n")
+	return []byte(b.String())
+}
+
+// typeCheck
+// the compile check, model.go only uses the standard library
+func typeCheck(source []byte) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "model.go", source, 0)
+	if err != nil {
+		return err
+	}
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = config.Check("resource", fset, []*ast.File{file}, nil)
+	return err
+}
+
+type generator struct {
+	strings.Builder
+	doc    *model.Document
+	unions bool
+}
+
+func (g *generator) writeStruct(name string, comment string, properties map[string]*model.Property, required []string, topLevel bool) {
+	if comment != "" {
+		g.WriteString(fmt.Sprintf("// %s %s\n", name, comment))
+	}
+	g.WriteString("type " + name + " struct {\n")
+	for _, propertyName := range sortedKeys(properties) {
+		property := properties[propertyName]
+		isRequired := contains(required, propertyName)
+		tag := fmt.Sprintf("json:\"%s\"", propertyName)
+		if !isRequired {
+			tag = fmt.Sprintf("json:\"%s,omitempty\"", propertyName)
+		}
+		graphql := property.OriginalName(propertyName)
+		if topLevel && len(property.ArgumentPaths) > 0 {
+			graphql = strings.Join(property.ArgumentPaths, ",")
+		}
+		tag += fmt.Sprintf(" graphql:\"%s\"", graphql)
+		g.WriteString(fmt.Sprintf("%s %s `%s`\n", identifier(propertyName), g.goType(property, isRequired), tag))
+	}
+	g.WriteString("}\n\n")
+}
+
+func (g *generator) writeEnum(name string, def *model.Property) {
+	g.WriteString(fmt.Sprintf("// %s enum\ntype %s string\n\nconst (\n", name, name))
+	for _, value := range def.Enum {
+		g.WriteString(fmt.Sprintf("%s%s %s = %q\n", name, identifier(camel(value)), name, value))
+	}
+	g.WriteString(")\n\n")
+}
+
+// writeUnion
+// a pointer per member, (un)marshals as whichever member is set / the first member the JSON fits
+func (g *generator) writeUnion(name string, def *model.Property) {
+	g.unions = true
+	members := make([]string, 0, len(def.AnyOf)+len(def.OneOf))
+	for _, item := range append(append([]*model.Item{}, def.AnyOf...), def.OneOf...) {
+		if ref, found := strings.CutPrefix(item.Ref, "#/definitions/"); found {
+			members = append(members, ref)
+		}
+	}
+	g.WriteString(fmt.Sprintf("// %s union, one of the members is set\ntype %s struct {\n", name, name))
+	for _, member := range members {
+		g.WriteString(fmt.Sprintf("%s *%s `json:\"-\"`\n", identifier(member), g.typeName(member)))
+	}
+	g.WriteString("}\n\n")
+
+	g.WriteString(fmt.Sprintf("func (u %s) MarshalJSON() ([]byte, error) {\n", name))
+	for _, member := range members {
+		g.WriteString(fmt.Sprintf("if u.%s != nil {\nreturn json.Marshal(u.%s)\n}\n", identifier(member), identifier(member)))
+	}
+	g.WriteString("return []byte(\"null\"), nil\n}\n\n")
+
+	g.WriteString(fmt.Sprintf("func (u *%s) UnmarshalJSON(b []byte) error {\n", name))
+	for _, member := range members {
+		g.WriteString(fmt.Sprintf("{\nv := new(%s)\nd := json.NewDecoder(bytes.NewReader(b))\nd.DisallowUnknownFields()\nif d.Decode(v) == nil {\nu.%s = v\nreturn nil\n}\n}\n", g.typeName(member), identifier(member)))
+	}
+	g.WriteString(fmt.Sprintf("return fmt.Errorf(\"%s: no member matches %%s\", string(b))\n}\n\n", name))
+}
+
+// goType
+// the field type, optional values are pointers, arrays and maps are nil-able as is
+func (g *generator) goType(property *model.Property, isRequired bool) string {
+	t := ""
+	switch {
+	case property.Ref != "":
+		t = g.typeName(strings.TrimPrefix(property.Ref, "#/definitions/"))
+	case property.Type == "array" && property.Items != nil:
+		return "[]" + g.itemType(property.Items)
+	case property.Type == "array":
+		return "[]interface{}"
+	case property.Type == "object":
+		return "map[string]interface{}"
+	default:
+		t = primitive(property.Type)
+	}
+	if isRequired || t == "interface{}" {
+		return t
+	}
+	return "*" + t
+}
+
+func (g *generator) itemType(item *model.Item) string {
+	if item.Ref != "" {
+		return g.typeName(strings.TrimPrefix(item.Ref, "#/definitions/"))
+	}
+	return primitive(item.Type)
+}
+
+// typeName
+// the definition's Go type, primitives (e.g. scalars) are inlined
+func (g *generator) typeName(name string) string {
+	def := g.doc.Definitions[name]
+	if def == nil {
+		return "interface{}"
+	}
+	if isPrimitive(def) {
+		return primitive(def.Type)
+	}
+	return name
+}
+
+func isPrimitive(def *model.Property) bool {
+	return len(def.Enum) == 0 && len(def.AnyOf) == 0 && len(def.OneOf) == 0 && len(def.Properties) == 0 && def.Type != "object"
+}
+
+func primitive(jsonType string) string {
+	switch jsonType {
+	case "string":
+		return "string"
+	case "integer":
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	}
+	return "interface{}"
+}
+
+// camel ENTITY_IN_USE -> EntityInUse
+func camel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '_' || r == '-' || r == ' ' || r == '.' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + strings.ToLower(part[1:]))
+	}
+	return b.String()
+}
+
+// identifier
+// an exported Go identifier
+func identifier(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			b.WriteRune(r)
+		}
+	}
+	id := b.String()
+	if id == "" || !unicode.IsLetter(rune(id[0])) {
+		id = "X" + id
+	}
+	return strings.ToUpper(id[:1]) + id[1:]
+}
+
+func sortedKeys(m map[string]*model.Property) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+const configSource = `package resource
+
+import (
+	"github.com/aws-cloudformation/cloudformation-cli-go-plugin/cfn/handler"
+)
+
+// Configuration the resource's type configuration
+func Configuration(req handler.Request) (*TypeConfiguration, error) {
+	config := &TypeConfiguration{}
+	if err := req.UnmarshalTypeConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+`
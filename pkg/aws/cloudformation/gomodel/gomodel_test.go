@@ -0,0 +1,106 @@
+package gomodel_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/gomodel"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	service, err := fixture.Service("aiNotificationsChannel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = gomodel.Generate(service.Document(), dir); err != nil {
+		t.Fatal(err)
+	}
+	fixture.GoldenFile(t, filepath.Join("testdata", "model.go.golden"), filepath.Join(dir, "cmd", "resource", "model.go"))
+	// The fixture has a type configuration
+	if _, err = os.Stat(filepath.Join(dir, "cmd", "resource", "config.go")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSource(t *testing.T) {
+	const target = `{
+   "typeName": "NewRelic::Observability::target",
+   "definitions": {
+      "Email": {"type": "object", "properties": {"Address": {"type": "string"}}, "required": ["Address"]},
+      "Slack": {"type": "object", "properties": {"Channel": {"type": "string"}}},
+      "Target": {"oneOf": [{"$ref": "#/definitions/Email"}, {"$ref": "#/definitions/Slack"}]},
+      "Level": {"type": "string", "enum": ["NOT_SET", "high"]},
+      "ID": {"type": "string"}
+   },
+   "properties": {
+      "Id": {"$ref": "#/definitions/ID"},
+      "Target": {"$ref": "#/definitions/Target"},
+      "Level": {"$ref": "#/definitions/Level"},
+      "Weight": {"type": "number"},
+      "Labels": {"type": "array", "items": {"type": "string"}},
+      "Extra": {"type": "object"}
+   },
+   "required": ["Level"],
+   "handlers": {}
+}`
+	doc := &model.Document{}
+	if err := json.Unmarshal([]byte(target), doc); err != nil {
+		t.Fatal(err)
+	}
+	source, err := gomodel.Source(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := make(map[string]bool)
+	for _, line := range strings.Split(string(source), "\n") {
+		lines[strings.Join(strings.Fields(line), " ")] = true
+	}
+	for _, want := range []string{
+		// Optional values are pointers, required ones, lists and maps aren't
+		"Level Level `json:\"Level\" graphql:\"level\"`",
+		"Weight *float64 `json:\"Weight,omitempty\" graphql:\"weight\"`",
+		"Labels []string `json:\"Labels,omitempty\" graphql:\"labels\"`",
+		"Extra map[string]interface{} `json:\"Extra,omitempty\" graphql:\"extra\"`",
+		// Scalars are inlined
+		"Id *string `json:\"Id,omitempty\" graphql:\"id\"`",
+		// A constant per enum value
+		"LevelNotSet Level = \"NOT_SET\"",
+		"LevelHigh Level = \"high\"",
+		// A pointer per union member
+		"type Target struct {",
+		"Email *Email `json:\"-\"`",
+		"Slack *Slack `json:\"-\"`",
+		"func (u Target) MarshalJSON() ([]byte, error) {",
+		"func (u *Target) UnmarshalJSON(b []byte) error {",
+		"\"bytes\"",
+	} {
+		if !lines[want] {
+			t.Errorf("no %q in:\n%s", want, source)
+		}
+	}
+	if strings.Contains(string(source), "type ID ") {
+		t.Errorf("the scalar ID has a type:\n%s", source)
+	}
+}
+
+func TestGoMod(t *testing.T) {
+	all := string(gomodel.GoMod("thing"))
+	for _, r := range gomodel.Requirements {
+		if !strings.Contains(all, "\t"+r[0]+" "+r[1]+"\n") {
+			t.Errorf("no %s %s:\n%s", r[0], r[1], all)
+		}
+	}
+	if !strings.HasPrefix(all, "module thing\n\ngo 1.21\n") {
+		t.Errorf("got\n%s", all)
+	}
+	// Only the modules asked for
+	lambda := string(gomodel.GoMod("thing", "github.com/aws/aws-lambda-go"))
+	if strings.Count(lambda, "\t") != 1 || !strings.Contains(lambda, "github.com/aws/aws-lambda-go") {
+		t.Errorf("got\n%s", lambda)
+	}
+}
@@ -0,0 +1,276 @@
+package guard
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+CloudFormation Guard (cfn-guard 2) rules for templates declaring a Service's resource type, checked before deploying
+without registering the type
+
+- <name>_required: the required properties, nested ones when their object is there
+- <name>_types: JSON types, enum membership and patterns, no readOnlyProperties. Intrinsic functions (Ref, Fn::*) are
+  structs, they pass
+- <name>_policy: the organizational policy from the config (nerdgraph.PolicyConfig), required tag keys and banned enum
+  values
+
+Objects and list items are checked in blocks when they're there. An intrinsic function standing in for a whole object
+is checked like a literal one, its nested required properties fail.
+*/
+
+// rule kinds
+const (
+	required = iota
+	types
+	policy
+)
+
+const indent = "    "
+
+// Generate
+// write dir/<base name>.guard
+func Generate(service *nerdgraph.Service, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("guard: %w", err)
+	}
+	doc := service.Document()
+	rules := Rules(doc, service.GetConfig().PolicyConfig(service.GetName()))
+	if err := os.WriteFile(filepath.Join(dir, doc.BaseName()+".guard"), []byte(rules), 0644); err != nil {
+		return fmt.Errorf("guard: %w", err)
+	}
+	return nil
+}
+
+// Name
+// the rules' prefix and the resources variable, the base name as an identifier
+func Name(doc *model.Document) string {
+	return strings.ReplaceAll(doc.BaseName(), "-", "_")
+}
+
+// Rules
+// the rule file for the document's type
+func Rules(doc *model.Document, p nerdgraph.PolicyConfig) string {
+	g := &generator{doc: doc, policy: p, visiting: make(map[string]bool)}
+	name := Name(doc)
+	var b strings.Builder
+	b.WriteString("#\n")
+	b.WriteString(fmt.Sprintf("# %s, generated by gqlparser from the NerdGraph schema. DO NOT EDIT.\n", doc.TypeName))
+	b.WriteString("#   cfn-guard validate --rules <this file> --data <template>\n")
+	b.WriteString("#\n\n")
+	b.WriteString(fmt.Sprintf("let %s = Resources.*[ Type == '%s' ]\n", name, doc.TypeName))
+
+	if len(doc.Required) > 0 {
+		body := []string{fmt.Sprintf("Properties exists <<%s requires Properties>>", doc.TypeName)}
+		body = append(body, block("Properties", g.clauses(doc.Properties, doc.Required, required))...)
+		writeRule(&b, name+"_required", name, body)
+	}
+	// Templates can't set what NerdGraph assigns
+	writable := make(map[string]*model.Property, len(doc.Properties))
+	readOnly := make([]string, 0, len(doc.ReadOnlyProperties))
+	for name, property := range doc.Properties {
+		writable[name] = property
+	}
+	for _, path := range doc.ReadOnlyProperties {
+		property := strings.TrimPrefix(path, "/properties/")
+		delete(writable, property)
+		readOnly = append(readOnly, fmt.Sprintf("%s !exists <<%s is read-only>>", property, property))
+	}
+	if body := append(sorted(readOnly), g.clauses(writable, nil, types)...); len(body) > 0 {
+		writeRule(&b, name+"_types", name, block("when Properties exists", block("Properties", body)))
+	}
+	body := g.tags()
+	body = append(body, block("when Properties exists", block("Properties", g.clauses(writable, nil, policy)))...)
+	if len(body) > 0 {
+		writeRule(&b, name+"_policy", name, body)
+	}
+	return b.String()
+}
+
+// writeRule
+// a rule over each of the type's resources, skipped when the template has none
+func writeRule(b *strings.Builder, rule string, variable string, body []string) {
+	b.WriteString(fmt.Sprintf("\nrule %s when %%%s !empty {\n", rule, variable))
+	for _, line := range block("%"+variable, body) {
+		b.WriteString(indent + line + "\n")
+	}
+	b.WriteString("}\n")
+}
+
+// block
+// <head> { body }, nothing when the body's empty
+func block(head string, body []string) []string {
+	if len(body) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(body)+2)
+	lines = append(lines, head+" {")
+	for _, line := range body {
+		lines = append(lines, indent+line)
+	}
+	return append(lines, "}")
+}
+
+type generator struct {
+	doc      *model.Document
+	policy   nerdgraph.PolicyConfig
+	visiting map[string]bool
+}
+
+// clauses
+// the kind's checks for an object's properties, relative to the object
+func (g *generator) clauses(properties map[string]*model.Property, requiredNames []string, kind int) []string {
+	lines := make([]string, 0)
+	if kind == required {
+		for _, name := range sorted(requiredNames) {
+			lines = append(lines, fmt.Sprintf("%s exists <<%s is required>>", name, name))
+		}
+	}
+	for _, name := range sortedKeys(properties) {
+		property := properties[name]
+		body := append(g.checks(property, name, kind), g.nested(property, name, kind)...)
+		if kind == required {
+			lines = append(lines, body...)
+		} else {
+			lines = append(lines, block("when "+name+" exists", body)...)
+		}
+	}
+	return lines
+}
+
+// checks
+// the property's own type, enum and pattern, or banned values
+func (g *generator) checks(property *model.Property, path string, kind int) []string {
+	def, name := g.resolve(property.Ref)
+	if def == nil {
+		def = property
+	}
+	switch kind {
+	case types:
+		switch {
+		case len(def.Enum) > 0:
+			return literal(path, fmt.Sprintf("%s IN [%s]", path, quoted(def.Enum)))
+		case def.Pattern != "" || property.Pattern != "":
+			pattern := property.Pattern
+			if pattern == "" {
+				pattern = def.Pattern
+			}
+			return literal(path, fmt.Sprintf("%s == /%s/", path, strings.ReplaceAll(pattern, "/", "\\/")))
+		}
+		if property.Type == "array" {
+			return literal(path, path+" is_list")
+		}
+		switch def.Type {
+		case "string":
+			return literal(path, path+" is_string")
+		case "integer":
+			return literal(path, path+" is_int")
+		case "number":
+			return literal(path, path+" is_int or", path+" is_float")
+		case "boolean":
+			return literal(path, path+" is_bool")
+		}
+		if len(def.Properties) > 0 {
+			return []string{path + " is_struct"}
+		}
+	case policy:
+		lines := make([]string, 0)
+		for _, value := range g.policy.BannedValues[name] {
+			lines = append(lines, fmt.Sprintf("%s != '%s' <<%s %s is banned by policy>>", path, value, name, value))
+		}
+		return lines
+	}
+	return nil
+}
+
+// nested
+// an object's properties in a block, or each list item's
+func (g *generator) nested(property *model.Property, path string, kind int) []string {
+	if property.Type == "array" {
+		if property.Items == nil {
+			return nil
+		}
+		item := &model.Property{Type: property.Items.Type, Ref: property.Items.Ref}
+		// A list of scalars checks each one with [*]
+		body := append(g.checks(item, path+"[*]", kind), block(path+"[*]", g.object(item, kind))...)
+		return block("when "+path+" is_list", block("when "+path+" !empty", body))
+	}
+	return block("when "+path+" is_struct", block(path, g.object(property, kind)))
+}
+
+// object
+// the clauses for the definition's properties, nothing for a scalar or a definition already being visited
+func (g *generator) object(property *model.Property, kind int) []string {
+	def, name := g.resolve(property.Ref)
+	if def == nil || len(def.Properties) == 0 || g.visiting[name] {
+		return nil
+	}
+	g.visiting[name] = true
+	defer delete(g.visiting, name)
+	return g.clauses(def.Properties, def.Required, kind)
+}
+
+// tags
+// every required tag key is there
+func (g *generator) tags() []string {
+	if len(g.policy.RequiredTags) == 0 || g.doc.Tagging == nil || !g.doc.Tagging.Taggable {
+		return nil
+	}
+	tagProperty := strings.TrimPrefix(g.doc.Tagging.TagProperty, "/properties/")
+	lines := []string{fmt.Sprintf("Properties.%s exists <<%s requires tags by policy>>", tagProperty, g.doc.TypeName)}
+	for _, key := range g.policy.RequiredTags {
+		lines = append(lines, fmt.Sprintf("some Properties.%s[*].Key == '%s' <<tag %s is required by policy>>", tagProperty, key, key))
+	}
+	return lines
+}
+
+// resolve
+// the referenced definition and its name
+func (g *generator) resolve(ref string) (*model.Property, string) {
+	if ref == "" {
+		return nil, ""
+	}
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	return g.doc.Definitions[name], name
+}
+
+// literal
+// the check as a disjunction with is_struct, intrinsic functions pass
+func literal(path string, clauses ...string) []string {
+	lines := make([]string, 0, len(clauses)+1)
+	for i, clause := range clauses {
+		if i == len(clauses)-1 {
+			clause += " or"
+		}
+		lines = append(lines, clause)
+	}
+	return append(lines, path+" is_struct")
+}
+
+func quoted(values []string) string {
+	q := make([]string, 0, len(values))
+	for _, v := range values {
+		q = append(q, "'"+strings.ReplaceAll(v, "'", "\\'")+"'")
+	}
+	return strings.Join(q, ", ")
+}
+
+func sorted(list []string) []string {
+	s := append([]string{}, list...)
+	sort.Strings(s)
+	return s
+}
+
+func sortedKeys(m map[string]*model.Property) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,194 @@
+package guard_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/guard"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// policy
+// a config with organizational rules, a required tag key and a banned channel type
+func policy() *nerdgraph.Config {
+	config := nerdgraph.NewConfig()
+	config.Policy = nerdgraph.PolicyConfig{
+		RequiredTags: []string{"team"},
+		BannedValues: map[string][]string{"AiNotificationsChannelType": {"WEBHOOK"}},
+	}
+	return config
+}
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range []string{"aiNotificationsChannel", "aiNotificationsDestination"} {
+		t.Run(name, func(t *testing.T) {
+			service, err := fixture.Service(name, policy())
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			if err = guard.Generate(service, dir); err != nil {
+				t.Fatal(err)
+			}
+			file := service.Document().BaseName() + ".guard"
+			fixture.GoldenFile(t, filepath.Join("testdata", file), filepath.Join(dir, file))
+		})
+	}
+}
+
+const thing = `{
+   "typeName": "NewRelic::Observability::thing",
+   "definitions": {
+      "Kind": {"type": "string", "enum": ["A", "B"]},
+      "Condition": {"type": "object", "properties": {"Name": {"type": "string"}, "Not": {"$ref": "#/definitions/Condition"}}, "required": ["Name"]}
+   },
+   "properties": {
+      "Guid": {"type": "string"},
+      "Name": {"type": "string", "pattern": "^a/b$"},
+      "Kind": {"$ref": "#/definitions/Kind"},
+      "Condition": {"$ref": "#/definitions/Condition"},
+      "Conditions": {"type": "array", "items": {"$ref": "#/definitions/Condition"}},
+      "Size": {"type": "number"}
+   },
+   "required": %s,
+   "readOnlyProperties": ["/properties/Guid"],
+   "primaryIdentifier": ["/properties/Guid"],
+   "handlers": {}
+}`
+
+// rules
+// the rules for thing with the required properties
+func rules(t *testing.T, required string, p nerdgraph.PolicyConfig) string {
+	t.Helper()
+	doc := &model.Document{}
+	if err := json.Unmarshal([]byte(strings.Replace(thing, "%s", required, 1)), doc); err != nil {
+		t.Fatal(err)
+	}
+	return guard.Rules(doc, p)
+}
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		required string
+		policy   nerdgraph.PolicyConfig
+		want     []string
+		not      []string
+	}{
+		{
+			name:     "required",
+			required: `["Condition"]`,
+			want: []string{
+				"let newrelic_observability_thing = Resources.*[ Type == 'NewRelic::Observability::thing' ]",
+				"rule newrelic_observability_thing_required when %newrelic_observability_thing !empty {",
+				"Condition exists <<Condition is required>>",
+				// Only when the object is there
+				"when Condition is_struct {",
+			},
+		},
+		{
+			// No required rule, the types still apply
+			name:     "nothing required",
+			required: `[]`,
+			want:     []string{"rule newrelic_observability_thing_types when %newrelic_observability_thing !empty {"},
+			not:      []string{"_required", "_policy"},
+		},
+		{
+			name:     "types",
+			required: `[]`,
+			want: []string{
+				"Guid !exists <<Guid is read-only>>",
+				"Kind IN ['A', 'B'] or",
+				"Name == /^a\\/b$/ or",
+				"Size is_int or",
+				"Size is_float or",
+				// Intrinsic functions are structs
+				"Name is_struct",
+				"Conditions[*] is_struct",
+			},
+			not: []string{"when Guid exists"},
+		},
+		{
+			name:     "banned values",
+			required: `[]`,
+			policy:   nerdgraph.PolicyConfig{BannedValues: map[string][]string{"Kind": {"B"}}},
+			want: []string{
+				"rule newrelic_observability_thing_policy when %newrelic_observability_thing !empty {",
+				"Kind != 'B' <<Kind B is banned by policy>>",
+			},
+		},
+		{
+			// thing isn't taggable
+			name:     "required tags",
+			required: `[]`,
+			policy:   nerdgraph.PolicyConfig{RequiredTags: []string{"team"}},
+			not:      []string{"requires tags by policy"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(t, tt.required, tt.policy)
+			lines := make(map[string]bool)
+			for _, line := range strings.Split(got, "\n") {
+				lines[strings.TrimSpace(line)] = true
+			}
+			for _, want := range tt.want {
+				if !lines[want] {
+					t.Errorf("no %q in:\n%s", want, got)
+				}
+			}
+			for _, not := range tt.not {
+				if strings.Contains(got, not) {
+					t.Errorf("has %q:\n%s", not, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRulesRecursive(t *testing.T) {
+	// A recursive definition's properties are checked once, not forever: Not is only a struct
+	got := rules(t, `["Condition"]`, nerdgraph.PolicyConfig{})
+	if strings.Contains(got, "Not {") || strings.Count(got, "Not is_struct") != 2 {
+		t.Errorf("Not:\n%s", got)
+	}
+	// The braces balance
+	if strings.Count(got, "{") != strings.Count(got, "}") {
+		t.Errorf("unbalanced:\n%s", got)
+	}
+}
+
+func TestName(t *testing.T) {
+	doc := &model.Document{TypeName: "NewRelic::Observability::aiNotificationsChannel"}
+	if got := guard.Name(doc); got != "newrelic_observability_ainotificationschannel" {
+		t.Errorf("got %s", got)
+	}
+}
+
+func TestRequiredTags(t *testing.T) {
+	service, err := fixture.Entity("aiNotificationsChannel", policy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = guard.Generate(service, dir); err != nil {
+		t.Fatal(err)
+	}
+	file := service.Document().BaseName() + ".guard"
+	got, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"Properties.Tags exists <<NewRelic::Observability::aiNotificationsChannel requires tags by policy>>",
+		"some Properties.Tags[*].Key == 'team' <<tag team is required by policy>>",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("no %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/gomodel"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+	"bytes"
+	"embed"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+/*
+Generate the cloudformation-cli-go-plugin handlers (cmd/resource) for a Service. The handlers call the NerdGraph mutations
+grouped by the Service, and read/list through the query path down to the Service's entity type.
+
+They send the requests with pkg/nerdgraph/client and convert the model with pkg/nerdgraph/mapping, copied into the project's
+internal/nerdgraph, from the mapping.json embedded next to them. The resource Model, TypeConfiguration and the project's
+go.mod come from the gomodel generator.
+*/
+
+//go:embed templates/*.tmpl
+var templates embed.FS
+
+var handlerTemplates = template.Must(template.ParseFS(templates, "templates/*.tmpl"))
+
+type handlerData struct {
+	Name      string // Create | Read | Update | Delete | List
+	Var       string // create, read, ...
+	Comment   string
+	Supported bool
+	Document  string
+	Arguments []string // Variables taken from the model
+	// Mutations
+	Operation      string
+	EntityField    string
+	ErrorsField    string
+	TagDocument    string
+	TagOperation   string
+	TagErrorsField string
+	ReadFirst      bool // Delete reads first, a resource that's gone is NotFound
+	// Queries
+	Query           bool
+	Path            []string
+	EntitiesField   string
+	CursorArgument  string // "" unless the list is paginated
+	NextCursorField string
+	Filter          string // Variable holding the identifier
+}
+
+type resourceData struct {
+	TypeName          string
+	Endpoint          string
+	TypeConfiguration bool
+	Handlers          []*handlerData
+	ClientImport      string
+	MappingImport     string
+}
+
+// Generate
+// write the handlers and their mapping.json into dir/cmd/resource, and the runtime packages into dir/internal/nerdgraph
+func Generate(service *nerdgraph.Service, dir string) (err error) {
+	// The same documents as the .graphql operations, they're the contract with NerdGraph
+	documents, err := service.OperationDocuments()
+	if err != nil {
+		return fmt.Errorf("handlers: %w", err)
+	}
+	data := newResourceData(service, documents)
+	resourceDir := filepath.Join(dir, "cmd", "resource")
+	if err = os.MkdirAll(resourceDir, 0755); err != nil {
+		return fmt.Errorf("handlers: %w", err)
+	}
+	for _, name := range []string{"resource.go", "nerdgraph.go"} {
+		if err = write(filepath.Join(resourceDir, name), name+".tmpl", data); err != nil {
+			return err
+		}
+	}
+	if err = service.Mapping().Write(filepath.Join(resourceDir, "mapping.json")); err != nil {
+		return fmt.Errorf("handlers: %w", err)
+	}
+	if err = nerdgraph.EmitRuntime(dir); err != nil {
+		return fmt.Errorf("handlers: %w", err)
+	}
+	return
+}
+
+func write(fileName string, templateName string, data *resourceData) error {
+	var b bytes.Buffer
+	if err := handlerTemplates.ExecuteTemplate(&b, templateName, data); err != nil {
+		return fmt.Errorf("handlers: %s: %w", templateName, err)
+	}
+	// Formatting is also the syntax check
+	source, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("handlers: %s: %w", fileName, err)
+	}
+	return os.WriteFile(fileName, source, 0644)
+}
+
+func newResourceData(service *nerdgraph.Service, documents map[string]string) *resourceData {
+	doc := service.Document()
+	enabled, endpoint := service.GetConfig().TypeConfigurationConfig(service.GetName())
+	module := gomodel.ModulePath(doc)
+	data := &resourceData{
+		TypeName:          doc.TypeName,
+		Endpoint:          client.EndpointURL(endpoint),
+		TypeConfiguration: enabled,
+		Handlers:          make([]*handlerData, 0, 5),
+		ClientImport:      nerdgraph.RuntimeImport(module, "client"),
+		MappingImport:     nerdgraph.RuntimeImport(module, "mapping"),
+	}
+	data.Handlers = append(data.Handlers, mutationHandler(service, documents, nerdgraph.CreateOperation, nerdgraph.CreateTagsOperation, nerdgraph.TagAddMutation))
+	data.Handlers = append(data.Handlers, queryHandler(service, documents, nerdgraph.ReadOperation))
+	data.Handlers = append(data.Handlers, mutationHandler(service, documents, nerdgraph.UpdateOperation, nerdgraph.UpdateTagsOperation, nerdgraph.TagReplaceMutation))
+	data.Handlers = append(data.Handlers, mutationHandler(service, documents, nerdgraph.DeleteOperation, "", ""))
+	data.Handlers = append(data.Handlers, queryHandler(service, documents, nerdgraph.ListOperation))
+	return data
+}
+
+func mutationHandler(service *nerdgraph.Service, documents map[string]string, name string, tagOperation string, tagMutation string) *handlerData {
+	h := &handlerData{Name: upperFirst(name), Var: name}
+	field := service.GetOperation(name)
+	if field == nil {
+		h.Comment = "no NerdGraph mutation"
+		return h
+	}
+	h.Supported = true
+	h.Operation = field.Name
+	h.Comment = field.Name
+	h.Document = documents[name]
+	for _, arg := range field.Arguments {
+		h.Arguments = append(h.Arguments, arg.Name)
+	}
+	h.ReadFirst = name == nerdgraph.DeleteOperation && service.ListQuery() != nil
+	h.EntityField = service.EntityField(field)
+	h.ErrorsField = service.ErrorsField(field)
+	if tagDocument, ok := documents[tagOperation]; ok && service.Document().Tagging.Taggable {
+		h.TagDocument = tagDocument
+		h.TagOperation = tagMutation
+		h.TagErrorsField = service.ErrorsField(service.RootMutation(tagMutation))
+		h.Comment += ", then tags the entity"
+	}
+	return h
+}
+
+func queryHandler(service *nerdgraph.Service, documents map[string]string, name string) *handlerData {
+	h := &handlerData{Name: upperFirst(name), Var: name, Query: true}
+	q := service.ListQuery()
+	if q == nil {
+		h.Comment = "no NerdGraph query"
+		return h
+	}
+	h.Supported = true
+	// The handler sets the filter and cursor, the rest come from the properties of the same name
+	for _, v := range q.Variables() {
+		h.Arguments = append(h.Arguments, v.Name)
+	}
+	for _, f := range q.Fields {
+		h.Path = append(h.Path, f.Name)
+	}
+	h.EntitiesField = q.EntitiesField
+	h.NextCursorField = q.NextCursorField
+	if name == nerdgraph.ReadOperation && q.FilterArgument != "" {
+		h.Document = documents[nerdgraph.ReadOperation]
+		h.Filter = q.FilterField
+	} else {
+		h.Document = documents[nerdgraph.ListOperation]
+		if q.CursorArgument != "" && q.NextCursorField != "" {
+			h.CursorArgument = q.CursorArgument
+		}
+	}
+	h.Comment = "queries " + q.EntityType.Name + " through " + strings.Join(h.Path, ".")
+	return h
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/gomodel"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/handlers"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedProjectBuilds(t *testing.T) {
+	tests := []struct {
+		name    string
+		service string
+		fixture func(name string, config *nerdgraph.Config) (*nerdgraph.Service, error)
+	}{
+		{"channel", "aiNotificationsChannel", fixture.Service},
+		{"destination", "aiNotificationsDestination", fixture.Service},
+		// With the tagging mutations
+		{"channel entity", "aiNotificationsChannel", fixture.Entity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, err := tt.fixture(tt.service, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			if err = gomodel.Generate(service.Document(), dir); err != nil {
+				t.Fatal(err)
+			}
+			if err = handlers.Generate(service, dir); err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range []string{"go.mod", "cmd/resource/model.go", "cmd/resource/config.go", "cmd/resource/resource.go", "cmd/resource/nerdgraph.go", "cmd/resource/mapping.json", "internal/nerdgraph/client/client.go", "internal/nerdgraph/mapping/mapping.go"} {
+				if _, err = os.Stat(filepath.Join(dir, file)); err != nil {
+					t.Error(err)
+				}
+			}
+			goMod, _ := os.ReadFile(filepath.Join(dir, "go.mod"))
+			if !strings.Contains(string(goMod), "github.com/aws-cloudformation/cloudformation-cli-go-plugin v1.2.0") {
+				t.Errorf("go.mod doesn't pin the plugin:\n%s", goMod)
+			}
+			fixture.Build(t, dir)
+		})
+	}
+}
+
+func TestHandlersUseRuntime(t *testing.T) {
+	service, err := fixture.Entity("aiNotificationsChannel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = handlers.Generate(service, dir); err != nil {
+		t.Fatal(err)
+	}
+	module := gomodel.ModulePath(service.Document())
+	resource, _ := os.ReadFile(filepath.Join(dir, "cmd", "resource", "resource.go"))
+	nerdgraph, _ := os.ReadFile(filepath.Join(dir, "cmd", "resource", "nerdgraph.go"))
+	for _, want := range []string{
+		`"` + module + `/internal/nerdgraph/client"`,
+		`c.Mutate(ctx, createDocument, variables, "aiNotificationsCreateChannel", "channel", "errors")`,
+		`resourceMapping.Variables("aiNotificationsUpdateChannel", current, previous)`,
+		`c.Paginate(ctx, listDocument`,
+		`CursorArgument: "cursor"`,
+		`c.Mutate(ctx, createTagDocument, tagVariables, "taggingAddTagsToEntity", "", "errors")`,
+		// Deleting what's gone is NotFound, like the contract harness
+		"if event, err := Read(req, nil, read); err != nil || event.OperationStatus != handler.Success {",
+		// A created resource it can't identify fails
+		"if entity == nil || entity[resourceMapping.IdentifierField] == nil {",
+		`fmt.Errorf("Create: aiNotificationsCreateChannel returned no %s", resourceMapping.IdentifierField), cloudformation.HandlerErrorCodeGeneralServiceException`,
+	} {
+		if !strings.Contains(string(resource), want) {
+			t.Errorf("resource.go doesn't have %s", want)
+		}
+	}
+	// Read and List can't run the query without the arguments the primary identifier doesn't carry
+	if !strings.Contains(string(resource), `variables, err := queryVariables(current, []string{"accountId"})`) {
+		t.Error("resource.go doesn't check the query's arguments")
+	}
+	for _, want := range []string{`"` + module + `/internal/nerdgraph/mapping"`, "//go:embed mapping.json", "client.New(access)", "&client.Error{Code: client.InvalidRequest"} {
+		if !strings.Contains(string(nerdgraph), want) {
+			t.Errorf("nerdgraph.go doesn't have %s", want)
+		}
+	}
+	// No hand-rolled client
+	if strings.Contains(string(nerdgraph), "net/http") {
+		t.Error("nerdgraph.go imports net/http")
+	}
+}
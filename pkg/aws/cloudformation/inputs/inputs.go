@@ -0,0 +1,437 @@
+package inputs
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*
+Contract test inputs, what `cfn test` (and `gqlparser contract`) reads from inputs/
+
+- inputs_1_create.json the required properties, plus the optional top-level ones (e.g. Tags). Optional nested properties
+  are left out, a merged input definition can have fields only some of the mutations take
+- inputs_1_update.json the create input with its free-form strings changed. createOnlyProperties, enums, identifiers
+  and relationships stay the same
+- inputs_1_invalid.json the create input broken on purpose: an enum value that doesn't exist, otherwise a missing
+  required property, otherwise a value of the wrong type
+
+readOnlyProperties are never written. Values come from a seeded source, the same seed writes the same inputs
+*/
+
+const (
+	// maxDepth nesting of required object properties, recursive definitions stop here
+	maxDepth = 8
+	// invalidEnum isn't in any enum
+	invalidEnum = "NOT_A_VALID_VALUE"
+)
+
+// epoch the DateTime values are offsets from, fixed so they're reproducible
+var epoch = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Generator the inputs for a Document
+type Generator struct {
+	doc        *model.Document
+	rand       *rand.Rand
+	readOnly   map[string]bool
+	createOnly map[string]bool
+}
+
+func New(doc *model.Document, seed int64) *Generator {
+	g := &Generator{doc: doc, rand: rand.New(rand.NewSource(seed)), readOnly: make(map[string]bool), createOnly: make(map[string]bool)}
+	for _, path := range doc.ReadOnlyProperties {
+		g.readOnly[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	for _, path := range doc.CreateOnlyProperties {
+		g.createOnly[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	return g
+}
+
+// Generate
+// write dir/inputs/inputs_1_create.json, inputs_1_update.json (when there's an update handler) and inputs_1_invalid.json
+func Generate(doc *model.Document, dir string, seed int64) error {
+	g := New(doc, seed)
+	create := g.Create()
+	files := map[string]map[string]interface{}{
+		"inputs_1_create.json":  create,
+		"inputs_1_invalid.json": g.Invalid(create),
+	}
+	if doc.Handlers["update"] != nil {
+		files["inputs_1_update.json"] = g.Update(create)
+	}
+
+	inputsDir := filepath.Join(dir, "inputs")
+	if err := os.MkdirAll(inputsDir, 0755); err != nil {
+		return fmt.Errorf("inputs: %w", err)
+	}
+	for name, input := range files {
+		b, err := json.MarshalIndent(input, "", "   ")
+		if err != nil {
+			return fmt.Errorf("inputs: %s: %w", name, err)
+		}
+		if err = os.WriteFile(filepath.Join(inputsDir, name), append(b, '\n'), 0644); err != nil {
+			return fmt.Errorf("inputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// Create
+// a valid create input
+func (g *Generator) Create() map[string]interface{} {
+	required := make(map[string]bool)
+	for _, r := range g.doc.Required {
+		required[r] = true
+	}
+	input := make(map[string]interface{})
+	for _, name := range sortedNames(g.doc.Properties) {
+		if g.readOnly[name] {
+			continue
+		}
+		property := g.doc.Properties[name]
+		if !required[name] && (property.RelationshipRef != nil || !g.creates(property)) {
+			// Optional references need another resource to exist, and create may not take what update does
+			continue
+		}
+		if v := g.value(name, property, 0); v != nil {
+			input[name] = v
+		}
+	}
+	return input
+}
+
+// Update
+// create with its free-form strings changed
+func (g *Generator) Update(create map[string]interface{}) map[string]interface{} {
+	update := copyInput(create)
+	for _, name := range sortedNames(g.doc.Properties) {
+		if _, ok := update[name]; !ok || g.createOnly[name] {
+			continue
+		}
+		update[name] = g.change(g.doc.Properties[name], update[name], 0)
+	}
+	return update
+}
+
+// Invalid
+// create broken against the schema
+func (g *Generator) Invalid(create map[string]interface{}) map[string]interface{} {
+	invalid := copyInput(create)
+	for _, name := range sortedNames(g.doc.Properties) {
+		if v, ok := invalid[name]; ok && g.breakEnum(g.doc.Properties[name], v, invalid, name, 0) {
+			return invalid
+		}
+	}
+	if len(g.doc.Required) > 0 {
+		required := append([]string{}, g.doc.Required...)
+		sort.Strings(required)
+		delete(invalid, required[0])
+		return invalid
+	}
+	for _, name := range sortedNames(g.doc.Properties) {
+		if _, ok := invalid[name]; !ok {
+			continue
+		}
+		if g.resolve(g.doc.Properties[name]).Type == "string" {
+			invalid[name] = 0
+		} else {
+			invalid[name] = "not a " + g.resolve(g.doc.Properties[name]).Type
+		}
+		return invalid
+	}
+	// Nothing to break, an unknown property then, additionalProperties is always false
+	invalid["NotAProperty"] = true
+	return invalid
+}
+
+// Value
+// an example value for the top-level property, nil when there's no such property
+func (g *Generator) Value(name string) interface{} {
+	property := g.doc.Properties[name]
+	if property == nil {
+		return nil
+	}
+	return g.value(name, property, 0)
+}
+
+// creates
+// create's mutation takes the property, true when that isn't known (e.g. entity tags)
+func (g *Generator) creates(property *model.Property) bool {
+	handler := g.doc.Handlers["create"]
+	if handler == nil || len(handler.Operations) == 0 || len(property.ArgumentPaths) == 0 {
+		return true
+	}
+	for _, path := range property.ArgumentPaths {
+		mutation, _, _ := strings.Cut(path, ".")
+		for _, operation := range handler.Operations {
+			if operation == mutation {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// value
+// a valid value for the property, nil when there isn't one
+func (g *Generator) value(name string, property *model.Property, depth int) interface{} {
+	if depth > maxDepth {
+		return nil
+	}
+	if property.Type == "array" && property.Items != nil {
+		item := &model.Property{Type: property.Items.Type, Ref: property.Items.Ref, AnyOf: property.Items.AnyOf, Name: property.Name}
+		if v := g.value(singular(name), item, depth+1); v != nil {
+			return []interface{}{v}
+		}
+		return []interface{}{}
+	}
+	if property.Default != nil {
+		return property.Default
+	}
+
+	def := g.resolve(property)
+	if len(def.Enum) > 0 {
+		return def.Enum[g.rand.Intn(len(def.Enum))]
+	}
+	members := append(append([]*model.Item{}, def.AnyOf...), def.OneOf...)
+	if len(members) > 0 {
+		return g.value(name, &model.Property{Ref: members[0].Ref, Type: members[0].Type}, depth+1)
+	}
+	switch def.Type {
+	case "integer":
+		return g.rand.Intn(1000) + 1
+	case "number":
+		return float64(g.rand.Intn(100000)) / 100
+	case "boolean":
+		return g.rand.Intn(2) == 1
+	case "string":
+		return g.text(name, property, def)
+	}
+
+	// Objects, only what's required
+	object := make(map[string]interface{})
+	for _, r := range sortedStrings(def.Required) {
+		p := def.Properties[r]
+		if p == nil {
+			continue
+		}
+		if v := g.value(r, p, depth+1); v != nil {
+			object[r] = v
+		}
+	}
+	return object
+}
+
+// text
+// a string in the scalar's format, matching the pattern when there is one
+func (g *Generator) text(name string, property *model.Property, def *model.Property) string {
+	candidates := make([]string, 0, 4)
+	scalar := def.Name
+	if scalar == "" {
+		scalar = property.Name
+	}
+	lower := strings.ToLower(scalar)
+	switch {
+	case scalar == "ID" || property.RelationshipRef != nil || strings.HasSuffix(lower, "guid") || strings.HasSuffix(lower, "id"):
+		candidates = append(candidates, g.id())
+	case strings.Contains(lower, "datetime") || strings.Contains(lower, "timestamp"):
+		candidates = append(candidates, epoch.Add(time.Duration(g.rand.Intn(365*24))*time.Hour).Format(time.RFC3339))
+	case strings.Contains(lower, "date"):
+		candidates = append(candidates, epoch.AddDate(0, 0, g.rand.Intn(365)).Format("2006-01-02"))
+	case strings.Contains(lower, "epoch") || strings.Contains(lower, "millis"):
+		candidates = append(candidates, fmt.Sprint(epoch.Add(time.Duration(g.rand.Intn(365*24))*time.Hour).UnixMilli()))
+	case strings.Contains(lower, "url") || strings.Contains(lower, "uri"):
+		candidates = append(candidates, "https://example.com/"+g.word())
+	case strings.Contains(lower, "email"):
+		candidates = append(candidates, g.word()+"@example.com")
+	case strings.Contains(lower, "json"):
+		candidates = append(candidates, "{}")
+	}
+	candidates = append(candidates, strings.ToLower(name)+"-"+g.word(), g.word())
+
+	pattern := property.Pattern
+	if pattern == "" {
+		pattern = def.Pattern
+	}
+	if pattern == "" {
+		return candidates[0]
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return candidates[0]
+	}
+	for _, c := range candidates {
+		if re.MatchString(c) {
+			return c
+		}
+	}
+	// Nothing fits, the input will need editing
+	return candidates[0]
+}
+
+// change
+// a different value for the free-form strings in value, everything else stays
+func (g *Generator) change(property *model.Property, value interface{}, depth int) interface{} {
+	if depth > maxDepth {
+		return value
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		if property.Items == nil {
+			return v
+		}
+		item := &model.Property{Type: property.Items.Type, Ref: property.Items.Ref, Name: property.Name}
+		changed := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			changed = append(changed, g.change(item, e, depth+1))
+		}
+		return changed
+	case map[string]interface{}:
+		def := g.resolve(property)
+		changed := make(map[string]interface{}, len(v))
+		for _, k := range sortedKeys(v) {
+			if p := def.Properties[k]; p != nil {
+				changed[k] = g.change(p, v[k], depth+1)
+			} else {
+				changed[k] = v[k]
+			}
+		}
+		return changed
+	case string:
+		if !g.freeForm(property) {
+			return v
+		}
+		return v + "-updated"
+	}
+	return value
+}
+
+// freeForm
+// a plain String, nothing about it is constrained or refers to something else
+func (g *Generator) freeForm(property *model.Property) bool {
+	def := g.resolve(property)
+	if len(def.Enum) > 0 || property.RelationshipRef != nil || property.Default != nil || property.Pattern != "" || def.Pattern != "" {
+		return false
+	}
+	return def.Type == "string" && (def.Name == "String" || def.Name == "")
+}
+
+// breakEnum
+// replace the first enum value found in value, set through parent[key]
+func (g *Generator) breakEnum(property *model.Property, value interface{}, parent interface{}, key interface{}, depth int) bool {
+	if depth > maxDepth {
+		return false
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		if property.Items == nil {
+			return false
+		}
+		item := &model.Property{Type: property.Items.Type, Ref: property.Items.Ref}
+		for i, e := range v {
+			if g.breakEnum(item, e, v, i, depth+1) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		def := g.resolve(property)
+		for _, k := range sortedKeys(v) {
+			if p := def.Properties[k]; p != nil && g.breakEnum(p, v[k], v, k, depth+1) {
+				return true
+			}
+		}
+	case string:
+		if len(g.resolve(property).Enum) == 0 {
+			return false
+		}
+		switch p := parent.(type) {
+		case map[string]interface{}:
+			p[key.(string)] = invalidEnum
+		case []interface{}:
+			p[key.(int)] = invalidEnum
+		}
+		return true
+	}
+	return false
+}
+
+// resolve
+// the definition a $ref points to, the property itself otherwise
+func (g *Generator) resolve(property *model.Property) *model.Property {
+	if property.Ref == "" {
+		return property
+	}
+	if def := g.doc.Definitions[strings.TrimPrefix(property.Ref, "#/definitions/")]; def != nil {
+		return def
+	}
+	return property
+}
+
+const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+func (g *Generator) word() string {
+	b := make([]byte, 8)
+	for i := range b {
+		b[i] = letters[g.rand.Intn(len(letters))]
+	}
+	return string(b)
+}
+
+// id
+// base64 like NerdGraph's entity GUIDs
+func (g *Generator) id() string {
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 24)
+	for i := range b {
+		b[i] = alphabet[g.rand.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// singular
+// Tags -> Tag, for naming array items
+func singular(name string) string {
+	if len(name) > 1 && strings.HasSuffix(name, "s") {
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+func sortedNames(properties map[string]*model.Property) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedStrings(s []string) []string {
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func copyInput(m map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(m))
+	b, _ := json.Marshal(m)
+	_ = json.Unmarshal(b, &c)
+	return c
+}
@@ -0,0 +1,104 @@
+package inputs_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/inputs"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	for _, name := range []string{"aiNotificationsChannel", "aiNotificationsDestination"} {
+		t.Run(name, func(t *testing.T) {
+			service, err := fixture.Service(name, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dir := t.TempDir()
+			if err = inputs.Generate(service.Document(), dir, 1); err != nil {
+				t.Fatal(err)
+			}
+			// The destination has no update mutation, so no update input
+			fixture.GoldenDir(t, filepath.Join("testdata", name), filepath.Join(dir, "inputs"), "*.json")
+		})
+	}
+}
+
+func TestSeed(t *testing.T) {
+	service, err := fixture.Service("aiNotificationsChannel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := service.Document()
+	if a, b := inputs.New(doc, 7).Create(), inputs.New(doc, 7).Create(); !reflect.DeepEqual(a, b) {
+		t.Errorf("the same seed wrote %v and %v", a, b)
+	}
+	if a, b := inputs.New(doc, 7).Create(), inputs.New(doc, 8).Create(); reflect.DeepEqual(a, b) {
+		t.Errorf("seeds 7 and 8 both wrote %v", a)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		create string
+		want   string
+	}{
+		{
+			name:   "missing required property",
+			schema: `{"typeName": "NewRelic::Observability::thing", "properties": {"Name": {"type": "string"}, "Size": {"type": "integer"}}, "required": ["Size", "Name"], "handlers": {}}`,
+			create: `{"Name": "n", "Size": 1}`,
+			want:   `{"Size": 1}`,
+		},
+		{
+			name:   "wrong type",
+			schema: `{"typeName": "NewRelic::Observability::thing", "properties": {"Size": {"type": "integer"}}, "handlers": {}}`,
+			create: `{"Size": 1}`,
+			want:   `{"Size": "not a integer"}`,
+		},
+		{
+			name:   "unknown property",
+			schema: `{"typeName": "NewRelic::Observability::thing", "properties": {}, "handlers": {}}`,
+			create: `{}`,
+			want:   `{"NotAProperty": true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &model.Document{}
+			if err := json.Unmarshal([]byte(tt.schema), doc); err != nil {
+				t.Fatal(err)
+			}
+			create, want := make(map[string]interface{}), make(map[string]interface{})
+			if err := json.Unmarshal([]byte(tt.create), &create); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			got := inputs.New(doc, 1).Invalid(create)
+			if normalized := normalize(t, got); !reflect.DeepEqual(normalized, want) {
+				t.Errorf("got %v, want %v", normalized, want)
+			}
+		})
+	}
+}
+
+// normalize
+// the input as it round trips through JSON, numbers are float64
+func normalize(t *testing.T, v map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]interface{})
+	if err = json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
@@ -16,14 +16,21 @@ type Document struct {
    AdditionalProperties bool                   `json:"additionalProperties"`
    Required             []string               `json:"required"`
    ReadOnlyProperties   []string               `json:"readOnlyProperties"`
+   CreateOnlyProperties []string               `json:"createOnlyProperties,omitempty"`
+   WriteOnlyProperties  []string               `json:"writeOnlyProperties,omitempty"`
    PrimaryIdentifier    []string               `json:"primaryIdentifier"`
    Handlers             map[string]*Handler    `json:"handlers"`
-   Tagging              map[string]interface{} `json:"tagging"`
+   Tagging              *Tagging               `json:"tagging"`
+   TypeConfiguration    *TypeConfiguration     `json:"typeConfiguration,omitempty"`
+   GraphQLDescription   string                 `json:"-"` // The mutations' description, for docs
    knownTypes           map[string]interface{} `json:"-"`
 }
 
 type Handler struct {
-   Permissions []string `json:"permissions"`
+   Permissions      []string `json:"permissions"`
+   TimeoutInMinutes int      `json:"timeoutInMinutes,omitempty"` // 2 - 2160, CloudFormation's default is 120
+   // Below here are for housekeeping, not part of the schema.json
+   Operations []string `json:"-"` // NerdGraph operations that implement the handler, in call order
 }
 
 func NewDocument() (document *Document) {
@@ -38,25 +45,90 @@ func NewDocument() (document *Document) {
       ReadOnlyProperties:   make([]string, 0),
       PrimaryIdentifier:    make([]string, 0),
       Handlers:             make(map[string]*Handler),
-      Tagging:              make(map[string]interface{}),
+      Tagging:              NewTagging(),
       knownTypes:           make(map[string]interface{}),
    }
+   document.Properties["Guid"] = &Property{Type: "string", Description: "NerdGraph identifier", Properties: make(map[string]*Property), Name: "ID"}
    document.ReadOnlyProperties = append(document.ReadOnlyProperties, "/properties/Guid")
    document.PrimaryIdentifier = append(document.PrimaryIdentifier, "/properties/Guid")
-   handler := &Handler{}
-   permissions := []string{"cloudformation:BatchDescribeTypeConfigurations"}
-   handler.Permissions = permissions
-   document.Handlers["create"] = handler
-   document.Handlers["update"] = handler
-   document.Handlers["delete"] = handler
-   document.Handlers["read"] = handler
-   document.Handlers["list"] = handler
-   document.Tagging["taggable"] = true
-   document.Tagging["tagProperty"] = "#/definitions/EntityTag"
 
    return
 }
 
+func NewHandler(permissions []string, timeoutInMinutes int) *Handler {
+   if permissions == nil {
+      permissions = make([]string, 0)
+   }
+   return &Handler{
+      Permissions:      permissions,
+      TimeoutInMinutes: timeoutInMinutes,
+      Operations:       make([]string, 0),
+   }
+}
+
+// AddHandler
+// add handler to document, no handler means CloudFormation doesn't support the operation (e.g. no update == replace on update)
+func (d *Document) AddHandler(name string, handler *Handler) {
+   d.Handlers[name] = handler
+}
+
+// AddArgumentPath
+// record that mutation.argument feeds the top-level property
+func (d *Document) AddArgumentPath(argDefName string, path string) {
+   if property := d.Properties[uppercaseTypeName(argDefName)]; property != nil {
+      property.ArgumentPaths = append(property.ArgumentPaths, path)
+   }
+}
+
+// AddReadOnlyProperty
+// mark a top-level property as assigned by NerdGraph, it can't be required
+func (d *Document) AddReadOnlyProperty(name string) {
+   path := PropertyPath(name)
+   for _, p := range d.ReadOnlyProperties {
+      if p == path {
+         return
+      }
+   }
+   d.ReadOnlyProperties = append(d.ReadOnlyProperties, path)
+   required := make([]string, 0, len(d.Required))
+   for _, r := range d.Required {
+      if r != uppercaseTypeName(name) {
+         required = append(required, r)
+      }
+   }
+   d.Required = required
+}
+
+// AddWriteOnlyProperty
+// mark a top-level property as never returned by NerdGraph, e.g. a secret the mutation takes
+func (d *Document) AddWriteOnlyProperty(name string) {
+   path := PropertyPath(name)
+   for _, p := range d.WriteOnlyProperties {
+      if p == path {
+         return
+      }
+   }
+   d.WriteOnlyProperties = append(d.WriteOnlyProperties, path)
+}
+
+// AddCreateOnlyProperty
+// mark a top-level property as only settable on create, changing it replaces the resource
+func (d *Document) AddCreateOnlyProperty(name string) {
+   path := PropertyPath(name)
+   for _, p := range d.CreateOnlyProperties {
+      if p == path {
+         return
+      }
+   }
+   d.CreateOnlyProperties = append(d.CreateOnlyProperties, path)
+}
+
+// BaseName
+// lowercase TypeName, :: replaced with -, for file and directory names
+func (d *Document) BaseName() string {
+   return strings.ReplaceAll(strings.ToLower(d.TypeName), "::", "-")
+}
+
 // AddDefinition
 // add definition property to document
 func (d *Document) AddDefinition(astType *ast.Type, property *Property) (err error) {
@@ -79,6 +151,9 @@ func (d *Document) AddProperty(argDefName string, property *Property) (err error
       return fmt.Errorf("cannot add nil property to document")
    }
 
+   if property.GraphQLName == "" {
+      property.GraphQLName = argDefName
+   }
    existingProperty := d.Properties[uppercaseTypeName(argDefName)]
    if existingProperty == nil {
       d.Properties[uppercaseTypeName(argDefName)] = property
@@ -200,6 +275,8 @@ func (d *Document) SplunkTypeDefinitions(astType *ast.Type, gqlSchema *ast.Schem
          continue
       }
 
+      fieldTypeProperty.GraphQLName = field.Name
+      fieldTypeProperty.GraphQLDescription = field.Description
       property.Properties[uppercaseTypeName(field.Name)] = fieldTypeProperty // add property types to this larger property
 
       if field.Type.NonNull { // if it's required, add name to Required for this property
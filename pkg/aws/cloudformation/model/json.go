@@ -0,0 +1,149 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/vektah/gqlparser/v2/ast"
+	"os"
+	"strings"
+)
+
+/*
+Reading a resource schema back into a Document. The JSON only has the schema, the housekeeping fields the generator
+fills from the GraphQL AST are derived from it:
+
+- Name is the GraphQL type name: the definition's key, the referenced definition or the built-in scalar (String, Int,
+  Float, Boolean) for a JSON type
+- Kind is ENUM for an enum, UNION for anyOf/oneOf, INPUT_OBJECT for an object, SCALAR for any other definition and
+  empty for a built-in. References take the Kind of their definition
+- IsArray and IsRequired come from type array and the parent's required list
+
+GraphQLName, GraphQLDescription, ArgumentPaths and the handlers' Operations aren't in the schema, they stay empty.
+*/
+
+// ReadDocument
+// the resource schema in file
+func ReadDocument(file string) (*Document, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	d := &Document{}
+	if err = json.Unmarshal(b, d); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return d, nil
+}
+
+func (d *Document) UnmarshalJSON(b []byte) error {
+	// document has Document's fields but not its methods, so this doesn't recurse
+	type document Document
+	if err := json.Unmarshal(b, (*document)(d)); err != nil {
+		return err
+	}
+	if d.Definitions == nil {
+		d.Definitions = make(map[string]*Property)
+	}
+	if d.Properties == nil {
+		d.Properties = make(map[string]*Property)
+	}
+	if d.Handlers == nil {
+		d.Handlers = make(map[string]*Handler)
+	}
+	d.knownTypes = make(map[string]interface{})
+	for name, def := range d.Definitions {
+		def.Name = name
+		if def.Kind == "" && def.Type != "array" {
+			// A definition is a GraphQL type, a bare JSON type is a custom scalar
+			def.Kind = ast.Scalar
+		}
+		d.knownTypes[name] = nil
+	}
+	markRequired(d.Properties, d.Required)
+	for _, def := range d.Definitions {
+		d.resolveKinds(def)
+	}
+	for _, property := range d.Properties {
+		d.resolveKinds(property)
+	}
+	if d.TypeConfiguration != nil {
+		markRequired(d.TypeConfiguration.Properties, d.TypeConfiguration.Required)
+		for _, property := range d.TypeConfiguration.Properties {
+			d.resolveKinds(property)
+		}
+	}
+	return nil
+}
+
+// resolveKinds
+// references, and arrays of them, take their definition's Kind
+func (d *Document) resolveKinds(p *Property) {
+	ref := p.Ref
+	if p.Items != nil {
+		ref = p.Items.Ref
+	}
+	if def := d.Definitions[strings.TrimPrefix(ref, "#/definitions/")]; ref != "" && def != nil {
+		p.Kind = def.Kind
+	}
+	for _, property := range p.Properties {
+		d.resolveKinds(property)
+	}
+}
+
+func (p *Property) UnmarshalJSON(b []byte) error {
+	type property Property
+	if err := json.Unmarshal(b, (*property)(p)); err != nil {
+		return err
+	}
+	if p.Properties == nil {
+		p.Properties = make(map[string]*Property)
+	}
+	if p.Enum == nil {
+		p.Enum = make([]string, 0)
+	}
+	p.IsArray = p.Type == "array"
+	switch {
+	case len(p.Enum) > 0:
+		p.Kind = ast.Enum
+	case len(p.AnyOf) > 0 || len(p.OneOf) > 0:
+		p.Kind = ast.Union
+	case p.Type == "object" || len(p.Properties) > 0:
+		p.Kind = ast.InputObject
+	}
+	switch {
+	case p.Ref != "":
+		p.Name = strings.TrimPrefix(p.Ref, "#/definitions/")
+	case p.Items != nil && p.Items.Ref != "":
+		p.Name = strings.TrimPrefix(p.Items.Ref, "#/definitions/")
+	case p.Items != nil:
+		p.Name = BuiltInName(p.Items.Type)
+	case p.Kind == "":
+		p.Name = BuiltInName(p.Type)
+	}
+	markRequired(p.Properties, p.Required)
+	return nil
+}
+
+// BuiltInName
+// the GraphQL built-in scalar a JSON type comes from, the reverse of NewBasicType. IDs are strings, they come back as String
+func BuiltInName(jsonType string) string {
+	switch jsonType {
+	case "integer":
+		return "Int"
+	case "number":
+		return "Float"
+	case "boolean":
+		return "Boolean"
+	case "string":
+		return "String"
+	}
+	return ""
+}
+
+func markRequired(properties map[string]*Property, required []string) {
+	for _, name := range required {
+		if property := properties[name]; property != nil {
+			property.IsRequired = true
+		}
+	}
+}
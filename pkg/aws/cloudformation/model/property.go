@@ -14,7 +14,7 @@ type Property struct {
 	Title          string `json:"title,omitempty"`
 	Description    string `json:"description,omitempty"`
 	// `json:"examples,omitempty"`
-	// `json:"default,omitempty"`
+	Default interface{} `json:"default,omitempty"`
 	// `json:"multipleOf,omitempty"`
 	// `json:"maximum,omitempty"`
 	// `json:"exclusiveMaximum,omitempty"`
@@ -22,7 +22,7 @@ type Property struct {
 	// `json:"exclusiveMinimum,omitempty"`
 	// `json:"maxLength,omitempty"`
 	// `json:"minLength,omitempty"`
-	// `json:"pattern,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
 	// `json:"items,omitempty"`
 	// `json:"maxItems,omitempty"`
 	// `json:"minItems,omitempty"`
@@ -41,6 +41,8 @@ type Property struct {
 	Ref   string   `json:"$ref,omitempty"`
 	Items *Item    `json:"items,omitempty"`
 	// `json:"format,omitempty"`
+	WriteOnly       bool             `json:"writeOnly,omitempty"`
+	RelationshipRef *RelationshipRef `json:"relationshipRef,omitempty"`
 	// `json:"allOf,omitempty"`
 	AnyOf []*Item `json:"anyOf,omitempty"`
 	OneOf []*Item `json:"oneOf,omitempty"`
@@ -49,8 +51,18 @@ type Property struct {
 	Kind               ast.DefinitionKind `json:"-"`
 	BuiltIn            bool               `json:"-"`
 	IsRequired         bool               `json:"-"`
-	IsArray            bool               `json:"="`
-	ArrayEntryRequired bool               `json:"="`
+	IsArray            bool               `json:"-"`
+	Sensitive          bool               `json:"-"` // Secret in the Terraform and Pulumi output, writeOnly in the schema
+	GraphQLName        string             `json:"-"` // Original argument or field name
+	GraphQLDescription string             `json:"-"` // Original argument, field or type description, for docs
+	ArgumentPaths      []string           `json:"-"` // Top-level properties: mutation.argument for each mutation taking it
+	ArrayEntryRequired bool               `json:"-"`
+}
+
+// RelationshipRef the property holds the identifier of another resource type
+type RelationshipRef struct {
+	TypeName     string `json:"typeName"`
+	PropertyPath string `json:"propertyPath"`
 }
 
 type Item struct {
@@ -127,6 +139,7 @@ func NewDefinitionProperty(definition *ast.Definition, typeDef *ast.Type) (prope
 
 	name := nameFromType(typeDef)
 	property.Name = name
+	property.GraphQLDescription = definition.Description
 
 	// Modify each property based on definition kind
 	err = property.createNewDefinitionKind(definition, typeDef)
@@ -185,6 +198,24 @@ func (p *Property) createNewDefinitionKind(definition *ast.Definition, typeDef *
 	return err
 }
 
+// IsIDType
+// GraphQL ID, after translation it's a JSON string
+func (p *Property) IsIDType() bool {
+	return p.Kind == "" && !p.IsArray && p.Name == "ID"
+}
+
+// OriginalName
+// the GraphQL argument or field name, the CloudFormation name with a lowercase first letter if it's not known
+func (p *Property) OriginalName(name string) string {
+	if p.GraphQLName != "" {
+		return p.GraphQLName
+	}
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
 func (p *Property) AsSchemaProperty() *Property {
 	// TODO massage this property as a JSON Schema top-level "property"
 	return p
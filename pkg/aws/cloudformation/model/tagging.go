@@ -0,0 +1,58 @@
+package model
+
+// Tagging is the resource schema "tagging" block (https://github.com/aws-cloudformation/cloudformation-cli/blob/master/src/rpdk/core/data/schema/provider.definition.schema.v1.json)
+// The flags are always written, CloudFormation takes a missing one as true
+type Tagging struct {
+	Taggable                 bool   `json:"taggable"`
+	TagOnCreate              bool   `json:"tagOnCreate"`
+	TagUpdatable             bool   `json:"tagUpdatable"`
+	CloudFormationSystemTags bool   `json:"cloudFormationSystemTags"`
+	TagProperty              string `json:"tagProperty,omitempty"`
+}
+
+// TagDefinitionName name of the CloudFormation style tag definition
+const TagDefinitionName = "Tag"
+
+// NewTagging
+// a non-taggable resource, the strategy decides the rest
+func NewTagging() *Tagging {
+	return &Tagging{Taggable: false}
+}
+
+// NewTagDefinition
+// the CloudFormation [{Key, Value}] tag shape
+func NewTagDefinition() *Property {
+	f := new(bool)
+	*f = false
+	return &Property{
+		Type:                 "object",
+		Required:             []string{"Key", "Value"},
+		AdditionalProperties: f,
+		Properties: map[string]*Property{
+			"Key":   {Type: "string"},
+			"Value": {Type: "string"},
+		},
+		Name: TagDefinitionName,
+	}
+}
+
+// NewTagListProperty
+// an array of tag definition references
+func NewTagListProperty() *Property {
+	f := new(bool)
+	*f = false
+	return &Property{
+		InsertionOrder: f,
+		Type:           "array",
+		Items:          &Item{Ref: "#/definitions/" + TagDefinitionName},
+		Properties:     make(map[string]*Property),
+		Name:           TagDefinitionName,
+		IsArray:        true,
+	}
+}
+
+// PropertyPath
+// JSON pointer to a top-level property, as used by tagProperty, primaryIdentifier, etc.
+func PropertyPath(name string) string {
+	return "/properties/" + uppercaseTypeName(name)
+}
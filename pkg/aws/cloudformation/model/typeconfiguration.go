@@ -0,0 +1,79 @@
+package model
+
+// TypeConfiguration is the resource schema "typeConfiguration", set per account with `aws cloudformation set-type-configuration`
+type TypeConfiguration struct {
+	Properties           map[string]*Property `json:"properties"`
+	AdditionalProperties bool                 `json:"additionalProperties"`
+	Required             []string             `json:"required"`
+}
+
+const (
+	// TypeConfigurationPermission lets the handlers read the type configuration
+	TypeConfigurationPermission = "cloudformation:BatchDescribeTypeConfigurations"
+	// AccessDefinitionName name of the NerdGraph credentials and endpoint definition
+	AccessDefinitionName = "NewRelicAccess"
+	// EndpointPattern US, EU, a custom NerdGraph https URL, or http on the loopback host for a local mock server
+	EndpointPattern = "^(US|EU|https://[0-9a-zA-Z]([-.\\w]*[0-9a-zA-Z])(:[0-9]*)*([?/#].*)?|http://(localhost|127\\.0\\.0\\.1|\\[::1\\])(:[0-9]+)?([?/#].*)?)$"
+)
+
+// NewTypeConfiguration
+// a single required property referencing the NerdGraph ApiKey and Endpoint definition
+func NewTypeConfiguration() *TypeConfiguration {
+	return &TypeConfiguration{
+		Properties: map[string]*Property{
+			AccessDefinitionName: {Ref: "#/definitions/" + AccessDefinitionName, Properties: make(map[string]*Property)},
+		},
+		AdditionalProperties: false,
+		Required:             []string{AccessDefinitionName},
+	}
+}
+
+// NewAccessDefinition
+// the definition referenced by the type configuration, defaultEndpoint is US, EU, a custom URL or "" for none
+func NewAccessDefinition(defaultEndpoint string) *Property {
+	f := new(bool)
+	*f = false
+	var def interface{}
+	if defaultEndpoint != "" {
+		def = defaultEndpoint
+	}
+	return &Property{
+		Type:                 "object",
+		Required:             []string{"ApiKey"},
+		AdditionalProperties: f,
+		Properties: map[string]*Property{
+			"ApiKey": {
+				Type:        "string",
+				Description: "New Relic User API key, use a dynamic reference such as {{resolve:ssm-secure:...}}",
+				WriteOnly:   true,
+				Sensitive:   true,
+			},
+			"Endpoint": {
+				Type:        "string",
+				Description: "NerdGraph endpoint: US, EU, a custom https URL or http://localhost for testing",
+				Pattern:     EndpointPattern,
+				Default:     def,
+			},
+		},
+		Name: AccessDefinitionName,
+	}
+}
+
+// SetTypeConfiguration
+// add the type configuration, its definition, and the permission for every handler to read it, once
+func (d *Document) SetTypeConfiguration(typeConfiguration *TypeConfiguration, access *Property) {
+	d.TypeConfiguration = typeConfiguration
+	d.Definitions[AccessDefinitionName] = access
+	for _, handler := range d.Handlers {
+		found := false
+		for _, permission := range handler.Permissions {
+			if permission == TypeConfigurationPermission {
+				found = true
+				break
+			}
+		}
+		if !found {
+			handler.Permissions = append(handler.Permissions, TypeConfigurationPermission)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestEndpointPattern(t *testing.T) {
+	pattern := regexp.MustCompile(EndpointPattern)
+	tests := []struct {
+		endpoint string
+		want     bool
+	}{
+		{"US", true},
+		{"EU", true},
+		{"https://api.newrelic.com/graphql", true},
+		{"https://nerdgraph.example.com:8443/graphql", true},
+		{"http://localhost:8080/graphql", true},
+		{"http://localhost", true},
+		{"http://127.0.0.1:8080/graphql", true},
+		{"http://[::1]:8080/graphql", true},
+		{"http://api.newrelic.com/graphql", false},
+		{"http://localhost.example.com/graphql", false},
+		{"http://127.0.0.2/graphql", false},
+		{"ftp://localhost/graphql", false},
+		{"us", false},
+	}
+	for _, tt := range tests {
+		if got := pattern.MatchString(tt.endpoint); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.endpoint, got, tt.want)
+		}
+	}
+}
+
+func TestSetTypeConfigurationPermissionOnce(t *testing.T) {
+	doc := NewDocument()
+	doc.AddHandler("create", NewHandler([]string{"a:b", TypeConfigurationPermission}, 0))
+	doc.AddHandler("read", NewHandler(nil, 0))
+	doc.SetTypeConfiguration(NewTypeConfiguration(), NewAccessDefinition("US"))
+	doc.SetTypeConfiguration(NewTypeConfiguration(), NewAccessDefinition("US"))
+	for name, handler := range doc.Handlers {
+		n := 0
+		for _, permission := range handler.Permissions {
+			if permission == TypeConfigurationPermission {
+				n++
+			}
+		}
+		if n != 1 {
+			t.Errorf("%s: %s %d times in %v", name, TypeConfigurationPermission, n, handler.Permissions)
+		}
+	}
+}
+
+func TestApiKeyIsSensitive(t *testing.T) {
+	apiKey := NewAccessDefinition("").Properties["ApiKey"]
+	if !apiKey.WriteOnly || !apiKey.Sensitive {
+		t.Errorf("ApiKey writeOnly %t, sensitive %t", apiKey.WriteOnly, apiKey.Sensitive)
+	}
+	// writeOnly says it, the description needn't
+	if strings.HasPrefix(apiKey.Description, "Sensitive") {
+		t.Errorf("description %s", apiKey.Description)
+	}
+}
@@ -0,0 +1,458 @@
+package validate
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Offline validation of CloudFormation templates, YAML or JSON, against the generated resource types
+
+- Resources whose Type is a generated TypeName have their Properties checked against the Document: unknown and
+  read-only properties, missing required ones, JSON types, enum values and patterns, down through the definitions
+- Intrinsic functions (!Ref, {"Fn::If": ...}, ...) stand in for any value, they're not checked
+- Every Fn::GetAtt (and ${Name.Attribute} in Fn::Sub) on one of those resources has to name a readOnlyProperty
+
+Errors carry the template's line and column.
+*/
+
+// Error
+// a problem in the template and where it is
+type Error struct {
+	File    string
+	Line    int
+	Column  int
+	Path    string // e.g. Resources.Channel.Properties.Channel.Type
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", e.File, e.Line, e.Column, e.Path, e.Message)
+}
+
+// Validator
+// the generated types by TypeName
+type Validator struct {
+	types map[string]*model.Document
+}
+
+// New
+// a validator for the documents' types
+func New(docs []*model.Document) *Validator {
+	v := &Validator{types: make(map[string]*model.Document, len(docs))}
+	for _, doc := range docs {
+		v.types[doc.TypeName] = doc
+	}
+	return v
+}
+
+// File
+// read and validate the template
+func (v *Validator) File(file string) ([]*Error, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("validate: %w", err)
+	}
+	return v.Validate(file, b)
+}
+
+// Validate
+// the template's problems, sorted by line. err is for a template that can't be parsed
+func (v *Validator) Validate(file string, b []byte) ([]*Error, error) {
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(b, root); err != nil {
+		return nil, fmt.Errorf("validate: %s: %w", file, err)
+	}
+	c := &checker{types: v.types, file: file, resources: make(map[string]*model.Document)}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	template := resolve(root.Content[0])
+	if template.Kind != yaml.MappingNode {
+		c.errorf(template, "", "the template isn't a mapping")
+		return c.errors, nil
+	}
+	if resources := value(template, "Resources"); resources != nil && resolve(resources).Kind == yaml.MappingNode {
+		resources = resolve(resources)
+		for i := 0; i+1 < len(resources.Content); i += 2 {
+			logicalId := resources.Content[i].Value
+			c.resource(logicalId, resolve(resources.Content[i+1]))
+		}
+	}
+	c.getAtts(template, "")
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		if c.errors[i].Line != c.errors[j].Line {
+			return c.errors[i].Line < c.errors[j].Line
+		}
+		return c.errors[i].Column < c.errors[j].Column
+	})
+	return c.errors, nil
+}
+
+type checker struct {
+	types     map[string]*model.Document
+	file      string
+	resources map[string]*model.Document // Logical id -> the generated type it declares
+	doc       *model.Document
+	errors    []*Error
+}
+
+func (c *checker) errorf(node *yaml.Node, path string, format string, args ...interface{}) {
+	c.errors = append(c.errors, &Error{File: c.file, Line: node.Line, Column: node.Column, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// resource
+// the resource's Properties when its Type is a generated one
+func (c *checker) resource(logicalId string, resource *yaml.Node) {
+	if resource.Kind != yaml.MappingNode {
+		return
+	}
+	t := value(resource, "Type")
+	if t == nil {
+		return
+	}
+	doc := c.types[resolve(t).Value]
+	if doc == nil {
+		return
+	}
+	c.resources[logicalId] = doc
+	c.doc = doc
+	path := "Resources." + logicalId + ".Properties"
+	properties := value(resource, "Properties")
+	if properties == nil {
+		if len(doc.Required) > 0 {
+			c.errorf(resource, "Resources."+logicalId, "%s requires Properties: %s", doc.TypeName, strings.Join(sorted(doc.Required), ", "))
+		}
+		return
+	}
+	properties = resolve(properties)
+	if intrinsic(properties) {
+		return
+	}
+	if properties.Kind != yaml.MappingNode {
+		c.errorf(properties, path, "expected a mapping")
+		return
+	}
+	readOnly := make(map[string]bool)
+	for _, p := range doc.ReadOnlyProperties {
+		readOnly[strings.TrimPrefix(p, "/properties/")] = true
+	}
+	for i := 0; i+1 < len(properties.Content); i += 2 {
+		key := properties.Content[i]
+		if readOnly[key.Value] {
+			c.errorf(key, path+"."+key.Value, "%s is read-only, NerdGraph assigns it", key.Value)
+		}
+	}
+	c.object(properties, doc.Properties, doc.Required, nil, path)
+}
+
+// object
+// a mapping's keys against the definition's properties
+func (c *checker) object(node *yaml.Node, properties map[string]*model.Property, required []string, additional *bool, path string) {
+	for _, name := range sorted(required) {
+		if value(node, name) == nil {
+			c.errorf(node, path, "missing required property %s", name)
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, v := node.Content[i], node.Content[i+1]
+		property := properties[key.Value]
+		if property == nil {
+			if additional == nil || !*additional {
+				c.errorf(key, path+"."+key.Value, "unknown property %s%s", key.Value, suggestion(key.Value, properties))
+			}
+			continue
+		}
+		c.value(resolve(v), property, path+"."+key.Value)
+	}
+}
+
+// value
+// the node against the property: type, enum, pattern, then its items or properties
+func (c *checker) value(node *yaml.Node, property *model.Property, path string) {
+	if intrinsic(node) {
+		return
+	}
+	if property.Type == "array" {
+		if node.Kind != yaml.SequenceNode {
+			c.errorf(node, path, "expected a list")
+			return
+		}
+		if property.Items == nil {
+			return
+		}
+		item := &model.Property{Type: property.Items.Type, Ref: property.Items.Ref, AnyOf: property.Items.AnyOf}
+		for i, e := range node.Content {
+			c.value(resolve(e), item, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return
+	}
+
+	def := property
+	if property.Ref != "" {
+		if def = c.doc.Definitions[strings.TrimPrefix(property.Ref, "#/definitions/")]; def == nil {
+			return
+		}
+		if def.Type == "array" {
+			c.value(node, def, path)
+			return
+		}
+	}
+	pattern := property.Pattern
+	if pattern == "" {
+		pattern = def.Pattern
+	}
+
+	members := append(append([]*model.Item{}, def.AnyOf...), def.OneOf...)
+	switch {
+	case len(members) > 0:
+		c.union(node, members, path)
+	case len(def.Properties) > 0 || def.Type == "object":
+		if node.Kind != yaml.MappingNode {
+			c.errorf(node, path, "expected a mapping")
+			return
+		}
+		c.object(node, def.Properties, def.Required, def.AdditionalProperties, path)
+	case node.Kind != yaml.ScalarNode:
+		c.errorf(node, path, "expected a scalar of type %s", scalarType(def.Type))
+	case len(def.Enum) > 0:
+		if !contains(def.Enum, node.Value) {
+			c.errorf(node, path, "%q is not one of %s", node.Value, strings.Join(def.Enum, ", "))
+		}
+	default:
+		if !scalarOf(node, def.Type) {
+			c.errorf(node, path, "%q is not of type %s", node.Value, scalarType(def.Type))
+			return
+		}
+		if pattern != "" {
+			// ECMAScript patterns Go can't compile aren't checked
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(node.Value) {
+				c.errorf(node, path, "%q doesn't match %s", node.Value, pattern)
+			}
+		}
+	}
+}
+
+// union
+// the mapping is valid when one of the members accepts it
+func (c *checker) union(node *yaml.Node, members []*model.Item, path string) {
+	errors := c.errors
+	for _, member := range members {
+		c.errors = nil
+		c.value(node, &model.Property{Type: member.Type, Ref: member.Ref}, path)
+		if len(c.errors) == 0 {
+			c.errors = errors
+			return
+		}
+	}
+	c.errors = errors
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		names = append(names, strings.TrimPrefix(member.Ref, "#/definitions/"))
+	}
+	c.errorf(node, path, "doesn't match any of %s", strings.Join(names, ", "))
+}
+
+// getAtts
+// every Fn::GetAtt and Fn::Sub ${Name.Attribute} on a generated resource names a readOnlyProperty
+func (c *checker) getAtts(node *yaml.Node, path string) {
+	switch node.Tag {
+	case "!GetAtt":
+		c.getAtt(node, path)
+		return
+	case "!Sub":
+		c.sub(node, path)
+		return
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, v := node.Content[i], node.Content[i+1]
+			switch key.Value {
+			case "Fn::GetAtt":
+				c.getAtt(v, join(path, key.Value))
+			case "Fn::Sub":
+				c.sub(v, join(path, key.Value))
+			default:
+				c.getAtts(v, join(path, key.Value))
+			}
+		}
+	case yaml.SequenceNode:
+		for i, e := range node.Content {
+			c.getAtts(e, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+// getAtt
+// Name.Attribute or [Name, Attribute]
+func (c *checker) getAtt(node *yaml.Node, path string) {
+	var logicalId, attribute string
+	switch node.Kind {
+	case yaml.ScalarNode:
+		logicalId, attribute, _ = strings.Cut(node.Value, ".")
+	case yaml.SequenceNode:
+		if len(node.Content) != 2 || node.Content[0].Kind != yaml.ScalarNode {
+			return
+		}
+		logicalId = node.Content[0].Value
+		if node.Content[1].Kind != yaml.ScalarNode {
+			// The attribute is an intrinsic function, e.g. !Ref
+			c.getAtts(node.Content[1], path)
+			return
+		}
+		attribute = node.Content[1].Value
+	default:
+		return
+	}
+	c.attribute(node, path, logicalId, attribute)
+}
+
+// sub
+// the ${Name.Attribute} references in the string, variables in the map shadow resources
+var subReference = regexp.MustCompile(`\$\{([^!}][^}]*)}`)
+
+func (c *checker) sub(node *yaml.Node, path string) {
+	s := node
+	variables := map[string]bool{}
+	if node.Kind == yaml.SequenceNode && len(node.Content) > 0 {
+		s = node.Content[0]
+		if len(node.Content) > 1 {
+			m := resolve(node.Content[1])
+			for i := 0; i+1 < len(m.Content); i += 2 {
+				variables[m.Content[i].Value] = true
+				c.getAtts(m.Content[i+1], path)
+			}
+		}
+	}
+	if s.Kind != yaml.ScalarNode {
+		return
+	}
+	for _, match := range subReference.FindAllStringSubmatch(s.Value, -1) {
+		logicalId, attribute, found := strings.Cut(strings.TrimSpace(match[1]), ".")
+		if found && !variables[match[1]] {
+			c.attribute(s, path, logicalId, attribute)
+		}
+	}
+}
+
+// attribute
+// a readOnlyProperty of the resource, nested ones with dots
+func (c *checker) attribute(node *yaml.Node, path string, logicalId string, attribute string) {
+	doc := c.resources[logicalId]
+	if doc == nil {
+		return
+	}
+	readOnly := make([]string, 0, len(doc.ReadOnlyProperties))
+	for _, p := range doc.ReadOnlyProperties {
+		name := strings.ReplaceAll(strings.TrimPrefix(p, "/properties/"), "/properties/", ".")
+		name = strings.ReplaceAll(name, "/", ".")
+		if name == attribute {
+			return
+		}
+		readOnly = append(readOnly, name)
+	}
+	sort.Strings(readOnly)
+	c.errorf(node, path, "%s (%s) has no attribute %s, Fn::GetAtt can read %s", logicalId, doc.TypeName, attribute, strings.Join(readOnly, ", "))
+}
+
+// intrinsic
+// a short form tag (!Ref, !Sub, ...) or a single key mapping Ref, Condition or Fn::*
+func intrinsic(node *yaml.Node) bool {
+	if strings.HasPrefix(node.Tag, "!") && !strings.HasPrefix(node.Tag, "!!") {
+		return true
+	}
+	if node.Kind != yaml.MappingNode || len(node.Content) != 2 {
+		return false
+	}
+	key := node.Content[0].Value
+	return key == "Ref" || key == "Condition" || strings.HasPrefix(key, "Fn::")
+}
+
+// scalarOf
+// the scalar is the JSON type, quoted numbers and booleans too: CloudFormation converts them
+func scalarOf(node *yaml.Node, jsonType string) bool {
+	switch jsonType {
+	case "integer":
+		_, err := strconv.ParseInt(node.Value, 10, 64)
+		return err == nil
+	case "number":
+		_, err := strconv.ParseFloat(node.Value, 64)
+		return err == nil
+	case "boolean":
+		return node.Value == "true" || node.Value == "false"
+	}
+	return node.Tag != "!!null"
+}
+
+func scalarType(jsonType string) string {
+	if jsonType == "" {
+		return "string"
+	}
+	return jsonType
+}
+
+// suggestion
+// a property with the same name but for case, the usual typo
+func suggestion(name string, properties map[string]*model.Property) string {
+	for p := range properties {
+		if strings.EqualFold(p, name) {
+			return ", did you mean " + p + "?"
+		}
+	}
+	return ""
+}
+
+// value
+// the mapping's value for key, nil if it's not there
+func value(node *yaml.Node, key string) *yaml.Node {
+	node = resolve(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// resolve
+// the node an alias stands for
+func resolve(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// sorted
+// a sorted copy, required lists come from maps in no particular order
+func sorted(list []string) []string {
+	s := append([]string{}, list...)
+	sort.Strings(s)
+	return s
+}
+
+func join(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
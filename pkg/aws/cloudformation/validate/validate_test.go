@@ -0,0 +1,303 @@
+package validate_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/validate"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// A type with a union, the fixture has none
+const target = `{
+   "typeName": "NewRelic::Observability::target",
+   "definitions": {
+      "Email": {"type": "object", "properties": {"Address": {"type": "string", "pattern": "^[^@]+@[^@]+$"}}, "required": ["Address"], "additionalProperties": false},
+      "Slack": {"type": "object", "properties": {"Channel": {"type": "string"}}, "required": ["Channel"], "additionalProperties": false},
+      "Target": {"oneOf": [{"$ref": "#/definitions/Email"}, {"$ref": "#/definitions/Slack"}]}
+   },
+   "properties": {
+      "Guid": {"type": "string"},
+      "Target": {"$ref": "#/definitions/Target"},
+      "Weight": {"type": "number"},
+      "Enabled": {"type": "boolean"}
+   },
+   "readOnlyProperties": ["/properties/Guid"],
+   "primaryIdentifier": ["/properties/Guid"],
+   "handlers": {}
+}`
+
+// validator
+// the fixture's types and target
+func validator(t *testing.T) *validate.Validator {
+	t.Helper()
+	services, err := fixture.Services(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := make([]*model.Document, 0, len(services)+1)
+	for _, service := range services {
+		docs = append(docs, service.Document())
+	}
+	doc := &model.Document{}
+	if err = json.Unmarshal([]byte(target), doc); err != nil {
+		t.Fatal(err)
+	}
+	return validate.New(append(docs, doc))
+}
+
+// where
+// the errors as "<line>:<column> <path>"
+func where(errors []*validate.Error) []string {
+	l := make([]string, 0, len(errors))
+	for _, e := range errors {
+		l = append(l, fmt.Sprintf("%d:%d %s", e.Line, e.Column, e.Path))
+	}
+	return l
+}
+
+func TestFileGolden(t *testing.T) {
+	errors, err := validator(t).File("testdata/template.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for _, e := range errors {
+		b.WriteString(e.Error() + "\n")
+	}
+	fixture.Golden(t, filepath.Join("testdata", "template.txt"), []byte(b.String()))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		want     []string
+		message  string // The first error's
+	}{
+		{
+			name: "valid",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+    Properties:
+      AccountId: "1"
+      Channel: {DestinationId: d, Name: n, Product: ALERTS, Properties: [], Type: EMAIL}
+`,
+		},
+		{
+			name: "unknown property",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+    Properties:
+      AccountId: 1
+      Channel: {DestinationId: d, name: n, Name: n, Product: ALERTS, Properties: [], Type: EMAIL}
+`,
+			want:    []string{"6:35 Resources.Chan.Properties.Channel.name"},
+			message: "unknown property name, did you mean Name?",
+		},
+		{
+			name: "read-only property",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+    Properties:
+      Guid: g
+      AccountId: 1
+      Channel: {DestinationId: d, Name: n, Product: ALERTS, Properties: [], Type: EMAIL}
+`,
+			want:    []string{"5:7 Resources.Chan.Properties.Guid"},
+			message: "Guid is read-only, NerdGraph assigns it",
+		},
+		{
+			name: "missing required",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+    Properties:
+      AccountId: 1
+      Channel:
+        DestinationId: d
+        Name: n
+        Product: ALERTS
+        Properties: []
+`,
+			want:    []string{"7:9 Resources.Chan.Properties.Channel"},
+			message: "missing required property Type",
+		},
+		{
+			name: "missing Properties",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+`,
+			want:    []string{"3:5 Resources.Chan"},
+			message: "requires Properties",
+		},
+		{
+			name: "wrong type",
+			template: `Resources:
+  Target:
+    Type: NewRelic::Observability::target
+    Properties:
+      Weight: heavy
+      Enabled: "yes"
+`,
+			want:    []string{"5:15 Resources.Target.Properties.Weight", "6:16 Resources.Target.Properties.Enabled"},
+			message: `"heavy" is not of type number`,
+		},
+		{
+			name: "bad enum",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+    Properties:
+      AccountId: 1
+      Channel: {DestinationId: d, Name: n, Product: NOPE, Properties: [], Type: EMAIL}
+`,
+			want:    []string{"6:53 Resources.Chan.Properties.Channel.Product"},
+			message: `"NOPE" is not one of ALERTS, IINT`,
+		},
+		{
+			name: "list item",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+    Properties:
+      AccountId: 1
+      Channel:
+        DestinationId: d
+        Name: n
+        Product: ALERTS
+        Type: EMAIL
+        Properties:
+          - {Key: k, Value: v}
+          - {Key: k}
+`,
+			want:    []string{"13:13 Resources.Chan.Properties.Channel.Properties[1]"},
+			message: "missing required property Value",
+		},
+		{
+			name: "union member",
+			template: `Resources:
+  Target:
+    Type: NewRelic::Observability::target
+    Properties:
+      Target: {Channel: "#alerts"}
+`,
+		},
+		{
+			name: "no union member",
+			template: `Resources:
+  Target:
+    Type: NewRelic::Observability::target
+    Properties:
+      Target: {Address: not-an-address}
+`,
+			want:    []string{"5:15 Resources.Target.Properties.Target"},
+			message: "doesn't match any of Email, Slack",
+		},
+		{
+			// Intrinsic functions stand in for any value
+			name: "intrinsic functions",
+			template: `Resources:
+  Chan:
+    Type: NewRelic::Observability::aiNotificationsChannel
+    Properties:
+      AccountId: !Ref Account
+      Channel:
+        DestinationId: {"Fn::GetAtt": [Dest, DestinationId]}
+        Name: !Sub "${AWS::StackName}"
+        Product: {"Fn::If": [Prod, ALERTS, IINT]}
+        Properties: !Ref Properties
+        Type: !Ref Type
+  Dest:
+    Type: NewRelic::Observability::aiNotificationsDestination
+    Properties: {"Fn::If": [Prod, {}, {}]}
+`,
+		},
+		{
+			name: "GetAtt on a property",
+			template: `Resources:
+  Target:
+    Type: NewRelic::Observability::target
+    Properties: {}
+Outputs:
+  Guid: {Value: !GetAtt Target.Guid}
+  Weight: {Value: {"Fn::GetAtt": [Target, Weight]}}
+`,
+			want:    []string{"7:34 Outputs.Weight.Value.Fn::GetAtt"},
+			message: "Target (NewRelic::Observability::target) has no attribute Weight, Fn::GetAtt can read Guid",
+		},
+		{
+			// Variables in the map aren't resources, ${!...} is a literal
+			name: "Sub reference",
+			template: `Resources:
+  Target:
+    Type: NewRelic::Observability::target
+    Properties: {}
+Outputs:
+  A: {Value: !Sub "${Target.Guid} ${!Target.Weight}"}
+  B: {Value: {"Fn::Sub": ["${Target.Weight} ${Target.Enabled}", {"Target.Weight": w}]}}
+`,
+			want:    []string{"7:27 Outputs.B.Value.Fn::Sub"},
+			message: "has no attribute Enabled",
+		},
+		{
+			name: "other types",
+			template: `Resources:
+  Topic:
+    Type: AWS::SNS::Topic
+    Properties: {Unknown: !GetAtt Topic.TopicName}
+`,
+		},
+	}
+	v := validator(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errors, err := v.Validate("template.yaml", []byte(tt.template))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := where(errors); strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("got %v, want %v", errors, tt.want)
+			}
+			if len(errors) > 0 && !strings.Contains(errors[0].Message, tt.message) {
+				t.Errorf("got %q, want %q", errors[0].Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	// JSON is YAML, the errors have its lines too
+	template := `{
+  "Resources": {
+    "Target": {
+      "Type": "NewRelic::Observability::target",
+      "Properties": {"Weight": "heavy"}
+    }
+  }
+}`
+	errors, err := validator(t).Validate("template.json", []byte(template))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := where(errors); len(got) != 1 || got[0] != "5:32 Resources.Target.Properties.Weight" {
+		t.Errorf("got %v", got)
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+	if _, err := validator(t).Validate("template.yaml", []byte("Resources: [")); err == nil {
+		t.Error("no error for a template that doesn't parse")
+	}
+	errors, err := validator(t).Validate("template.yaml", []byte("- a list"))
+	if err != nil || len(errors) != 1 || errors[0].Error() != "template.yaml:1:1: : the template isn't a mapping" {
+		t.Errorf("got %v, %v", errors, err)
+	}
+}
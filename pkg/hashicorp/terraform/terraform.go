@@ -0,0 +1,468 @@
+package terraform
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"fmt"
+	"github.com/vektah/gqlparser/v2/ast"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+/*
+Generate the Terraform Plugin Framework resource schema (schema.Schema Go source) for a Service, from the same
+Document the CloudFormation schema comes from
+
+- Required/optional from the create inputs, computed from readOnlyProperties and the payload entity's fields no input
+  sets (e.g. createdAt)
+- createOnlyProperties plan RequiresReplace, computed attributes UseStateForUnknown
+- Input objects are nested blocks, SingleNestedBlock or ListNestedBlock for lists. Blocks can't be required, a
+  required one gets an IsRequired validator
+- Enums are strings with a OneOf validator, the tag property a map of strings
+*/
+
+const header = "// Code generated by gqlparser from the NerdGraph schema. DO NOT EDIT.\n\n"
+
+const (
+	frameworkPath  = "github.com/hashicorp/terraform-plugin-framework/"
+	validatorsPath = "github.com/hashicorp/terraform-plugin-framework-validators/"
+)
+
+// Generate
+// write dir/<resource name>_resource_schema.go
+func Generate(service *nerdgraph.Service, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("terraform: %w", err)
+	}
+	source, err := Source(service)
+	if err != nil {
+		return err
+	}
+	file := filepath.Join(dir, strings.TrimPrefix(ResourceName(service), "newrelic_")+"_resource_schema.go")
+	if err = os.WriteFile(file, source, 0644); err != nil {
+		return fmt.Errorf("terraform: %w", err)
+	}
+	return nil
+}
+
+// ResourceName
+// the Terraform resource type, aiNotificationsChannel -> newrelic_ai_notifications_channel
+func ResourceName(service *nerdgraph.Service) string {
+	return "newrelic_" + snake(service.GetName())
+}
+
+// Source
+// the formatted schema function, <Service>ResourceSchema(ctx) in package provider
+func Source(service *nerdgraph.Service) ([]byte, error) {
+	doc := service.Document()
+	g := &generator{doc: doc, imports: map[string]bool{frameworkPath + "resource/schema": true}, visiting: make(map[string]bool)}
+
+	readOnly := make(map[string]bool)
+	for _, path := range doc.ReadOnlyProperties {
+		readOnly[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	createOnly := make(map[string]bool)
+	for _, path := range doc.CreateOnlyProperties {
+		createOnly[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	tagProperty := ""
+	if doc.Tagging != nil {
+		tagProperty = strings.TrimPrefix(doc.Tagging.TagProperty, "/properties/")
+	}
+
+	attributes := make([]*field, 0, len(doc.Properties))
+	for _, name := range sortedKeys(doc.Properties) {
+		property := doc.Properties[name]
+		attributes = append(attributes, &field{
+			name:        snake(property.OriginalName(name)),
+			property:    property,
+			description: description(property),
+			required:    contains(doc.Required, name) && !readOnly[name],
+			computed:    readOnly[name],
+			replace:     createOnly[name] || doc.Handlers["update"] == nil,
+			tags:        name == tagProperty,
+		})
+	}
+	attributes = append(attributes, g.payloadFields(service, attributes)...)
+
+	var body strings.Builder
+	g.writeObject(&body, attributes)
+	name := identifier(service.GetName())
+
+	var file strings.Builder
+	file.WriteString(header + "package provider\n\nimport (\n\"context\"\n")
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		file.WriteString(fmt.Sprintf("%q\n", path))
+	}
+	file.WriteString(")\n\n")
+	file.WriteString(fmt.Sprintf("// %sResourceSchema\n// %s, %s\n", name, ResourceName(service), doc.TypeName))
+	file.WriteString(fmt.Sprintf("func %sResourceSchema(ctx context.Context) schema.Schema {\nreturn schema.Schema{\n", name))
+	if d := strings.TrimSpace(doc.GraphQLDescription); d != "" {
+		file.WriteString(fmt.Sprintf("Description: %q,\n", d))
+	}
+	file.WriteString(body.String())
+	file.WriteString("}\n}\n")
+
+	source, err := format.Source([]byte(file.String()))
+	if err != nil {
+		return nil, fmt.Errorf("terraform: %s: %w", doc.TypeName, err)
+	}
+	return source, nil
+}
+
+// field
+// an attribute or block to write
+type field struct {
+	name        string
+	property    *model.Property
+	description string
+	required    bool
+	computed    bool
+	replace     bool
+	tags        bool
+}
+
+type generator struct {
+	doc      *model.Document
+	imports  map[string]bool
+	visiting map[string]bool
+}
+
+// payloadFields
+// the entity's scalar fields nothing sets, computed
+func (g *generator) payloadFields(service *nerdgraph.Service, attributes []*field) []*field {
+	entity := service.EntityType()
+	if entity == nil {
+		return nil
+	}
+	covered := map[string]bool{service.IdentifierField(): true}
+	for _, a := range attributes {
+		covered[a.property.OriginalName("")] = true
+		covered[a.name] = true
+		if def := g.object(a.property); def != nil {
+			for name, p := range def.Properties {
+				covered[p.OriginalName(name)] = true
+			}
+		}
+	}
+	computed := make([]*field, 0)
+	for _, f := range entity.Fields {
+		if covered[f.Name] || covered[snake(f.Name)] {
+			continue
+		}
+		property := g.scalarProperty(service.GetSchemaDocument(), f.Type)
+		if property == nil {
+			continue
+		}
+		computed = append(computed, &field{name: snake(f.Name), property: property, description: strings.TrimSpace(f.Description), computed: true})
+	}
+	return computed
+}
+
+// scalarProperty
+// a Property for a scalar, enum or list of them, nil for anything else
+func (g *generator) scalarProperty(schemaDocument *ast.SchemaDocument, t *ast.Type) *model.Property {
+	if t.Elem != nil {
+		item := g.scalarProperty(schemaDocument, t.Elem)
+		if item == nil {
+			return nil
+		}
+		return &model.Property{Type: "array", Items: &model.Item{Type: item.Type}}
+	}
+	def := schemaDocument.Definitions.ForName(t.NamedType)
+	if def == nil {
+		switch t.NamedType {
+		case "Int":
+			return &model.Property{Type: "integer"}
+		case "Float":
+			return &model.Property{Type: "number"}
+		case "Boolean":
+			return &model.Property{Type: "boolean"}
+		}
+		return &model.Property{Type: "string"}
+	}
+	switch def.Kind {
+	case ast.Scalar:
+		return &model.Property{Type: "string"}
+	case ast.Enum:
+		values := make([]string, 0, len(def.EnumValues))
+		for _, v := range def.EnumValues {
+			values = append(values, v.Name)
+		}
+		return &model.Property{Type: "string", Enum: values}
+	}
+	return nil
+}
+
+// writeObject
+// the Attributes and Blocks of a schema or block
+func (g *generator) writeObject(b *strings.Builder, fields []*field) {
+	attributes := make([]*field, 0, len(fields))
+	blocks := make([]*field, 0)
+	for _, f := range fields {
+		if !f.tags && g.object(f.property) != nil {
+			blocks = append(blocks, f)
+		} else {
+			attributes = append(attributes, f)
+		}
+	}
+	if len(attributes) > 0 {
+		b.WriteString("Attributes: map[string]schema.Attribute{\n")
+		for _, f := range attributes {
+			g.writeAttribute(b, f)
+		}
+		b.WriteString("},\n")
+	}
+	if len(blocks) > 0 {
+		b.WriteString("Blocks: map[string]schema.Block{\n")
+		for _, f := range blocks {
+			g.writeBlock(b, f)
+		}
+		b.WriteString("},\n")
+	}
+}
+
+// kind
+// the framework's names for a primitive: attribute, plan modifier and validator type
+type kind struct {
+	attribute string
+	modifier  string
+	pkg       string
+}
+
+var kinds = map[string]*kind{
+	"string":  {attribute: "StringAttribute", modifier: "String", pkg: "string"},
+	"integer": {attribute: "Int64Attribute", modifier: "Int64", pkg: "int64"},
+	"number":  {attribute: "Float64Attribute", modifier: "Float64", pkg: "float64"},
+	"boolean": {attribute: "BoolAttribute", modifier: "Bool", pkg: "bool"},
+	"list":    {attribute: "ListAttribute", modifier: "List", pkg: "list"},
+	"map":     {attribute: "MapAttribute", modifier: "Map", pkg: "map"},
+	"object":  {attribute: "SingleNestedBlock", modifier: "Object", pkg: "object"},
+}
+
+func (g *generator) writeAttribute(b *strings.Builder, f *field) {
+	def := g.resolve(f.property)
+	k := kinds[def.Type]
+	elementType := ""
+	switch {
+	case f.tags:
+		k = kinds["map"]
+		elementType = g.elementType("string")
+	case f.property.Type == "array":
+		k = kinds["list"]
+		item := "string"
+		if f.property.Items != nil {
+			item = g.resolve(&model.Property{Type: f.property.Items.Type, Ref: f.property.Items.Ref}).Type
+		}
+		elementType = g.elementType(item)
+	case k == nil:
+		k = kinds["string"]
+	}
+
+	b.WriteString(fmt.Sprintf("%q: schema.%s{\n", f.name, k.attribute))
+	if elementType != "" {
+		b.WriteString(fmt.Sprintf("ElementType: %s,\n", elementType))
+	}
+	g.writeCommon(b, f, k)
+	if len(def.Enum) > 0 && f.property.Type != "array" && !f.computed {
+		g.imports[frameworkPath+"schema/validator"] = true
+		g.imports[validatorsPath+"stringvalidator"] = true
+		values := make([]string, 0, len(def.Enum))
+		for _, v := range def.Enum {
+			values = append(values, fmt.Sprintf("%q", v))
+		}
+		b.WriteString(fmt.Sprintf("Validators: []validator.String{stringvalidator.OneOf(%s)},\n", strings.Join(values, ", ")))
+	}
+	b.WriteString("},\n")
+}
+
+// writeBlock
+// an input object, a list of them is a ListNestedBlock
+func (g *generator) writeBlock(b *strings.Builder, f *field) {
+	def := g.object(f.property)
+	name := strings.TrimPrefix(f.property.Ref, "#/definitions/")
+	if f.property.Items != nil {
+		name = strings.TrimPrefix(f.property.Items.Ref, "#/definitions/")
+	}
+	if g.visiting[name] {
+		b.WriteString(fmt.Sprintf("// %q: %s is recursive, Terraform schemas can't be\n", f.name, name))
+		return
+	}
+	g.visiting[name] = true
+	defer delete(g.visiting, name)
+
+	k := kinds["object"]
+	if f.property.Type == "array" {
+		k = &kind{attribute: "ListNestedBlock", modifier: "List", pkg: "list"}
+	}
+	b.WriteString(fmt.Sprintf("%q: schema.%s{\n", f.name, k.attribute))
+	if f.description != "" {
+		b.WriteString(fmt.Sprintf("Description: %q,\n", f.description))
+	}
+	if f.replace {
+		g.writeRequiresReplace(b, k)
+	}
+	if f.required {
+		g.imports[frameworkPath+"schema/validator"] = true
+		g.imports[validatorsPath+k.pkg+"validator"] = true
+		b.WriteString(fmt.Sprintf("Validators: []validator.%s{%svalidator.IsRequired()},\n", k.modifier, k.pkg))
+	}
+
+	fields := make([]*field, 0, len(def.Properties))
+	for _, propertyName := range sortedKeys(def.Properties) {
+		p := def.Properties[propertyName]
+		fields = append(fields, &field{
+			name:        snake(p.OriginalName(propertyName)),
+			property:    p,
+			description: description(p),
+			required:    contains(def.Required, propertyName),
+			computed:    f.computed,
+		})
+	}
+	if f.property.Type == "array" {
+		b.WriteString("NestedObject: schema.NestedBlockObject{\n")
+		g.writeObject(b, fields)
+		b.WriteString("},\n")
+	} else {
+		g.writeObject(b, fields)
+	}
+	b.WriteString("},\n")
+}
+
+// writeCommon
+// description, required/optional/computed, sensitive and plan modifiers
+func (g *generator) writeCommon(b *strings.Builder, f *field, k *kind) {
+	if f.description != "" {
+		b.WriteString(fmt.Sprintf("Description: %q,\n", f.description))
+	}
+	switch {
+	case f.computed:
+		b.WriteString("Computed: true,\n")
+	case f.required:
+		b.WriteString("Required: true,\n")
+	default:
+		b.WriteString("Optional: true,\n")
+	}
+	if f.property.WriteOnly || f.property.Sensitive {
+		b.WriteString("Sensitive: true,\n")
+	}
+	switch {
+	case f.computed:
+		g.imports[frameworkPath+"resource/schema/planmodifier"] = true
+		g.imports[frameworkPath+"resource/schema/"+k.pkg+"planmodifier"] = true
+		b.WriteString(fmt.Sprintf("PlanModifiers: []planmodifier.%s{%splanmodifier.UseStateForUnknown()},\n", k.modifier, k.pkg))
+	case f.replace:
+		g.writeRequiresReplace(b, k)
+	}
+}
+
+func (g *generator) writeRequiresReplace(b *strings.Builder, k *kind) {
+	g.imports[frameworkPath+"resource/schema/planmodifier"] = true
+	g.imports[frameworkPath+"resource/schema/"+k.pkg+"planmodifier"] = true
+	b.WriteString(fmt.Sprintf("PlanModifiers: []planmodifier.%s{%splanmodifier.RequiresReplace()},\n", k.modifier, k.pkg))
+}
+
+// elementType
+// the attr.Type of a list or map's elements
+func (g *generator) elementType(jsonType string) string {
+	g.imports[frameworkPath+"types"] = true
+	switch jsonType {
+	case "integer":
+		return "types.Int64Type"
+	case "number":
+		return "types.Float64Type"
+	case "boolean":
+		return "types.BoolType"
+	}
+	return "types.StringType"
+}
+
+// object
+// the input object definition of the property, or its items, nil when it isn't one
+func (g *generator) object(property *model.Property) *model.Property {
+	ref := property.Ref
+	if property.Type == "array" && property.Items != nil {
+		ref = property.Items.Ref
+	}
+	if ref == "" {
+		return nil
+	}
+	def := g.doc.Definitions[strings.TrimPrefix(ref, "#/definitions/")]
+	if def == nil || len(def.Enum) > 0 || len(def.Properties) == 0 {
+		return nil
+	}
+	return def
+}
+
+func (g *generator) resolve(property *model.Property) *model.Property {
+	if property.Ref == "" {
+		return property
+	}
+	if def := g.doc.Definitions[strings.TrimPrefix(property.Ref, "#/definitions/")]; def != nil {
+		return def
+	}
+	return property
+}
+
+// description
+// the schema's description, otherwise the GraphQL one
+func description(property *model.Property) string {
+	if property.Description != "" {
+		return property.Description
+	}
+	return strings.TrimSpace(property.GraphQLDescription)
+}
+
+// snake
+// accountId -> account_id, aiNotificationsChannel -> ai_notifications_channel
+func snake(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteRune('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// identifier
+// an exported Go identifier
+func identifier(s string) string {
+	if s == "" {
+		return "Resource"
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func sortedKeys(m map[string]*model.Property) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package terraform_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/hashicorp/terraform"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// goMod the provider module the schemas are built in, the framework versions they're generated for
+const goMod = `module provider
+
+go 1.21
+
+require (
+	github.com/hashicorp/terraform-plugin-framework v1.19.0
+	github.com/hashicorp/terraform-plugin-framework-validators v0.19.0
+)
+`
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"aiNotificationsChannel", "aiNotificationsDestination"} {
+		service, err := fixture.Service(name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = terraform.Generate(service, dir); err != nil {
+			t.Fatal(err)
+		}
+		// The destination has no update mutation, every attribute requires replacing it
+		file := strings.TrimPrefix(terraform.ResourceName(service), "newrelic_") + "_resource_schema.go"
+		fixture.GoldenFile(t, filepath.Join("testdata", file+".golden"), filepath.Join(dir, file))
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fixture.Build(t, dir)
+}
+
+func TestResourceName(t *testing.T) {
+	service, err := fixture.Service("aiNotificationsChannel", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := terraform.ResourceName(service); got != "newrelic_ai_notifications_channel" {
+		t.Errorf("got %s", got)
+	}
+}
@@ -0,0 +1,276 @@
+package jsonschema
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+Target-neutral JSON Schema of a Document's type graph, for request validation and API docs outside CloudFormation
+
+- Draft 2020-12, definitions under $defs, or OpenAPI 3.1 components.schemas (see openapi.go)
+- Properties and required lists use the original GraphQL argument and field names
+- CloudFormation-only keywords are dropped (insertionOrder, relationshipRef, handlers, tagging, ...),
+  readOnlyProperties and writeOnlyProperties become the readOnly and writeOnly annotations
+- The typeConfiguration's definitions (NerdGraph credentials) aren't part of the type graph, they're left out
+*/
+
+// Draft the $schema of the exported schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema the JSON Schema keywords the export uses
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	ReadOnly             bool               `json:"readOnly,omitempty"`
+	WriteOnly            bool               `json:"writeOnly,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"` // Draft-07, the template bundle
+	Const                interface{}        `json:"const,omitempty"`
+	PatternProperties    map[string]*Schema `json:"patternProperties,omitempty"`
+	MinProperties        int                `json:"minProperties,omitempty"`
+	MaxProperties        int                `json:"maxProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+}
+
+// Generate
+// write dir/<base name>.json, the draft 2020-12 schema
+func Generate(doc *model.Document, dir string) error {
+	return write(filepath.Join(dir, doc.BaseName()+".json"), New(doc))
+}
+
+// New
+// the resource as a draft 2020-12 schema, its definitions under $defs
+func New(doc *model.Document) *Schema {
+	c := &converter{doc: doc, refPrefix: "#/$defs/"}
+	root := c.resource()
+	root.Schema = Draft
+	root.Defs = c.definitions()
+	return root
+}
+
+// Title
+// the last part of TypeName, NewRelic::Observability::aiNotificationsChannel -> aiNotificationsChannel
+func Title(doc *model.Document) string {
+	parts := strings.Split(doc.TypeName, "::")
+	return parts[len(parts)-1]
+}
+
+// converter
+// model.Property to Schema, $refs pointing at refPrefix. In template mode (template.go) the names are CloudFormation's,
+// readOnlyProperties are left out and every property can be an intrinsic function
+type converter struct {
+	doc       *model.Document
+	refPrefix string
+	template  bool
+}
+
+// resource
+// the top-level properties
+func (c *converter) resource() *Schema {
+	readOnly := paths(c.doc.ReadOnlyProperties)
+	writeOnly := paths(c.doc.WriteOnlyProperties)
+	f := false
+	s := &Schema{
+		Title:                Title(c.doc),
+		Description:          description(c.doc.Description, c.doc.GraphQLDescription),
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: &f,
+	}
+	for name, property := range c.doc.Properties {
+		if c.template && readOnly[name] {
+			continue
+		}
+		p := c.schema(property)
+		p.ReadOnly = readOnly[name]
+		p.WriteOnly = p.WriteOnly || writeOnly[name]
+		s.Properties[c.name(name, property)] = c.intrinsic(p)
+	}
+	s.Required = c.required(c.doc.Required, c.doc.Properties)
+	return s
+}
+
+// definitions
+// the definitions in the type graph, all but the ones only the typeConfiguration uses
+func (c *converter) definitions() map[string]*Schema {
+	used := make(map[string]bool)
+	c.reach(c.doc.Properties, used)
+	configuration := make(map[string]bool)
+	if c.doc.TypeConfiguration != nil {
+		c.reach(c.doc.TypeConfiguration.Properties, configuration)
+	}
+	defs := make(map[string]*Schema)
+	for name, def := range c.doc.Definitions {
+		if configuration[name] && !used[name] {
+			continue
+		}
+		defs[name] = c.schema(def)
+	}
+	return defs
+}
+
+// reach
+// mark the definitions properties reference, directly or through other definitions
+func (c *converter) reach(properties map[string]*model.Property, seen map[string]bool) {
+	for _, property := range properties {
+		refs := []string{property.Ref}
+		items := append(append([]*model.Item{}, property.AnyOf...), property.OneOf...)
+		if property.Items != nil {
+			items = append(items, property.Items)
+		}
+		for len(items) > 0 {
+			item := items[0]
+			items = append(items[1:], item.AnyOf...)
+			refs = append(refs, item.Ref)
+		}
+		for _, ref := range refs {
+			name := strings.TrimPrefix(ref, "#/definitions/")
+			if ref == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if def := c.doc.Definitions[name]; def != nil {
+				c.reach(map[string]*model.Property{name: def}, seen)
+				c.reach(def.Properties, seen)
+			}
+		}
+	}
+}
+
+// schema
+// a property or definition, without the CloudFormation-only keywords
+func (c *converter) schema(property *model.Property) *Schema {
+	s := &Schema{
+		Ref:         c.ref(property.Ref),
+		Title:       property.Title,
+		Description: description(property.Description, property.GraphQLDescription),
+		Type:        property.Type,
+		Pattern:     property.Pattern,
+		Default:     property.Default,
+		WriteOnly:   property.WriteOnly,
+	}
+	if len(property.Enum) > 0 {
+		s.Enum = property.Enum
+	}
+	if property.Items != nil {
+		s.Items = c.intrinsic(c.item(property.Items))
+		s.UniqueItems = property.Items.UniqueItems
+	}
+	if len(property.Properties) > 0 {
+		s.Properties = make(map[string]*Schema, len(property.Properties))
+		for name, p := range property.Properties {
+			s.Properties[c.name(name, p)] = c.intrinsic(c.schema(p))
+		}
+		s.Required = c.required(property.Required, property.Properties)
+	}
+	if s.Type == "object" || len(s.Properties) > 0 {
+		s.AdditionalProperties = property.AdditionalProperties
+	}
+	for _, item := range property.AnyOf {
+		s.AnyOf = append(s.AnyOf, c.item(item))
+	}
+	for _, item := range property.OneOf {
+		s.OneOf = append(s.OneOf, c.item(item))
+	}
+	return s
+}
+
+// item
+// an array's items or a union member
+func (c *converter) item(item *model.Item) *Schema {
+	s := &Schema{Ref: c.ref(item.Ref), Type: item.Type}
+	for _, member := range item.AnyOf {
+		s.AnyOf = append(s.AnyOf, c.item(member))
+	}
+	return s
+}
+
+// ref
+// #/definitions/<name> -> <refPrefix><name>
+func (c *converter) ref(ref string) string {
+	if ref == "" {
+		return ""
+	}
+	return c.refPrefix + strings.TrimPrefix(ref, "#/definitions/")
+}
+
+// name
+// the property's GraphQL name, the CloudFormation one in template mode
+func (c *converter) name(name string, property *model.Property) string {
+	if c.template {
+		return name
+	}
+	return property.OriginalName(name)
+}
+
+// required
+// the CloudFormation names in required as the converter's names, sorted
+func (c *converter) required(names []string, properties map[string]*model.Property) []string {
+	if len(names) == 0 {
+		return nil
+	}
+	r := make([]string, 0, len(names))
+	for _, name := range names {
+		property := properties[name]
+		if property == nil {
+			property = &model.Property{}
+		}
+		r = append(r, c.name(name, property))
+	}
+	sort.Strings(r)
+	return r
+}
+
+// description
+// the schema's description, the GraphQL one when there's none
+func description(schema string, graphQL string) string {
+	if schema != "" {
+		return schema
+	}
+	return strings.TrimSpace(graphQL)
+}
+
+func paths(p []string) map[string]bool {
+	m := make(map[string]bool, len(p))
+	for _, path := range p {
+		m[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	return m
+}
+
+// write
+// v as indented JSON, creating the directory
+func write(file string, v interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("jsonschema: %w", err)
+	}
+	b, err := json.MarshalIndent(v, "", "   ")
+	if err != nil {
+		return fmt.Errorf("jsonschema: %s: %w", file, err)
+	}
+	if err = os.WriteFile(file, append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("jsonschema: %w", err)
+	}
+	return nil
+}
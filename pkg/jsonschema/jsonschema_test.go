@@ -0,0 +1,160 @@
+package jsonschema_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/jsonschema"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// document
+// one of the fixture's documents
+func document(t *testing.T, name string) *model.Document {
+	t.Helper()
+	service, err := fixture.Service(name, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return service.Document()
+}
+
+// decode
+// the schema as the generic JSON it's written as
+func decode(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]interface{})
+	if err = json.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// resolveRefs
+// fail for every $ref in node that doesn't point into root, the references found
+func resolveRefs(t *testing.T, root map[string]interface{}, node interface{}) int {
+	t.Helper()
+	found := 0
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if ref, ok := value.(string); key == "$ref" && ok {
+				found++
+				if !resolves(root, ref) {
+					t.Errorf("$ref %s doesn't resolve", ref)
+				}
+				continue
+			}
+			found += resolveRefs(t, root, value)
+		}
+	case []interface{}:
+		for _, value := range v {
+			found += resolveRefs(t, root, value)
+		}
+	}
+	return found
+}
+
+// resolves
+// whether the JSON pointer, #/..., points at an object in root
+func resolves(root map[string]interface{}, ref string) bool {
+	if !strings.HasPrefix(ref, "#/") {
+		return false
+	}
+	node := root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		next, ok := node[part].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		node = next
+	}
+	return true
+}
+
+func TestGenerateGolden(t *testing.T) {
+	doc := document(t, "aiNotificationsChannel")
+	for _, tt := range []struct {
+		name     string
+		generate func(doc *model.Document, dir string) error
+	}{
+		{"jsonschema", jsonschema.Generate},
+		{"openapi", jsonschema.GenerateOpenAPI},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := tt.generate(doc, dir); err != nil {
+				t.Fatal(err)
+			}
+			fixture.GoldenFile(t, filepath.Join("testdata", tt.name, doc.BaseName()+".json"), filepath.Join(dir, doc.BaseName()+".json"))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"aiNotificationsChannel", "aiNotificationsDestination"} {
+		t.Run(name, func(t *testing.T) {
+			doc := document(t, name)
+			doc.AddWriteOnlyProperty("accountId")
+			schema := jsonschema.New(doc)
+			if schema.Schema != jsonschema.Draft || schema.Title != name {
+				t.Errorf("$schema %s title %s", schema.Schema, schema.Title)
+			}
+			root := decode(t, schema)
+			if resolveRefs(t, root, root) == 0 {
+				t.Error("no $refs")
+			}
+			// GraphQL names, CloudFormation's annotations as JSON Schema's
+			if p := schema.Properties["guid"]; p == nil || !p.ReadOnly {
+				t.Errorf("guid %+v", p)
+			}
+			if p := schema.Properties["accountId"]; p == nil || !p.WriteOnly {
+				t.Errorf("accountId %+v", p)
+			}
+			if _, ok := schema.Defs[model.AccessDefinitionName]; ok {
+				t.Errorf("the typeConfiguration's %s is exported", model.AccessDefinitionName)
+			}
+			b, _ := json.Marshal(schema)
+			for _, keyword := range []string{"insertionOrder", "relationshipRef", "handlers", "tagging", "primaryIdentifier"} {
+				if strings.Contains(string(b), `"`+keyword+`"`) {
+					t.Errorf("has the CloudFormation keyword %s", keyword)
+				}
+			}
+		})
+	}
+}
+
+func TestNewOpenAPI(t *testing.T) {
+	doc := document(t, "aiNotificationsChannel")
+	api := jsonschema.NewOpenAPI(doc)
+	if api.OpenAPI != jsonschema.OpenAPIVersion || api.Info.Title != doc.TypeName {
+		t.Errorf("openapi %s info %+v", api.OpenAPI, api.Info)
+	}
+	root := decode(t, api)
+	if resolveRefs(t, root, root) == 0 {
+		t.Error("no $refs")
+	}
+	resource := api.Components.Schemas[jsonschema.ResourceSchemaName(doc)]
+	if resource == nil || resource.Properties["channel"] == nil || resource.Properties["channel"].Ref != "#/components/schemas/AiNotificationsChannelInput" {
+		t.Errorf("resource %+v", resource)
+	}
+}
+
+func TestResourceSchemaName(t *testing.T) {
+	doc := &model.Document{TypeName: "NewRelic::Observability::aiNotificationsChannel", Definitions: map[string]*model.Property{}}
+	if got := jsonschema.ResourceSchemaName(doc); got != "AiNotificationsChannel" {
+		t.Errorf("got %s", got)
+	}
+	// A GraphQL type has the name already
+	doc.Definitions["AiNotificationsChannel"] = &model.Property{Type: "object"}
+	if got := jsonschema.ResourceSchemaName(doc); got != "AiNotificationsChannelResource" {
+		t.Errorf("got %s", got)
+	}
+}
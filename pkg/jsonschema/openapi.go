@@ -0,0 +1,64 @@
+package jsonschema
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"path/filepath"
+	"strings"
+)
+
+// OpenAPIVersion the OpenAPI release the components are for, its schemas are draft 2020-12
+const OpenAPIVersion = "3.1.0"
+
+// OpenAPI an OpenAPI document with only components
+type OpenAPI struct {
+	OpenAPI    string     `json:"openapi"`
+	Info       Info       `json:"info"`
+	Components Components `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// GenerateOpenAPI
+// write dir/<base name>.json, the OpenAPI 3.1 components
+func GenerateOpenAPI(doc *model.Document, dir string) error {
+	return write(filepath.Join(dir, doc.BaseName()+".json"), NewOpenAPI(doc))
+}
+
+// NewOpenAPI
+// the definitions and the resource itself as components.schemas, the resource named after ResourceSchemaName
+func NewOpenAPI(doc *model.Document) *OpenAPI {
+	c := &converter{doc: doc, refPrefix: "#/components/schemas/"}
+	schemas := c.definitions()
+	schemas[ResourceSchemaName(doc)] = c.resource()
+	return &OpenAPI{
+		OpenAPI: OpenAPIVersion,
+		Info: Info{
+			Title:       doc.TypeName,
+			Description: description(doc.Description, doc.GraphQLDescription),
+			Version:     "1.0.0",
+		},
+		Components: Components{Schemas: schemas},
+	}
+}
+
+// ResourceSchemaName
+// Title with an uppercase first letter, Resource appended when a GraphQL type already has the name
+func ResourceSchemaName(doc *model.Document) string {
+	title := Title(doc)
+	if title == "" {
+		return "Resource"
+	}
+	name := strings.ToUpper(title[:1]) + title[1:]
+	if _, ok := doc.Definitions[name]; ok {
+		name += "Resource"
+	}
+	return name
+}
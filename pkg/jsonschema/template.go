@@ -0,0 +1,121 @@
+package jsonschema
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"path/filepath"
+	"sort"
+)
+
+// TemplateDraft the $schema of the template bundle, draft-07 is what editors' YAML and JSON language servers support best
+const TemplateDraft = "http://json-schema.org/draft-07/schema#"
+
+// TemplateFile the bundle's file name
+const TemplateFile = "newrelic-cloudformation.schema.json"
+
+// intrinsicDefinition a single key mapping: Ref, Condition or Fn::*
+const intrinsicDefinition = "Intrinsic"
+
+// GenerateTemplate
+// write dir/TemplateFile, the bundle for all the documents
+func GenerateTemplate(docs []*model.Document, dir string) error {
+	return write(filepath.Join(dir, TemplateFile), NewTemplate(docs))
+}
+
+// NewTemplate
+// a CloudFormation template schema for editor completion and validation of the documents' types:
+// Resources.* picks the resource definition by Type and its Properties are checked against it. Each type's definitions
+// are nested under it, definitions/<Title>/definitions/<name>, the same GraphQL type can differ between documents
+func NewTemplate(docs []*model.Document) *Schema {
+	f := false
+	definitions := map[string]*Schema{
+		intrinsicDefinition: {
+			Description: "An intrinsic function, e.g. {\"Ref\": \"Parameter\"} or {\"Fn::GetAtt\": [\"Resource\", \"Attribute\"]}",
+			Type:        "object",
+			PatternProperties: map[string]*Schema{
+				"^(Ref|Condition|Fn::[A-Za-z0-9]+)$": {},
+			},
+			AdditionalProperties: &f,
+			MinProperties:        1,
+			MaxProperties:        1,
+		},
+	}
+
+	sorted := append([]*model.Document{}, docs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].TypeName < sorted[j].TypeName })
+	types := make([]string, 0, len(sorted))
+	conditions := make([]*Schema, 0, len(sorted))
+	for _, doc := range sorted {
+		title := Title(doc)
+		c := &converter{doc: doc, refPrefix: "#/definitions/" + title + "/definitions/", template: true}
+		properties := c.resource()
+		properties.Definitions = c.definitions()
+		definitions[title] = properties
+
+		types = append(types, doc.TypeName)
+		then := &Schema{Properties: map[string]*Schema{"Properties": {Ref: "#/definitions/" + title}}}
+		if len(doc.Required) > 0 {
+			then.Required = []string{"Properties"}
+		}
+		conditions = append(conditions, &Schema{
+			If:   &Schema{Properties: map[string]*Schema{"Type": {Const: doc.TypeName}}, Required: []string{"Type"}},
+			Then: then,
+		})
+	}
+
+	definitions["Resource"] = &Schema{
+		Type:     "object",
+		Required: []string{"Type"},
+		Properties: map[string]*Schema{
+			// Any type goes, the generated ones are offered for completion
+			"Type":                {Description: "The resource type", AnyOf: []*Schema{{Enum: types}, {Type: "string"}}},
+			"Properties":          {Type: "object"},
+			"Condition":           {Type: "string"},
+			"DependsOn":           {AnyOf: []*Schema{{Type: "string"}, {Type: "array", Items: &Schema{Type: "string"}}}},
+			"Metadata":            {Type: "object"},
+			"DeletionPolicy":      {Enum: []string{"Delete", "Retain", "RetainExceptOnCreate", "Snapshot"}},
+			"UpdateReplacePolicy": {Enum: []string{"Delete", "Retain", "Snapshot"}},
+			"CreationPolicy":      {Type: "object"},
+			"UpdatePolicy":        {Type: "object"},
+		},
+		AdditionalProperties: &f,
+		AllOf:                conditions,
+	}
+
+	return &Schema{
+		Schema:      TemplateDraft,
+		Title:       "CloudFormation template with New Relic resources",
+		Description: "Generated by gqlparser from the NerdGraph schema",
+		Type:        "object",
+		Required:    []string{"Resources"},
+		Properties: map[string]*Schema{
+			"AWSTemplateFormatVersion": {Enum: []string{"2010-09-09"}},
+			"Description":              {Type: "string"},
+			"Metadata":                 {Type: "object"},
+			"Parameters":               {Type: "object"},
+			"Rules":                    {Type: "object"},
+			"Mappings":                 {Type: "object"},
+			"Conditions":               {Type: "object"},
+			"Transform":                {AnyOf: []*Schema{{Type: "string"}, {Type: "array", Items: &Schema{Type: "string"}}}},
+			"Resources": {
+				Type:                 "object",
+				MinProperties:        1,
+				PatternProperties:    map[string]*Schema{"^[A-Za-z0-9]+$": {Ref: "#/definitions/Resource"}},
+				AdditionalProperties: &f,
+			},
+			"Outputs": {Type: "object"},
+		},
+		AdditionalProperties: &f,
+		Definitions:          definitions,
+	}
+}
+
+// intrinsic
+// in template mode, the schema or an intrinsic function standing in for the value
+func (c *converter) intrinsic(s *Schema) *Schema {
+	if !c.template {
+		return s
+	}
+	description := s.Description
+	s.Description = ""
+	return &Schema{Description: description, AnyOf: []*Schema{s, {Ref: "#/definitions/" + intrinsicDefinition}}}
+}
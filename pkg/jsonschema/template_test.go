@@ -0,0 +1,95 @@
+package jsonschema_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/jsonschema"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// templateDocuments
+// the fixture's documents, the destination first: the bundle sorts them
+func templateDocuments(t *testing.T) []*model.Document {
+	t.Helper()
+	return []*model.Document{document(t, "aiNotificationsDestination"), document(t, "aiNotificationsChannel")}
+}
+
+func TestGenerateTemplateGolden(t *testing.T) {
+	dir := t.TempDir()
+	if err := jsonschema.GenerateTemplate(templateDocuments(t), dir); err != nil {
+		t.Fatal(err)
+	}
+	fixture.GoldenFile(t, filepath.Join("testdata", "ide", jsonschema.TemplateFile), filepath.Join(dir, jsonschema.TemplateFile))
+}
+
+func TestNewTemplate(t *testing.T) {
+	// A type nothing's required in, its Properties can be left out
+	optional := &model.Document{}
+	if err := json.Unmarshal([]byte(`{"typeName": "NewRelic::Observability::optional", "properties": {"Name": {"type": "string"}}, "handlers": {}}`), optional); err != nil {
+		t.Fatal(err)
+	}
+	docs := append(templateDocuments(t), optional)
+	template := jsonschema.NewTemplate(docs)
+	if template.Schema != jsonschema.TemplateDraft {
+		t.Errorf("$schema %s", template.Schema)
+	}
+	root := decode(t, template)
+	if resolveRefs(t, root, root) == 0 {
+		t.Error("no $refs")
+	}
+
+	for _, doc := range docs {
+		title := jsonschema.Title(doc)
+		properties := template.Definitions[title]
+		if properties == nil {
+			t.Errorf("no definition for %s", title)
+			continue
+		}
+		// Templates can't set what NerdGraph assigns
+		for _, path := range doc.ReadOnlyProperties {
+			name := path[len("/properties/"):]
+			if _, ok := properties.Properties[name]; ok {
+				t.Errorf("%s: read-only %s", title, name)
+			}
+		}
+		// Every property can be an intrinsic function
+		for name, p := range properties.Properties {
+			if len(p.AnyOf) != 2 || p.AnyOf[1].Ref != "#/definitions/Intrinsic" {
+				t.Errorf("%s.%s isn't an intrinsic function: %+v", title, name, p)
+			}
+		}
+	}
+	if _, ok := template.Definitions["aiNotificationsChannel"].Properties["AccountId"]; !ok {
+		t.Error("no AccountId")
+	}
+
+	// Resources.* picks the definition by Type
+	resource := template.Definitions["Resource"]
+	if resource == nil || len(resource.AllOf) != len(docs) {
+		t.Fatalf("Resource %+v", resource)
+	}
+	required := map[string]bool{}
+	for _, condition := range resource.AllOf {
+		typeName, _ := condition.If.Properties["Type"].Const.(string)
+		ref := condition.Then.Properties["Properties"].Ref
+		if ref != "#/definitions/"+jsonschema.Title(&model.Document{TypeName: typeName}) {
+			t.Errorf("%s: Properties %s", typeName, ref)
+		}
+		required[typeName] = len(condition.Then.Required) == 1 && condition.Then.Required[0] == "Properties"
+	}
+	for typeName, want := range map[string]bool{
+		"NewRelic::Observability::aiNotificationsChannel":     true,
+		"NewRelic::Observability::aiNotificationsDestination": true,
+		"NewRelic::Observability::optional":                   false,
+	} {
+		if required[typeName] != want {
+			t.Errorf("%s: Properties required %t, want %t", typeName, required[typeName], want)
+		}
+	}
+	// Other types go too
+	if types := resource.Properties["Type"].AnyOf; len(types) != 2 || len(types[0].Enum) != len(docs) || types[1].Type != "string" {
+		t.Errorf("Type %+v", resource.Properties["Type"])
+	}
+}
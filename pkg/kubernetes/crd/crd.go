@@ -0,0 +1,326 @@
+package crd
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+Kubernetes CustomResourceDefinition for a Document, so controllers (e.g. Crossplane) can manage the same NerdGraph
+resources. The openAPIV3Schema is structural:
+
+- spec is the writable properties, status the readOnlyProperties
+- Every node has a type, $refs are inlined; a recursive definition stops with x-kubernetes-preserve-unknown-fields
+- Unions list their members' properties, and oneOf their required ones; members without required properties can't be
+  told apart, the union preserves unknown fields instead
+- Enums are enum, createOnlyProperties can't change once set (x-kubernetes-validations self == oldSelf)
+- No additionalProperties: false, the API server prunes unknown fields
+*/
+
+// APIVersion of the custom resources
+const APIVersion = "v1alpha1"
+
+// CustomResourceDefinition the apiextensions.k8s.io/v1 resource, just what's generated
+type CustomResourceDefinition struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       Spec     `yaml:"spec"`
+}
+
+type Metadata struct {
+	Name        string            `yaml:"name"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+type Spec struct {
+	Group    string     `yaml:"group"`
+	Names    Names      `yaml:"names"`
+	Scope    string     `yaml:"scope"`
+	Versions []*Version `yaml:"versions"`
+}
+
+type Names struct {
+	Kind     string `yaml:"kind"`
+	ListKind string `yaml:"listKind"`
+	Plural   string `yaml:"plural"`
+	Singular string `yaml:"singular"`
+}
+
+type Version struct {
+	Name         string                 `yaml:"name"`
+	Served       bool                   `yaml:"served"`
+	Storage      bool                   `yaml:"storage"`
+	Subresources map[string]interface{} `yaml:"subresources,omitempty"`
+	Schema       struct {
+		OpenAPIV3Schema *Schema `yaml:"openAPIV3Schema"`
+	} `yaml:"schema"`
+}
+
+// Schema the structural subset of JSONSchemaProps
+type Schema struct {
+	Type                  string             `yaml:"type,omitempty"`
+	Description           string             `yaml:"description,omitempty"`
+	Enum                  []string           `yaml:"enum,omitempty"`
+	Pattern               string             `yaml:"pattern,omitempty"`
+	Default               interface{}        `yaml:"default,omitempty"`
+	Items                 *Schema            `yaml:"items,omitempty"`
+	Properties            map[string]*Schema `yaml:"properties,omitempty"`
+	Required              []string           `yaml:"required,omitempty"`
+	OneOf                 []*Schema          `yaml:"oneOf,omitempty"`
+	PreserveUnknownFields bool               `yaml:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	Validations           []*Validation      `yaml:"x-kubernetes-validations,omitempty"`
+}
+
+// Validation a CEL rule
+type Validation struct {
+	Rule    string `yaml:"rule"`
+	Message string `yaml:"message,omitempty"`
+}
+
+// Generate
+// write dir/<group>_<plural>.yaml
+func Generate(doc *model.Document, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("crd: %w", err)
+	}
+	crd := New(doc)
+	b, err := yaml.Marshal(crd)
+	if err != nil {
+		return fmt.Errorf("crd: %s: %w", doc.TypeName, err)
+	}
+	file := filepath.Join(dir, crd.Spec.Group+"_"+crd.Spec.Names.Plural+".yaml")
+	if err = os.WriteFile(file, b, 0644); err != nil {
+		return fmt.Errorf("crd: %w", err)
+	}
+	return nil
+}
+
+// New
+// the CustomResourceDefinition, NewRelic::Observability::aiNotificationsChannel is AiNotificationsChannel in
+// observability.newrelic.com
+func New(doc *model.Document) *CustomResourceDefinition {
+	parts := strings.Split(doc.TypeName, "::")
+	name := parts[len(parts)-1]
+	kind := strings.ToUpper(name[:1]) + name[1:]
+	group := make([]string, 0, len(parts)-1)
+	for i := len(parts) - 2; i >= 0; i-- {
+		group = append(group, strings.ToLower(parts[i]))
+	}
+	group = append(group, "com")
+	singular := strings.ToLower(kind)
+
+	crd := &CustomResourceDefinition{
+		APIVersion: "apiextensions.k8s.io/v1",
+		Kind:       "CustomResourceDefinition",
+		Spec: Spec{
+			Group: strings.Join(group, "."),
+			Names: Names{Kind: kind, ListKind: kind + "List", Plural: plural(singular), Singular: singular},
+			Scope: "Namespaced",
+		},
+	}
+	crd.Metadata = Metadata{Name: crd.Spec.Names.Plural + "." + crd.Spec.Group, Annotations: map[string]string{"newrelic.com/cloudformation-type": doc.TypeName}}
+
+	g := &generator{doc: doc, visiting: make(map[string]bool)}
+	version := &Version{Name: APIVersion, Served: true, Storage: true, Subresources: map[string]interface{}{"status": map[string]interface{}{}}}
+	version.Schema.OpenAPIV3Schema = &Schema{
+		Type:        "object",
+		Description: description(doc.Description, doc.GraphQLDescription),
+		Properties: map[string]*Schema{
+			"apiVersion": {Type: "string"},
+			"kind":       {Type: "string"},
+			"metadata":   {Type: "object"},
+			"spec":       g.spec(),
+			"status":     g.status(),
+		},
+		Required: []string{"spec"},
+	}
+	crd.Spec.Versions = []*Version{version}
+	return crd
+}
+
+type generator struct {
+	doc      *model.Document
+	visiting map[string]bool
+}
+
+// spec
+// the writable properties
+func (g *generator) spec() *Schema {
+	readOnly := paths(g.doc.ReadOnlyProperties)
+	createOnly := paths(g.doc.CreateOnlyProperties)
+	immutable := g.doc.Handlers["update"] == nil
+	spec := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, name := range sortedKeys(g.doc.Properties) {
+		if readOnly[name] {
+			continue
+		}
+		property := g.doc.Properties[name]
+		field := property.OriginalName(name)
+		s := g.schema(property)
+		if createOnly[name] || immutable {
+			s.Validations = append(s.Validations, &Validation{Rule: "self == oldSelf", Message: field + " is immutable"})
+		}
+		spec.Properties[field] = s
+		if contains(g.doc.Required, name) {
+			spec.Required = append(spec.Required, field)
+		}
+	}
+	return spec
+}
+
+// status
+// the readOnlyProperties, what NerdGraph assigns
+func (g *generator) status() *Schema {
+	status := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for name := range paths(g.doc.ReadOnlyProperties) {
+		if property := g.doc.Properties[name]; property != nil {
+			status.Properties[property.OriginalName(name)] = g.schema(property)
+		}
+	}
+	return status
+}
+
+// schema
+// the property with its definitions inlined
+func (g *generator) schema(property *model.Property) *Schema {
+	if property.Type == "array" {
+		s := &Schema{Type: "array", Description: description(property.Description, property.GraphQLDescription)}
+		if property.Items != nil {
+			s.Items = g.schema(&model.Property{Type: property.Items.Type, Ref: property.Items.Ref, AnyOf: property.Items.AnyOf})
+		} else {
+			s.Items = &Schema{PreserveUnknownFields: true, Type: "object"}
+		}
+		return s
+	}
+
+	def := property
+	name := strings.TrimPrefix(property.Ref, "#/definitions/")
+	if property.Ref != "" {
+		if def = g.doc.Definitions[name]; def == nil {
+			return &Schema{Type: "object", PreserveUnknownFields: true}
+		}
+		if g.visiting[name] {
+			return &Schema{Type: "object", Description: name + " (recursive)", PreserveUnknownFields: true}
+		}
+		g.visiting[name] = true
+		defer delete(g.visiting, name)
+	}
+	s := &Schema{Description: description(property.Description, property.GraphQLDescription), Pattern: property.Pattern, Default: property.Default}
+	if s.Description == "" {
+		s.Description = description(def.Description, def.GraphQLDescription)
+	}
+	if s.Pattern == "" {
+		s.Pattern = def.Pattern
+	}
+
+	members := append(append([]*model.Item{}, def.AnyOf...), def.OneOf...)
+	switch {
+	case len(def.Enum) > 0:
+		s.Type = "string"
+		s.Enum = def.Enum
+	case len(members) > 0:
+		g.union(s, members)
+	case len(def.Properties) > 0:
+		s.Type = "object"
+		s.Properties = make(map[string]*Schema)
+		for _, field := range sortedKeys(def.Properties) {
+			p := def.Properties[field]
+			s.Properties[p.OriginalName(field)] = g.schema(p)
+			if contains(def.Required, field) {
+				s.Required = append(s.Required, p.OriginalName(field))
+			}
+		}
+	case def.Type == "object" || def.Type == "":
+		s.Type = "object"
+		s.PreserveUnknownFields = true
+	default:
+		s.Type = def.Type
+	}
+	return s
+}
+
+// union
+// the members' properties together, oneOf tells them apart by what they require
+func (g *generator) union(s *Schema, members []*model.Item) {
+	s.Type = "object"
+	s.Properties = make(map[string]*Schema)
+	oneOf := make([]*Schema, 0, len(members))
+	names := make([]string, 0, len(members))
+	for _, member := range members {
+		name := strings.TrimPrefix(member.Ref, "#/definitions/")
+		names = append(names, name)
+		m := g.schema(&model.Property{Ref: member.Ref, Type: member.Type})
+		for field, p := range m.Properties {
+			if _, ok := s.Properties[field]; !ok {
+				s.Properties[field] = p
+			}
+		}
+		if len(m.Required) == 0 {
+			oneOf = nil
+		} else if oneOf != nil {
+			oneOf = append(oneOf, &Schema{Required: m.Required})
+		}
+	}
+	if s.Description == "" {
+		s.Description = "One of " + strings.Join(names, ", ")
+	}
+	if len(oneOf) == len(members) {
+		s.OneOf = oneOf
+		return
+	}
+	s.Properties = nil
+	s.PreserveUnknownFields = true
+}
+
+// plural
+// English plural of the lowercase kind
+func plural(s string) string {
+	switch {
+	case strings.HasSuffix(s, "s"), strings.HasSuffix(s, "x"), strings.HasSuffix(s, "ch"), strings.HasSuffix(s, "sh"):
+		return s + "es"
+	case strings.HasSuffix(s, "y") && len(s) > 1 && !strings.ContainsAny(s[len(s)-2:len(s)-1], "aeiou"):
+		return s[:len(s)-1] + "ies"
+	}
+	return s + "s"
+}
+
+// description
+// the schema's description, the GraphQL one when there's none
+func description(schema string, graphQL string) string {
+	if schema != "" {
+		return schema
+	}
+	return strings.TrimSpace(graphQL)
+}
+
+func paths(p []string) map[string]bool {
+	m := make(map[string]bool, len(p))
+	for _, path := range p {
+		m[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	return m
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]*model.Property) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package crd_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/kubernetes/crd"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGolden(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"aiNotificationsChannel", "aiNotificationsDestination"} {
+		service, err := fixture.Service(name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = crd.Generate(service.Document(), dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fixture.GoldenDir(t, "testdata", dir, "*.yaml")
+}
+
+// structural
+// the API server's structural schema rules: every node has a type, or preserves unknown fields
+func structural(t *testing.T, path string, s *crd.Schema) {
+	t.Helper()
+	if s.Type == "" && !s.PreserveUnknownFields {
+		t.Errorf("%s has no type", path)
+	}
+	if s.Type == "array" && s.Items == nil {
+		t.Errorf("%s has no items", path)
+	}
+	if s.Items != nil {
+		structural(t, path+"[]", s.Items)
+	}
+	for name, p := range s.Properties {
+		structural(t, path+"."+name, p)
+	}
+}
+
+func TestNew(t *testing.T) {
+	const schema = `{
+   "typeName": "NewRelic::Observability::policy",
+   "definitions": {
+      "Condition": {"type": "object", "properties": {"Name": {"type": "string"}, "Conditions": {"type": "array", "items": {"$ref": "#/definitions/Condition"}}}},
+      "Email": {"type": "object", "properties": {"Address": {"type": "string"}}, "required": ["Address"]},
+      "Slack": {"type": "object", "properties": {"Channel": {"type": "string"}}, "required": ["Channel"]},
+      "Target": {"oneOf": [{"$ref": "#/definitions/Email"}, {"$ref": "#/definitions/Slack"}]}
+   },
+   "properties": {
+      "Guid": {"type": "string"},
+      "Region": {"type": "string"},
+      "Condition": {"$ref": "#/definitions/Condition"},
+      "Target": {"$ref": "#/definitions/Target"}
+   },
+   "readOnlyProperties": ["/properties/Guid"],
+   "createOnlyProperties": ["/properties/Region"],
+   "handlers": {"update": {"permissions": []}}
+}`
+	doc := &model.Document{}
+	if err := json.Unmarshal([]byte(schema), doc); err != nil {
+		t.Fatal(err)
+	}
+	c := crd.New(doc)
+	if c.Metadata.Name != "policies.observability.newrelic.com" || c.Spec.Names.Kind != "Policy" || c.Spec.Names.ListKind != "PolicyList" {
+		t.Errorf("names %+v %+v", c.Metadata, c.Spec.Names)
+	}
+	root := c.Spec.Versions[0].Schema.OpenAPIV3Schema
+	structural(t, "openAPIV3Schema", root)
+	// The fields are the GraphQL names
+	spec, status := root.Properties["spec"], root.Properties["status"]
+	if spec.Properties["guid"] != nil || status.Properties["guid"] == nil {
+		t.Errorf("Guid is read-only, it's status")
+	}
+	if v := spec.Properties["region"].Validations; len(v) != 1 || v[0].Rule != "self == oldSelf" {
+		t.Errorf("Region is create-only, validations %v", v)
+	}
+	if v := spec.Properties["condition"].Validations; len(v) != 0 {
+		t.Errorf("Condition is updatable, validations %v", v)
+	}
+	// Recursion stops preserving unknown fields
+	nested := spec.Properties["condition"].Properties["conditions"].Items
+	if !nested.PreserveUnknownFields || !strings.Contains(nested.Description, "recursive") {
+		t.Errorf("recursive Condition %+v", nested)
+	}
+	// The union's members are told apart by what they require
+	target := spec.Properties["target"]
+	if len(target.OneOf) != 2 || target.Properties["address"] == nil || target.Properties["channel"] == nil {
+		t.Errorf("union %+v", target)
+	}
+}
@@ -0,0 +1,335 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+/*
+NerdGraph client for the resource handlers at runtime.
+
+- Authenticates with the API key from the type configuration (model.AccessDefinitionName)
+- Retries network failures, 5xx and rate limited requests with exponential backoff, honouring Retry-After up to
+  MaxBackoff. A wait past the context's deadline fails with Throttling instead. Mutations
+  are only retried when NerdGraph can't have run them: rate limited, or failed before the request was sent. A timeout
+  or a 5xx after that could have created the resource already
+- Pages through list queries with nextCursor
+- Maps NerdGraph errors onto CloudFormation HandlerErrorCodes (see errors.go)
+
+Endpoint and HTTPClient can be pointed at a local server, e.g. httptest or the mock-server subcommand. The package only
+uses the standard library, generated handlers carry a copy of it.
+*/
+
+const (
+	DefaultMaxRetries = 4
+	DefaultMinBackoff = 500 * time.Millisecond
+	DefaultMaxBackoff = 20 * time.Second
+	DefaultTimeout    = 30 * time.Second
+)
+
+// NerdGraph endpoints
+const (
+	EndpointUS = "US"
+	EndpointEU = "EU"
+)
+
+var endpointURLs = map[string]string{
+	EndpointUS: "https://api.newrelic.com/graphql",
+	EndpointEU: "https://api.eu.newrelic.com/graphql",
+}
+
+// EndpointURL
+// the NerdGraph URL for US, EU, or the custom URL itself
+func EndpointURL(endpoint string) string {
+	if url, ok := endpointURLs[strings.ToUpper(endpoint)]; ok {
+		return url
+	}
+	if endpoint == "" {
+		return endpointURLs[EndpointUS]
+	}
+	return endpoint
+}
+
+// Access the NewRelicAccess type configuration
+type Access struct {
+	ApiKey   string `json:"ApiKey"`
+	Endpoint string `json:"Endpoint,omitempty"` // US | EU | URL
+}
+
+type Client struct {
+	Endpoint   string
+	ApiKey     string
+	HTTPClient *http.Client
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	sleep      func(ctx context.Context, d time.Duration) error
+}
+
+// GraphQLError an entry of the response's errors
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []GraphQLError         `json:"errors"`
+}
+
+// New
+// a client for the type configuration's endpoint, US when it's not set
+func New(access Access) (*Client, error) {
+	if access.ApiKey == "" {
+		return nil, &Error{Code: InvalidCredentials, Message: "nerdgraph: no API key in the type configuration"}
+	}
+	return &Client{
+		Endpoint:   EndpointURL(access.Endpoint),
+		ApiKey:     access.ApiKey,
+		HTTPClient: &http.Client{Timeout: DefaultTimeout},
+		MaxRetries: DefaultMaxRetries,
+		MinBackoff: DefaultMinBackoff,
+		MaxBackoff: DefaultMaxBackoff,
+		sleep:      sleep,
+	}, nil
+}
+
+// Execute
+// POST the document, retrying what's retryable. GraphQL errors are returned as an *Error along with whatever data came back
+func (c *Client) Execute(ctx context.Context, document string, variables map[string]interface{}) (map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]interface{}{"query": document, "variables": variables})
+	if err != nil {
+		return nil, &Error{Code: InvalidRequest, Message: fmt.Sprintf("nerdgraph: %v", err)}
+	}
+	mutation := isMutation(document)
+	for attempt := 0; ; attempt++ {
+		data, retryAfter, err := c.post(ctx, body)
+		e, ok := err.(*Error)
+		if err == nil || !ok || !e.Retryable || attempt >= c.MaxRetries {
+			return data, err
+		}
+		if mutation && e.sent && e.Code != Throttling {
+			return data, err
+		}
+		d := c.backoff(attempt, retryAfter)
+		// Waiting past the handler's deadline would only time it out, CloudFormation can retry a Throttling failure
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+			return data, &Error{Code: Throttling, Message: fmt.Sprintf("nerdgraph: retry in %v is past the deadline: %s", d, e.Message), StatusCode: e.StatusCode, Errors: e.Errors, sent: e.sent}
+		}
+		wait := sleep
+		if c.sleep != nil {
+			wait = c.sleep
+		}
+		if err = wait(ctx, d); err != nil {
+			return nil, &Error{Code: NetworkFailure, Message: fmt.Sprintf("nerdgraph: %v", err)}
+		}
+	}
+}
+
+// post
+// a single attempt, retryAfter is the server's Retry-After when it sent one
+func (c *Client) post(ctx context.Context, body []byte) (map[string]interface{}, time.Duration, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, &Error{Code: InvalidRequest, Message: fmt.Sprintf("nerdgraph: %v", err)}
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("API-Key", c.ApiKey)
+	// Once any of the request is written the server may act on it. The transport writes on its own goroutine
+	var sent atomic.Bool
+	request = request.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		WroteHeaders: func() { sent.Store(true) },
+	}))
+	resp, err := c.httpClient().Do(request)
+	if err != nil {
+		return nil, 0, &Error{Code: NetworkFailure, Message: fmt.Sprintf("nerdgraph: %v", err), Retryable: ctx.Err() == nil, sent: sent.Load()}
+	}
+	defer resp.Body.Close()
+	retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, retryAfter, &Error{Code: NetworkFailure, Message: fmt.Sprintf("nerdgraph: %v", err), Retryable: true, sent: true}
+	}
+
+	result := response{}
+	if err = json.Unmarshal(b, &result); err != nil || resp.StatusCode != http.StatusOK && len(result.Errors) == 0 {
+		if resp.StatusCode == http.StatusOK {
+			return nil, retryAfter, &Error{Code: ServiceInternalError, Message: fmt.Sprintf("nerdgraph: invalid response: %v", err), sent: true}
+		}
+		e := statusError(resp.StatusCode, string(b))
+		e.sent = true
+		return nil, retryAfter, e
+	}
+	if len(result.Errors) > 0 {
+		e := graphQLErrors(result.Errors)
+		e.StatusCode = resp.StatusCode
+		e.sent = true
+		return result.Data, retryAfter, e
+	}
+	return result.Data, retryAfter, nil
+}
+
+// isMutation
+// whether the document's operation is a mutation, only the first operation counts
+func isMutation(document string) bool {
+	for _, line := range strings.Split(document, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rest, found := strings.CutPrefix(line, "mutation")
+		return found && (rest == "" || strings.IndexAny(rest[:1], " \t({") == 0)
+	}
+	return false
+}
+
+// Mutate
+// execute the mutation, returning the payload's entity, or the payload when entityField is "". The payload's errors
+// field (errorsField) is an *Error too, and so is a null payload or entity: the mutation may not have run
+func (c *Client) Mutate(ctx context.Context, document string, variables map[string]interface{}, operation string, entityField string, errorsField string) (map[string]interface{}, error) {
+	data, err := c.Execute(ctx, document, variables)
+	if err != nil {
+		return nil, err
+	}
+	payload, _ := data[operation].(map[string]interface{})
+	if payload == nil {
+		return nil, &Error{Code: ServiceInternalError, Message: fmt.Sprintf("nerdgraph: %s returned no payload", operation), sent: true}
+	}
+	if errorsField != "" {
+		if e := PayloadError(operation, payload[errorsField]); e != nil {
+			return nil, e
+		}
+	}
+	if entityField == "" {
+		return payload, nil
+	}
+	entity, _ := payload[entityField].(map[string]interface{})
+	if entity == nil {
+		return nil, &Error{Code: ServiceInternalError, Message: fmt.Sprintf("nerdgraph: %s returned no %s", operation, entityField), sent: true}
+	}
+	return entity, nil
+}
+
+// Page where a list query's entities and cursor are
+type Page struct {
+	Path            []string // Fields down to the list, e.g. actor, account, aiNotifications, channels
+	EntitiesField   string   // The list under Path, "" when Path ends at the list
+	CursorArgument  string   // Variable taking the cursor, "" when the query isn't paginated
+	NextCursorField string
+}
+
+// Paginate
+// execute the query page after page, calling fn with each page's entities until fn returns false or there's no nextCursor
+func (c *Client) Paginate(ctx context.Context, document string, variables map[string]interface{}, page Page, fn func(entities []map[string]interface{}) bool) error {
+	v := make(map[string]interface{}, len(variables)+1)
+	for k, e := range variables {
+		v[k] = e
+	}
+	seen := make(map[string]bool)
+	for {
+		data, err := c.Execute(ctx, document, v)
+		if err != nil {
+			return err
+		}
+		entities, cursor := page.entities(data)
+		if !fn(entities) || cursor == "" || page.CursorArgument == "" {
+			return nil
+		}
+		// A cursor NerdGraph already returned would page forever
+		if seen[cursor] {
+			return &Error{Code: ServiceInternalError, Message: fmt.Sprintf("nerdgraph: repeated cursor %s", cursor)}
+		}
+		seen[cursor] = true
+		v[page.CursorArgument] = cursor
+	}
+}
+
+func (p Page) entities(data map[string]interface{}) ([]map[string]interface{}, string) {
+	var node interface{} = data
+	for _, field := range p.Path {
+		m, _ := node.(map[string]interface{})
+		node = m[field]
+	}
+	cursor := ""
+	if p.EntitiesField != "" {
+		m, _ := node.(map[string]interface{})
+		node = m[p.EntitiesField]
+		if p.NextCursorField != "" {
+			cursor, _ = m[p.NextCursorField].(string)
+		}
+	}
+	list, _ := node.([]interface{})
+	entities := make([]map[string]interface{}, 0, len(list))
+	for _, e := range list {
+		if entity, ok := e.(map[string]interface{}); ok {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, cursor
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// backoff
+// MinBackoff doubled per attempt, capped at MaxBackoff, plus up to 50% jitter. Retry-After wins when it's longer, up to
+// MaxBackoff: the server doesn't get to park a handler
+func (c *Client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	d := c.MinBackoff << attempt
+	if d <= 0 || d > c.MaxBackoff {
+		d = c.MaxBackoff
+	}
+	if d > 0 {
+		d += time.Duration(rand.Int63n(int64(d)/2 + 1))
+	}
+	if retryAfter > c.MaxBackoff {
+		retryAfter = c.MaxBackoff
+	}
+	if retryAfter > d {
+		return retryAfter
+	}
+	return d
+}
+
+// parseRetryAfter
+// Retry-After in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
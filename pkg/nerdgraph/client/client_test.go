@@ -0,0 +1,437 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	query    = "query Channels($cursor: String) {\n  actor {\n    channels(cursor: $cursor) {\n      entities {\n        id\n      }\n      nextCursor\n    }\n  }\n}\n"
+	mutation = "mutation AiNotificationsCreateChannel($name: String!) {\n  aiNotificationsCreateChannel(name: $name) {\n    channel {\n      id\n    }\n  }\n}\n"
+)
+
+// newClient
+// a client for the server that records its waits instead of sleeping
+func newClient(t *testing.T, url string) (*Client, *[]time.Duration) {
+	t.Helper()
+	c, err := New(Access{ApiKey: "key", Endpoint: url})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waits := make([]time.Duration, 0)
+	c.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return c, &waits
+}
+
+// respond
+// a handler answering each request with the next status and body, the last one repeats
+func respond(t *testing.T, attempts *int32, responses ...func(w http.ResponseWriter)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("API-Key") != "key" {
+			t.Errorf("API-Key %q", r.Header.Get("API-Key"))
+		}
+		n := int(atomic.AddInt32(attempts, 1)) - 1
+		if n >= len(responses) {
+			n = len(responses) - 1
+		}
+		responses[n](w)
+	}
+}
+
+func status(code int, header ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		for i := 0; i+1 < len(header); i += 2 {
+			w.Header().Set(header[i], header[i+1])
+		}
+		w.WriteHeader(code)
+		_, _ = w.Write([]byte("unavailable"))
+	}
+}
+
+func data(body string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func TestRetries(t *testing.T) {
+	ok := data(`{"data": {"aiNotificationsCreateChannel": {"channel": {"id": "c"}}}}`)
+	tests := []struct {
+		name      string
+		document  string
+		responses []func(w http.ResponseWriter)
+		attempts  int32
+		code      string
+	}{
+		{"query 503", query, []func(w http.ResponseWriter){status(503), ok}, 2, ""},
+		{"query 500", query, []func(w http.ResponseWriter){status(500), status(500), ok}, 3, ""},
+		{"query 599", query, []func(w http.ResponseWriter){status(599), ok}, 2, ""},
+		{"query 429", query, []func(w http.ResponseWriter){status(429), ok}, 2, ""},
+		{"query gives up", query, []func(w http.ResponseWriter){status(502)}, 5, ServiceInternalError},
+		{"query 400 isn't retried", query, []func(w http.ResponseWriter){status(400)}, 1, InvalidRequest},
+		{"query throttled in errors", query, []func(w http.ResponseWriter){data(`{"errors": [{"message": "slow down", "extensions": {"errorClass": "TOO_MANY_REQUESTS"}}]}`), ok}, 2, ""},
+		// NerdGraph may have created the channel before failing
+		{"mutation 500 isn't retried", mutation, []func(w http.ResponseWriter){status(500), ok}, 1, ServiceInternalError},
+		{"mutation 503 isn't retried", mutation, []func(w http.ResponseWriter){status(503), ok}, 1, ServiceInternalError},
+		{"mutation 429", mutation, []func(w http.ResponseWriter){status(429), ok}, 2, ""},
+		{"mutation throttled in errors", mutation, []func(w http.ResponseWriter){data(`{"errors": [{"message": "slow down", "extensions": {"errorClass": "RATE_LIMITED"}}]}`), ok}, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(respond(t, &attempts, tt.responses...))
+			defer server.Close()
+			c, waits := newClient(t, server.URL)
+			_, err := c.Execute(context.Background(), tt.document, nil)
+			if got := ErrorCode(err); got != tt.code {
+				t.Errorf("code %q, want %q: %v", got, tt.code, err)
+			}
+			if attempts := atomic.LoadInt32(&attempts); attempts != tt.attempts {
+				t.Errorf("%d attempts, want %d", attempts, tt.attempts)
+			}
+			if len(*waits) != int(tt.attempts)-1 {
+				t.Errorf("%d waits for %d attempts", len(*waits), tt.attempts)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(respond(t, &attempts, status(503)))
+	defer server.Close()
+	c, waits := newClient(t, server.URL)
+	c.MinBackoff = time.Second
+	c.MaxBackoff = 5 * time.Second
+	if _, err := c.Execute(context.Background(), query, nil); ErrorCode(err) != ServiceInternalError {
+		t.Fatalf("got %v", err)
+	}
+	// Doubling from MinBackoff, capped at MaxBackoff, plus up to 50% jitter
+	bounds := [][2]time.Duration{{1 * time.Second, 1500 * time.Millisecond}, {2 * time.Second, 3 * time.Second}, {4 * time.Second, 6 * time.Second}, {5 * time.Second, 7500 * time.Millisecond}}
+	if len(*waits) != len(bounds) {
+		t.Fatalf("waits %v", *waits)
+	}
+	for i, wait := range *waits {
+		if wait < bounds[i][0] || wait > bounds[i][1] {
+			t.Errorf("wait %d: %v not in %v", i, wait, bounds[i])
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(respond(t, &attempts, status(429, "Retry-After", "30"), data(`{"data": {}}`)))
+	defer server.Close()
+	c, waits := newClient(t, server.URL)
+	c.MaxBackoff = time.Minute
+	if _, err := c.Execute(context.Background(), query, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 30*time.Second {
+		t.Errorf("waits %v, want [30s]", *waits)
+	}
+}
+
+func TestRetryAfterCapped(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(respond(t, &attempts, status(429, "Retry-After", "3600"), data(`{"data": {}}`)))
+	defer server.Close()
+	c, waits := newClient(t, server.URL)
+	c.MaxBackoff = 40 * time.Second
+	if _, err := c.Execute(context.Background(), query, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(*waits) != 1 || (*waits)[0] > c.MaxBackoff*3/2 {
+		t.Errorf("waits %v, want at most MaxBackoff and its jitter", *waits)
+	}
+}
+
+func TestRetryAfterDeadline(t *testing.T) {
+	// A handler with seconds left doesn't wait 30s, it fails for CloudFormation to retry
+	var attempts int32
+	server := httptest.NewServer(respond(t, &attempts, status(429, "Retry-After", "30"), data(`{"data": {}}`)))
+	defer server.Close()
+	c, waits := newClient(t, server.URL)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := c.Execute(ctx, query, nil)
+	if ErrorCode(err) != Throttling {
+		t.Errorf("got %v", err)
+	}
+	if len(*waits) != 0 || attempts != 1 {
+		t.Errorf("waits %v after %d attempts", *waits, attempts)
+	}
+}
+
+func TestNetworkFailures(t *testing.T) {
+	// The request is read, then the connection drops without a response
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		_, _ = io.ReadAll(r.Body)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		document string
+		attempts int32
+	}{{query, 1 + DefaultMaxRetries}, {mutation, 1}} {
+		atomic.StoreInt32(&attempts, 0)
+		c, _ := newClient(t, server.URL)
+		_, err := c.Execute(context.Background(), tt.document, nil)
+		if ErrorCode(err) != NetworkFailure {
+			t.Errorf("got %v", err)
+		}
+		if attempts := atomic.LoadInt32(&attempts); attempts != tt.attempts {
+			t.Errorf("%s: %d attempts, want %d", tt.document[:5], attempts, tt.attempts)
+		}
+	}
+}
+
+func TestMutationRetriedBeforeSending(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(respond(t, &attempts, data(`{"data": {"aiNotificationsCreateChannel": {"channel": {"id": "c"}}}}`)))
+	defer server.Close()
+	c, waits := newClient(t, server.URL)
+	// The first dial fails, nothing reached NerdGraph
+	var dials int32
+	dialer := &net.Dialer{}
+	c.HTTPClient = &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if atomic.AddInt32(&dials, 1) == 1 {
+			return nil, errors.New("connection refused")
+		}
+		return dialer.DialContext(ctx, network, addr)
+	}}}
+	entity, err := c.Mutate(context.Background(), mutation, nil, "aiNotificationsCreateChannel", "channel", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entity["id"] != "c" || atomic.LoadInt32(&attempts) != 1 || len(*waits) != 1 {
+		t.Errorf("entity %v, %d attempts, waits %v", entity, attempts, *waits)
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	pages := map[string]string{
+		"":   `{"data": {"actor": {"channels": {"entities": [{"id": "1"}, {"id": "2"}], "nextCursor": "a"}}}}`,
+		"a":  `{"data": {"actor": {"channels": {"entities": [{"id": "3"}], "nextCursor": "b"}}}}`,
+		"b":  `{"data": {"actor": {"channels": {"entities": [{"id": "4"}], "nextCursor": null}}}}`,
+		"r":  `{"data": {"actor": {"channels": {"entities": [{"id": "5"}], "nextCursor": "r"}}}}`,
+		"r0": `{"data": {"actor": {"channels": {"entities": [], "nextCursor": "r"}}}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := struct {
+			Variables map[string]interface{} `json:"variables"`
+		}{}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Error(err)
+		}
+		cursor, _ := request.Variables["cursor"].(string)
+		if cursor == "" {
+			cursor, _ = request.Variables["start"].(string)
+		}
+		_, _ = w.Write([]byte(pages[cursor]))
+	}))
+	defer server.Close()
+	page := Page{Path: []string{"actor", "channels"}, EntitiesField: "entities", CursorArgument: "cursor", NextCursorField: "nextCursor"}
+
+	collect := func(variables map[string]interface{}, page Page, stop int) ([]string, error) {
+		c, _ := newClient(t, server.URL)
+		ids := make([]string, 0)
+		err := c.Paginate(context.Background(), query, variables, page, func(entities []map[string]interface{}) bool {
+			for _, e := range entities {
+				ids = append(ids, e["id"].(string))
+			}
+			return stop == 0 || len(ids) < stop
+		})
+		return ids, err
+	}
+
+	if ids, err := collect(nil, page, 0); err != nil || len(ids) != 4 || ids[3] != "4" {
+		t.Errorf("all pages: %v %v", ids, err)
+	}
+	if ids, err := collect(nil, page, 1); err != nil || len(ids) != 2 {
+		t.Errorf("stop after the first page: %v %v", ids, err)
+	}
+	unpaginated := page
+	unpaginated.CursorArgument = ""
+	if ids, err := collect(nil, unpaginated, 0); err != nil || len(ids) != 2 {
+		t.Errorf("no cursor argument: %v %v", ids, err)
+	}
+	// The variables aren't changed
+	variables := map[string]interface{}{"start": "r0"}
+	if _, err := collect(variables, page, 0); ErrorCode(err) != ServiceInternalError {
+		t.Errorf("repeated cursor: %v", err)
+	}
+	if len(variables) != 1 {
+		t.Errorf("variables changed: %v", variables)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"not found", `{"errors": [{"message": "m", "extensions": {"errorClass": "NOT_FOUND"}}]}`, NotFound},
+		{"code extension", `{"errors": [{"message": "m", "extensions": {"code": "UNAUTHENTICATED"}}]}`, InvalidCredentials},
+		{"forbidden", `{"errors": [{"message": "m", "extensions": {"errorClass": "FORBIDDEN"}}]}`, AccessDenied},
+		{"validation", `{"errors": [{"message": "m", "extensions": {"errorClass": "GRAPHQL_VALIDATION_FAILED"}}]}`, InvalidRequest},
+		{"keyword", `{"errors": [{"message": "m", "extensions": {"errorClass": "CHANNEL_NOT_FOUND"}}]}`, NotFound},
+		{"first known class", `{"errors": [{"message": "a"}, {"message": "b", "extensions": {"errorClass": "DUPLICATE"}}]}`, AlreadyExists},
+		{"no class", `{"errors": [{"message": "m"}]}`, GeneralServiceException},
+		{"invalid response", `<html>`, ServiceInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(respond(t, &attempts, data(tt.body)))
+			defer server.Close()
+			c, _ := newClient(t, server.URL)
+			if _, err := c.Execute(context.Background(), query, nil); ErrorCode(err) != tt.code {
+				t.Errorf("code %q, want %q: %v", ErrorCode(err), tt.code, err)
+			}
+		})
+	}
+}
+
+func TestPayloadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		errors string
+		code   string
+	}{
+		{"none", `[]`, ""},
+		{"null member", `[null]`, ""},
+		{"type", `[{"type": "ENTITY_IN_USE", "description": "in use"}]`, ResourceConflict},
+		{"object", `{"type": "NOT_FOUND", "description": "gone"}`, NotFound},
+		{"class-like field", `[{"details": "bad", "reason": "INVALID_PARAMETER"}]`, InvalidRequest},
+		{"unknown", `[{"description": "?"}]`, GeneralServiceException},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(respond(t, &attempts, data(`{"data": {"create": {"channel": {"id": "c"}, "errors": `+tt.errors+`}}}`)))
+			defer server.Close()
+			c, _ := newClient(t, server.URL)
+			_, err := c.Mutate(context.Background(), mutation, nil, "create", "channel", "errors")
+			if ErrorCode(err) != tt.code {
+				t.Errorf("code %q, want %q: %v", ErrorCode(err), tt.code, err)
+			}
+		})
+	}
+}
+
+func TestMutateNull(t *testing.T) {
+	tests := []struct {
+		name        string
+		response    string
+		entityField string
+		code        string
+	}{
+		{"payload", `{"data": {"create": {"channel": {"id": "c"}}}}`, "channel", ""},
+		{"payload without an entity field", `{"data": {"create": {"channel": null}}}`, "", ""},
+		{"null payload", `{"data": {"create": null}}`, "channel", ServiceInternalError},
+		{"missing payload", `{"data": {}}`, "", ServiceInternalError},
+		{"null entity", `{"data": {"create": {"channel": null}}}`, "channel", ServiceInternalError},
+		{"missing entity", `{"data": {"create": {}}}`, "channel", ServiceInternalError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(respond(t, &attempts, data(tt.response)))
+			defer server.Close()
+			c, _ := newClient(t, server.URL)
+			entity, err := c.Mutate(context.Background(), mutation, nil, "create", tt.entityField, "errors")
+			if ErrorCode(err) != tt.code {
+				t.Errorf("code %q, want %q: %v", ErrorCode(err), tt.code, err)
+			}
+			if err == nil && entity == nil {
+				t.Error("no error and no entity")
+			}
+			// The mutation reached NerdGraph, it isn't retried
+			if attempts != 1 {
+				t.Errorf("%d attempts", attempts)
+			}
+		})
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		status    int
+		code      string
+		retryable bool
+	}{
+		{400, InvalidRequest, false},
+		{401, InvalidCredentials, false},
+		{403, AccessDenied, false},
+		// Not the resource, the endpoint
+		{404, InvalidTypeConfiguration, false},
+		{429, Throttling, true},
+		{500, ServiceInternalError, true},
+		{501, ServiceInternalError, true},
+		{502, ServiceInternalError, true},
+		{503, ServiceInternalError, true},
+		{504, ServiceInternalError, true},
+		{520, ServiceInternalError, true},
+		{302, GeneralServiceException, false},
+	}
+	for _, tt := range tests {
+		e := statusError(tt.status, "")
+		if e.Code != tt.code || e.Retryable != tt.retryable {
+			t.Errorf("%d: %s retryable %t, want %s %t", tt.status, e.Code, e.Retryable, tt.code, tt.retryable)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(Access{}); ErrorCode(err) != InvalidCredentials {
+		t.Errorf("no API key: %v", err)
+	}
+	for endpoint, want := range map[string]string{
+		"":                        "https://api.newrelic.com/graphql",
+		"US":                      "https://api.newrelic.com/graphql",
+		"eu":                      "https://api.eu.newrelic.com/graphql",
+		"http://localhost:8080/x": "http://localhost:8080/x",
+	} {
+		c, err := New(Access{ApiKey: "key", Endpoint: endpoint})
+		if err != nil || c.Endpoint != want {
+			t.Errorf("%q: %v %v, want %s", endpoint, c, err, want)
+		}
+	}
+}
+
+func TestIsMutation(t *testing.T) {
+	for document, want := range map[string]bool{
+		mutation:                   true,
+		"\n# comment\nmutation {}": true,
+		"mutation($a: Int) {}":     true,
+		query:                      false,
+		"{ actor { id } }":         false,
+		"mutationish {}":           false,
+	} {
+		if got := isMutation(document); got != want {
+			t.Errorf("%q: %t, want %t", document, got, want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CloudFormation HandlerErrorCodes (https://docs.aws.amazon.com/cloudformation-cli/latest/userguide/resource-type-test-contract-errors.html)
+const (
+	NotUpdatable             = "NotUpdatable"
+	InvalidRequest           = "InvalidRequest"
+	AccessDenied             = "AccessDenied"
+	InvalidCredentials       = "InvalidCredentials"
+	AlreadyExists            = "AlreadyExists"
+	NotFound                 = "NotFound"
+	ResourceConflict         = "ResourceConflict"
+	Throttling               = "Throttling"
+	ServiceLimitExceeded     = "ServiceLimitExceeded"
+	NotStabilized            = "NotStabilized"
+	GeneralServiceException  = "GeneralServiceException"
+	ServiceInternalError     = "ServiceInternalError"
+	NetworkFailure           = "NetworkFailure"
+	InternalFailure          = "InternalFailure"
+	InvalidTypeConfiguration = "InvalidTypeConfiguration"
+	HandlerInternalFailure   = "HandlerInternalFailure"
+	NonCompliant             = "NonCompliant"
+	Unknown                  = "Unknown"
+	UnsupportedTarget        = "UnsupportedTarget"
+	ServiceTimeout           = "ServiceTimeout"
+)
+
+// Error a NerdGraph failure, Code is the HandlerErrorCode to report
+type Error struct {
+	Code       string
+	Message    string
+	StatusCode int            // HTTP status, 0 when there's no response
+	Errors     []GraphQLError // The response's errors, if any
+	Retryable  bool
+	sent       bool // The request reached the server, a mutation may have run
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorCode
+// the HandlerErrorCode for any error the client returns, GeneralServiceException when it isn't an *Error
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	if e, ok := err.(*Error); ok && e.Code != "" {
+		return e.Code
+	}
+	return GeneralServiceException
+}
+
+// errorClasses
+// NerdGraph's extensions.errorClass / payload error types -> HandlerErrorCode
+var errorClasses = map[string]string{
+	"NOT_FOUND":                 NotFound,
+	"ENTITY_NOT_FOUND":          NotFound,
+	"ALREADY_EXISTS":            AlreadyExists,
+	"DUPLICATE":                 AlreadyExists,
+	"CONFLICT":                  ResourceConflict,
+	"ENTITY_IN_USE":             ResourceConflict,
+	"TOO_MANY_REQUESTS":         Throttling,
+	"RATE_LIMITED":              Throttling,
+	"LIMIT_EXCEEDED":            ServiceLimitExceeded,
+	"BAD_USER_INPUT":            InvalidRequest,
+	"INVALID_INPUT":             InvalidRequest,
+	"INVALID_PARAMETER":         InvalidRequest,
+	"VALIDATION_ERROR":          InvalidRequest,
+	"GRAPHQL_VALIDATION_FAILED": InvalidRequest,
+	"GRAPHQL_PARSE_FAILED":      InvalidRequest,
+	"UNAUTHENTICATED":           InvalidCredentials,
+	"INVALID_API_KEY":           InvalidCredentials,
+	"FORBIDDEN":                 AccessDenied,
+	"ACCESS_DENIED":             AccessDenied,
+	"UNAUTHORIZED":              AccessDenied,
+	"UNAUTHORIZED_ACCOUNT":      AccessDenied,
+	"TIMEOUT":                   ServiceTimeout,
+	"INTERNAL_SERVER_ERROR":     ServiceInternalError,
+	"SERVER_ERROR":              ServiceInternalError,
+	"UNINITIALIZED":             ServiceInternalError,
+}
+
+// classKeywords
+// fallbacks for classes errorClasses doesn't list, checked in order
+var classKeywords = []struct {
+	keyword string
+	code    string
+}{
+	{"NOT_FOUND", NotFound},
+	{"ALREADY_EXISTS", AlreadyExists},
+	{"DUPLICATE", AlreadyExists},
+	{"THROTTL", Throttling},
+	{"RATE_LIMIT", Throttling},
+	{"LIMIT", ServiceLimitExceeded},
+	{"UNAUTHENTICATED", InvalidCredentials},
+	{"UNAUTHORIZED", AccessDenied},
+	{"FORBIDDEN", AccessDenied},
+	{"INVALID", InvalidRequest},
+	{"VALIDATION", InvalidRequest},
+	{"TIMEOUT", ServiceTimeout},
+	{"INTERNAL", ServiceInternalError},
+}
+
+// CodeForClass
+// the HandlerErrorCode for a NerdGraph error class or type, e.g. ENTITY_IN_USE -> ResourceConflict
+func CodeForClass(class string) string {
+	class = strings.ToUpper(strings.TrimSpace(class))
+	if class == "" {
+		return GeneralServiceException
+	}
+	if code, ok := errorClasses[class]; ok {
+		return code
+	}
+	for _, k := range classKeywords {
+		if strings.Contains(class, k.keyword) {
+			return k.code
+		}
+	}
+	return GeneralServiceException
+}
+
+// statusError
+// a non-GraphQL HTTP failure
+func statusError(status int, body string) *Error {
+	e := &Error{StatusCode: status, Message: "nerdgraph: " + http.StatusText(status)}
+	if body = strings.TrimSpace(body); body != "" {
+		e.Message += ": " + body
+	}
+	switch {
+	case status == http.StatusUnauthorized:
+		e.Code = InvalidCredentials
+	case status == http.StatusForbidden:
+		e.Code = AccessDenied
+	case status == http.StatusNotFound:
+		// The endpoint isn't NerdGraph, that says nothing about the resource
+		e.Code = InvalidTypeConfiguration
+	case status == http.StatusTooManyRequests:
+		e.Code = Throttling
+		e.Retryable = true
+	case status >= 500:
+		e.Code = ServiceInternalError
+		e.Retryable = true
+	case status >= 400:
+		e.Code = InvalidRequest
+	default:
+		e.Code = GeneralServiceException
+	}
+	return e
+}
+
+// graphQLErrors
+// the response's errors, the first error with a known class decides the code
+func graphQLErrors(errs []GraphQLError) *Error {
+	messages := make([]string, 0, len(errs))
+	code := ""
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+		if code != "" && code != GeneralServiceException {
+			continue
+		}
+		code = CodeForClass(extensionClass(e.Extensions))
+	}
+	return &Error{
+		Code:      code,
+		Message:   "nerdgraph: " + strings.Join(messages, "; "),
+		Errors:    errs,
+		Retryable: code == Throttling,
+	}
+}
+
+// extensionClass
+// NerdGraph puts the class in extensions.errorClass, other servers in extensions.code
+func extensionClass(extensions map[string]interface{}) string {
+	for _, key := range []string{"errorClass", "code", "errorCode", "type"} {
+		if class, ok := extensions[key].(string); ok && class != "" {
+			return class
+		}
+	}
+	return ""
+}
+
+// PayloadError
+// the mutation payload's errors field as an *Error, nil when it's empty. The error's type decides the code
+func PayloadError(operation string, errs interface{}) *Error {
+	list := make([]map[string]interface{}, 0)
+	switch v := errs.(type) {
+	case []interface{}:
+		for _, e := range v {
+			if m, ok := e.(map[string]interface{}); ok && len(m) > 0 {
+				list = append(list, m)
+			}
+		}
+	case map[string]interface{}:
+		if len(v) > 0 {
+			list = append(list, v)
+		}
+	}
+	if len(list) == 0 {
+		return nil
+	}
+
+	code := GeneralServiceException
+	for _, e := range list {
+		if code = CodeForClass(payloadClass(e)); code != GeneralServiceException {
+			break
+		}
+	}
+	b, _ := json.Marshal(list)
+	return &Error{Code: code, Message: fmt.Sprintf("%s: %s", operation, string(b)), Retryable: code == Throttling}
+}
+
+// payloadClass
+// the error type of a payload error, e.g. {type: NOT_FOUND, description: ...}
+func payloadClass(e map[string]interface{}) string {
+	for _, key := range []string{"type", "errorType", "errorClass", "code"} {
+		if class, ok := e[key].(string); ok && class != "" {
+			return class
+		}
+	}
+	// Otherwise the first string-valued field that looks like a class
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if s, ok := e[k].(string); ok && s != "" && strings.ToUpper(s) == s && !strings.Contains(s, " ") {
+			return s
+		}
+	}
+	return ""
+}
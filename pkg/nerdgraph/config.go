@@ -0,0 +1,246 @@
+package nerdgraph
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+   "encoding/json"
+   "fmt"
+   "os"
+   "strings"
+)
+
+// TaggingStrategy how a resource's CloudFormation tags are mapped onto NerdGraph
+type TaggingStrategy string
+
+const (
+   // TaggingNone the resource isn't taggable
+   TaggingNone TaggingStrategy = "none"
+   // TaggingEntity CloudFormation [{Key, Value}] tags applied to the NerdGraph entity via the tagging mutations
+   TaggingEntity TaggingStrategy = "entity"
+   // TaggingArgument a mutation argument carries the tags
+   TaggingArgument TaggingStrategy = "argument"
+)
+
+// NerdGraph tagging mutations used by TaggingEntity
+const (
+   TagAddMutation     = "taggingAddTagsToEntity"
+   TagReplaceMutation = "taggingReplaceTagsOnEntity"
+)
+
+type TaggingConfig struct {
+   Strategy   TaggingStrategy `json:"strategy"`
+   Argument   string          `json:"argument"`   // TaggingArgument only: name of the mutation argument holding the tags
+   SystemTags bool            `json:"systemTags"` // Propagate aws:cloudformation:* system tags
+}
+
+// HandlerConfig CloudFormation handler settings, keyed by handler name: create | read | update | delete | list
+type HandlerConfig struct {
+   Permissions      []string `json:"permissions"`
+   TimeoutInMinutes int      `json:"timeoutInMinutes"`
+}
+
+// TypeConfigurationConfig whether the resource reads its NerdGraph ApiKey and Endpoint from the type configuration
+type TypeConfigurationConfig struct {
+   Enabled  *bool  `json:"enabled"`
+   Endpoint string `json:"endpoint"` // Default endpoint: US | EU | https://...
+}
+
+// PolicyConfig organizational rules -emit guard adds to the ones from the schema
+type PolicyConfig struct {
+   RequiredTags []string            `json:"requiredTags"` // Tag keys every resource must have
+   BannedValues map[string][]string `json:"bannedValues"` // Enum definition name, e.g. AiNotificationsChannelType, -> values templates can't use
+}
+
+// ServiceConfig overrides for the services whose name starts with the key, e.g. a namespace such as aiNotifications
+type ServiceConfig struct {
+   Handlers          map[string]*HandlerConfig `json:"handlers"`
+   TypeConfiguration *TypeConfigurationConfig  `json:"typeConfiguration"`
+   Relationships     map[string]string         `json:"relationships"`
+   Policy            *PolicyConfig             `json:"policy"`
+   WriteOnly         []string                  `json:"writeOnly"` // Mutation arguments NerdGraph never returns, e.g. secrets
+}
+
+// Config Service generation options
+type Config struct {
+   Tagging           TaggingConfig             `json:"tagging"`
+   Handlers          map[string]*HandlerConfig `json:"handlers"`
+   TypeConfiguration TypeConfigurationConfig   `json:"typeConfiguration"`
+   SelectionDepth    int                       `json:"selectionDepth"` // Object levels selected by the operation documents
+   Relationships     map[string]string         `json:"relationships"`  // Property name, e.g. DestinationId, to service name. "" disables the naming convention
+   Services          map[string]*ServiceConfig `json:"services"`
+   Policy            PolicyConfig              `json:"policy"`
+}
+
+// NerdGraph endpoints, client.EndpointURL has their URLs
+const (
+   EndpointUS = client.EndpointUS
+   EndpointEU = client.EndpointEU
+)
+
+func NewConfig() *Config {
+   enabled := true
+   return &Config{
+      Tagging: TaggingConfig{
+         Strategy:   TaggingEntity,
+         Argument:   "",
+         SystemTags: true,
+      },
+      Handlers: make(map[string]*HandlerConfig),
+      TypeConfiguration: TypeConfigurationConfig{
+         Enabled:  &enabled,
+         Endpoint: EndpointUS,
+      },
+      SelectionDepth: DefaultSelectionDepth,
+      Relationships:  make(map[string]string),
+      Services:       make(map[string]*ServiceConfig),
+   }
+}
+
+// LoadConfig read a JSON config file over the defaults
+func LoadConfig(fileName string) (*Config, error) {
+   config := NewConfig()
+   b, err := os.ReadFile(fileName)
+   if err != nil {
+      return nil, fmt.Errorf("error reading config %s: %w", fileName, err)
+   }
+   if err = json.Unmarshal(b, config); err != nil {
+      return nil, fmt.Errorf("error parsing config %s: %w", fileName, err)
+   }
+   if _, err = ParseTaggingStrategy(string(config.Tagging.Strategy)); err != nil {
+      return nil, fmt.Errorf("error parsing config %s: %w", fileName, err)
+   }
+   if err = config.validateHandlers(); err != nil {
+      return nil, fmt.Errorf("error parsing config %s: %w", fileName, err)
+   }
+   return config, nil
+}
+
+// Handler timeoutInMinutes bounds, the resource schema's minimum and maximum. 0 leaves it out
+const (
+   MinTimeoutInMinutes = 2
+   MaxTimeoutInMinutes = 2160
+)
+
+// validateHandlers
+// the handler timeouts, defaults and service overrides
+func (c *Config) validateHandlers() error {
+   check := func(scope string, handlers map[string]*HandlerConfig) error {
+      for name, hc := range handlers {
+         if hc == nil || hc.TimeoutInMinutes == 0 {
+            continue
+         }
+         if hc.TimeoutInMinutes < MinTimeoutInMinutes || hc.TimeoutInMinutes > MaxTimeoutInMinutes {
+            return fmt.Errorf("%shandlers: %s: timeoutInMinutes %d is not between %d and %d", scope, name, hc.TimeoutInMinutes, MinTimeoutInMinutes, MaxTimeoutInMinutes)
+         }
+      }
+      return nil
+   }
+   if err := check("", c.Handlers); err != nil {
+      return err
+   }
+   for prefix, sc := range c.Services {
+      if sc == nil {
+         continue
+      }
+      if err := check("services: "+prefix+": ", sc.Handlers); err != nil {
+         return err
+      }
+   }
+   return nil
+}
+
+// serviceConfig
+// the ServiceConfig with the longest key that prefixes serviceName, nil if none
+func (c *Config) serviceConfig(serviceName string) *ServiceConfig {
+   var found *ServiceConfig
+   longest := -1
+   for prefix, sc := range c.Services {
+      if strings.HasPrefix(serviceName, prefix) && len(prefix) > longest {
+         found = sc
+         longest = len(prefix)
+      }
+   }
+   return found
+}
+
+// HandlerConfig
+// the handler's settings for serviceName, service overrides win over the defaults
+func (c *Config) HandlerConfig(serviceName string, handler string) HandlerConfig {
+   hc := HandlerConfig{Permissions: make([]string, 0)}
+   if d := c.Handlers[handler]; d != nil {
+      hc.Permissions = append(hc.Permissions, d.Permissions...)
+      hc.TimeoutInMinutes = d.TimeoutInMinutes
+   }
+   if sc := c.serviceConfig(serviceName); sc != nil {
+      if o := sc.Handlers[handler]; o != nil {
+         if o.Permissions != nil {
+            hc.Permissions = append(make([]string, 0, len(o.Permissions)), o.Permissions...)
+         }
+         if o.TimeoutInMinutes != 0 {
+            hc.TimeoutInMinutes = o.TimeoutInMinutes
+         }
+      }
+   }
+   return hc
+}
+
+// TypeConfigurationConfig
+// the type configuration settings for serviceName, service overrides win over the defaults
+func (c *Config) TypeConfigurationConfig(serviceName string) (enabled bool, endpoint string) {
+   enabled = c.TypeConfiguration.Enabled == nil || *c.TypeConfiguration.Enabled
+   endpoint = c.TypeConfiguration.Endpoint
+   if sc := c.serviceConfig(serviceName); sc != nil && sc.TypeConfiguration != nil {
+      if sc.TypeConfiguration.Enabled != nil {
+         enabled = *sc.TypeConfiguration.Enabled
+      }
+      if sc.TypeConfiguration.Endpoint != "" {
+         endpoint = sc.TypeConfiguration.Endpoint
+      }
+   }
+   return
+}
+
+// Relationship
+// the configured target service for serviceName's property, found is false when the naming convention applies
+func (c *Config) Relationship(serviceName string, propertyName string) (target string, found bool) {
+   if sc := c.serviceConfig(serviceName); sc != nil {
+      if target, found = sc.Relationships[propertyName]; found {
+         return
+      }
+   }
+   target, found = c.Relationships[propertyName]
+   return
+}
+
+// PolicyConfig
+// the policy for serviceName, the service's rules are added to the defaults
+func (c *Config) PolicyConfig(serviceName string) PolicyConfig {
+   policy := PolicyConfig{RequiredTags: append(make([]string, 0), c.Policy.RequiredTags...), BannedValues: make(map[string][]string)}
+   for enum, values := range c.Policy.BannedValues {
+      policy.BannedValues[enum] = append(policy.BannedValues[enum], values...)
+   }
+   if sc := c.serviceConfig(serviceName); sc != nil && sc.Policy != nil {
+      policy.RequiredTags = append(policy.RequiredTags, sc.Policy.RequiredTags...)
+      for enum, values := range sc.Policy.BannedValues {
+         policy.BannedValues[enum] = append(policy.BannedValues[enum], values...)
+      }
+   }
+   return policy
+}
+
+// WriteOnlyArguments
+// the mutation arguments of serviceName that are writeOnlyProperties
+func (c *Config) WriteOnlyArguments(serviceName string) []string {
+   if sc := c.serviceConfig(serviceName); sc != nil {
+      return sc.WriteOnly
+   }
+   return nil
+}
+
+func ParseTaggingStrategy(s string) (TaggingStrategy, error) {
+   switch strategy := TaggingStrategy(s); strategy {
+   case TaggingNone, TaggingEntity, TaggingArgument:
+      return strategy, nil
+   default:
+      return "", fmt.Errorf("unknown tagging strategy: %s", s)
+   }
+}
@@ -0,0 +1,42 @@
+package nerdgraph_test
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+   "os"
+   "path/filepath"
+   "strings"
+   "testing"
+)
+
+func TestLoadConfigTimeoutInMinutes(t *testing.T) {
+   tests := []struct {
+      name    string
+      config  string
+      wantErr string
+   }{
+      {"unset", `{"handlers": {"create": {"permissions": ["a:b"]}}}`, ""},
+      {"minimum", `{"handlers": {"create": {"timeoutInMinutes": 2}}}`, ""},
+      {"maximum", `{"handlers": {"delete": {"timeoutInMinutes": 2160}}}`, ""},
+      {"too short", `{"handlers": {"create": {"timeoutInMinutes": 1}}}`, "create: timeoutInMinutes 1 is not between 2 and 2160"},
+      {"too long", `{"handlers": {"update": {"timeoutInMinutes": 2161}}}`, "update: timeoutInMinutes 2161 is not between 2 and 2160"},
+      {"negative", `{"handlers": {"read": {"timeoutInMinutes": -5}}}`, "read: timeoutInMinutes -5"},
+      {"service override", `{"services": {"aiNotifications": {"handlers": {"list": {"timeoutInMinutes": 3000}}}}}`, "services: aiNotifications: handlers: list"},
+   }
+   for _, tt := range tests {
+      t.Run(tt.name, func(t *testing.T) {
+         fileName := filepath.Join(t.TempDir(), "config.json")
+         if err := os.WriteFile(fileName, []byte(tt.config), 0644); err != nil {
+            t.Fatal(err)
+         }
+         _, err := nerdgraph.LoadConfig(fileName)
+         switch {
+         case tt.wantErr == "" && err != nil:
+            t.Errorf("unexpected error: %v", err)
+         case tt.wantErr != "" && err == nil:
+            t.Errorf("no error, want %q", tt.wantErr)
+         case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+            t.Errorf("error %q doesn't contain %q", err, tt.wantErr)
+         }
+      })
+   }
+}
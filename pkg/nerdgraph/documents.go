@@ -0,0 +1,133 @@
+package nerdgraph
+
+import (
+   "fmt"
+   "github.com/vektah/gqlparser/v2"
+   "github.com/vektah/gqlparser/v2/ast"
+   "github.com/vektah/gqlparser/v2/parser"
+   "github.com/vektah/gqlparser/v2/validator"
+   "os"
+   "path/filepath"
+   "sort"
+)
+
+// Operation document names, also the .graphql file names
+const (
+   CreateOperation     = "create"
+   ReadOperation       = "read"
+   UpdateOperation     = "update"
+   DeleteOperation     = "delete"
+   ListOperation       = "list"
+   CreateTagsOperation = "create-tags"
+   UpdateTagsOperation = "update-tags"
+)
+
+// tagSelectionDepth the tagging payload and its errors' fields, e.g. errors { message type }
+const tagSelectionDepth = 2
+
+// Validated schemas, shared by the services parsed from the same document
+var schemas = make(map[*ast.SchemaDocument]*ast.Schema)
+
+// OperationDocuments
+// the GraphQL documents the handlers send, by operation name, each validated against the schema
+func (s *Service) OperationDocuments() (map[string]string, error) {
+   depth := s.config.SelectionDepth
+   if depth < 1 {
+      depth = DefaultSelectionDepth
+   }
+
+   documents := make(map[string]string)
+   for _, name := range []string{CreateOperation, UpdateOperation, DeleteOperation} {
+      if field := s.GetOperation(name); field != nil {
+         documents[name] = s.MutationDocument(field, depth)
+      }
+   }
+   if q := s.ListQuery(); q != nil {
+      documents[ListOperation] = s.ListQueryDocument(q, depth)
+      documents[ReadOperation] = s.ReadQueryDocument(q, depth)
+   }
+   if s.config.Tagging.Strategy == TaggingEntity && s.hasGuid() {
+      if field := s.RootMutation(TagAddMutation); field != nil && s.createDefinition != nil {
+         documents[CreateTagsOperation] = s.MutationDocument(field, tagSelectionDepth)
+      }
+      if field := s.RootMutation(TagReplaceMutation); field != nil && s.updateDefinition != nil {
+         documents[UpdateTagsOperation] = s.MutationDocument(field, tagSelectionDepth)
+      }
+   }
+
+   schema, err := s.schema()
+   if err != nil {
+      return documents, fmt.Errorf("%s: invalid schema: %w", s.serviceName, err)
+   }
+   for name, document := range documents {
+      if _, errs := gqlparser.LoadQuery(schema, document); len(errs) > 0 {
+         return documents, fmt.Errorf("%s %s operation: %w", s.serviceName, name, errs)
+      }
+   }
+   return documents, nil
+}
+
+// EmitOperations
+// write each operation document to dir/<operation>.graphql
+func (s *Service) EmitOperations(dir string) error {
+   documents, err := s.OperationDocuments()
+   if err != nil {
+      return err
+   }
+   if err = os.MkdirAll(dir, 0755); err != nil {
+      return err
+   }
+   names := make([]string, 0, len(documents))
+   for name := range documents {
+      names = append(names, name)
+   }
+   sort.Strings(names)
+   for _, name := range names {
+      if err = os.WriteFile(filepath.Join(dir, name+".graphql"), []byte(documents[name]), 0644); err != nil {
+         return err
+      }
+   }
+   return nil
+}
+
+// GetSchema
+// the validated schema the operation documents are checked against
+func (s *Service) GetSchema() (*ast.Schema, error) {
+   return s.schema()
+}
+
+// schema
+// the schema document plus the prelude's built-ins, validated
+func (s *Service) schema() (*ast.Schema, error) {
+   if schema, ok := schemas[s.schemaDocument]; ok {
+      return schema, nil
+   }
+   prelude, err := parser.ParseSchema(validator.Prelude)
+   if err != nil {
+      return nil, err
+   }
+   // A copy, the parsed document isn't changed
+   sd := &ast.SchemaDocument{
+      Schema:          s.schemaDocument.Schema,
+      SchemaExtension: s.schemaDocument.SchemaExtension,
+      Directives:      append(ast.DirectiveDefinitionList{}, s.schemaDocument.Directives...),
+      Definitions:     append(ast.DefinitionList{}, s.schemaDocument.Definitions...),
+      Extensions:      s.schemaDocument.Extensions,
+   }
+   for _, def := range prelude.Definitions {
+      if sd.Definitions.ForName(def.Name) == nil {
+         sd.Definitions = append(sd.Definitions, def)
+      }
+   }
+   for _, directive := range prelude.Directives {
+      if sd.Directives.ForName(directive.Name) == nil {
+         sd.Directives = append(sd.Directives, directive)
+      }
+   }
+   schema, err := validator.ValidateSchemaDocument(sd)
+   if err != nil {
+      return nil, err
+   }
+   schemas[s.schemaDocument] = schema
+   return schema, nil
+}
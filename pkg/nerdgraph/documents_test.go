@@ -0,0 +1,64 @@
+package nerdgraph_test
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+   "path/filepath"
+   "strings"
+   "testing"
+)
+
+func TestOperationDocumentsTagging(t *testing.T) {
+   for _, depth := range []int{2, 3} {
+      config := nerdgraph.NewConfig()
+      config.SelectionDepth = depth
+      service, err := fixture.Entity("aiNotificationsChannel", config)
+      if err != nil {
+         t.Fatal(err)
+      }
+      documents, err := service.OperationDocuments()
+      if err != nil {
+         t.Fatalf("depth %d: %v", depth, err)
+      }
+      for _, name := range []string{nerdgraph.CreateTagsOperation, nerdgraph.UpdateTagsOperation} {
+         document, ok := documents[name]
+         if !ok {
+            t.Errorf("depth %d: no %s document", depth, name)
+            continue
+         }
+         // The payload's errors are objects, they need their own selection
+         compact := strings.Join(strings.Fields(document), " ")
+         if !strings.Contains(compact, "errors { message type }") {
+            t.Errorf("depth %d: %s doesn't select errors { message type }:\n%s", depth, name, document)
+         }
+      }
+   }
+}
+
+func TestOperationDocumentsInvalidSchema(t *testing.T) {
+   // Valid enough to parse and build the services from, not to validate
+   sdl := strings.Replace(fixture.Schema, "type Account {\n", "type Account {\n  broken: Missing\n", 1)
+   services, err := fixture.ServicesFrom(sdl, nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if _, err = services["aiNotificationsChannel"].OperationDocuments(); err == nil || !strings.Contains(err.Error(), "invalid schema") {
+      t.Errorf("got %v, want an invalid schema error", err)
+   }
+}
+
+func TestEmitOperationsGolden(t *testing.T) {
+   for _, name := range []string{"aiNotificationsChannel", "aiNotificationsDestination"} {
+      t.Run(name, func(t *testing.T) {
+         service, err := fixture.Service(name, nil)
+         if err != nil {
+            t.Fatal(err)
+         }
+         dir := t.TempDir()
+         if err = service.EmitOperations(dir); err != nil {
+            t.Fatal(err)
+         }
+         fixture.GoldenDir(t, filepath.Join("testdata", "operations", name), dir, "*.graphql")
+      })
+   }
+}
@@ -0,0 +1,121 @@
+package nerdgraph
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mapping"
+   "github.com/vektah/gqlparser/v2/ast"
+   "os"
+   "path/filepath"
+   "strings"
+)
+
+// Mapping
+// the property <-> GraphQL mapping the handlers use at runtime
+func (s *Service) Mapping() *mapping.Mapping {
+   doc := s.Document()
+   m := newMapping(doc, s.IdentifierField())
+   // The arguments update and delete identify the entity by hold the identifier too, e.g. channelId
+   for _, handler := range []string{UpdateOperation, DeleteOperation} {
+      if arg := s.IdentifierArgument(s.GetOperation(handler)); arg != "" {
+         m.AddIdentifierProperty(strings.TrimPrefix(model.PropertyPath(arg), "/properties/"))
+      }
+   }
+   // Create and update can take different input types for the same property, e.g. channel: ChannelInput | ChannelUpdate
+   for _, field := range []*ast.FieldDefinition{s.createDefinition, s.updateDefinition, s.deleteDefinition} {
+      if field == nil {
+         continue
+      }
+      for _, arg := range field.Arguments {
+         if def := doc.Definitions[arg.Type.Name()]; def != nil && len(def.Properties) > 0 {
+            m.AddArgumentDefinition(field.Name+"."+arg.Name, arg.Type.Name())
+         }
+      }
+   }
+   // Merged definitions can have fields from another input type, e.g. ChannelInput gets ChannelUpdate's active
+   for name := range doc.Definitions {
+      if def := s.schemaDocument.Definitions.ForName(name); def != nil && def.Kind == ast.InputObject {
+         fields := make([]string, 0, len(def.Fields))
+         for _, f := range def.Fields {
+            fields = append(fields, f.Name)
+         }
+         m.AddInputFields(name, fields)
+      }
+   }
+   return m
+}
+
+// newMapping
+// the properties and definitions of a generated document, identifierField is the entity field identifying the resource
+func newMapping(doc *model.Document, identifierField string) *mapping.Mapping {
+   m := &mapping.Mapping{
+      TypeName:             doc.TypeName,
+      Properties:           make(map[string]*mapping.Field),
+      Definitions:          make(map[string]map[string]*mapping.Field),
+      IdentifierField:      identifierField,
+      IdentifierProperties: make([]string, 0),
+   }
+   for name, property := range doc.Properties {
+      field := newMappingField(doc, name, property)
+      field.Arguments = append(field.Arguments, property.ArgumentPaths...)
+      m.Properties[name] = field
+   }
+   for name, def := range doc.Definitions {
+      if len(def.Properties) == 0 {
+         continue
+      }
+      fields := make(map[string]*mapping.Field)
+      for propertyName, property := range def.Properties {
+         fields[propertyName] = newMappingField(doc, propertyName, property)
+      }
+      m.Definitions[name] = fields
+   }
+   for _, path := range doc.WriteOnlyProperties {
+      m.WriteOnlyProperties = append(m.WriteOnlyProperties, strings.TrimPrefix(path, "/properties/"))
+   }
+   // The primary identifier is what NerdGraph assigns, e.g. Guid. Other read-only properties aren't identifiers
+   for _, path := range doc.PrimaryIdentifier {
+      m.AddIdentifierProperty(strings.TrimPrefix(path, "/properties/"))
+   }
+   if doc.Tagging != nil && doc.Tagging.Taggable && doc.Tagging.TagProperty != "" {
+      m.TagProperty = strings.TrimPrefix(doc.Tagging.TagProperty, "/properties/")
+      // An argument carries the tags when a mutation takes the property, otherwise it's the entity's
+      m.TagStrategy = mapping.TagsEntity
+      if field := m.Properties[m.TagProperty]; field != nil && len(field.Arguments) > 0 {
+         m.TagStrategy = mapping.TagsArgument
+      } else if field != nil {
+         field.Definition = ""
+      }
+   }
+   return m
+}
+
+// newMappingField
+// the property's GraphQL name, and the definition or enum values it refers to
+func newMappingField(doc *model.Document, name string, property *model.Property) *mapping.Field {
+   field := &mapping.Field{Name: property.OriginalName(name), Array: property.Type == "array"}
+   ref := property.Ref
+   if property.Items != nil {
+      ref = property.Items.Ref
+   }
+   ref = strings.TrimPrefix(ref, "#/definitions/")
+   if def := doc.Definitions[ref]; def != nil {
+      switch {
+      case len(def.Enum) > 0:
+         field.Enum = def.Enum
+      case len(def.Properties) > 0:
+         field.Definition = ref
+      }
+   } else if len(property.Enum) > 0 {
+      field.Enum = property.Enum
+   }
+   return field
+}
+
+// EmitMapping
+// write the mapping to dir/mapping.json
+func (s *Service) EmitMapping(dir string) error {
+   if err := os.MkdirAll(dir, 0755); err != nil {
+      return err
+   }
+   return s.Mapping().Write(filepath.Join(dir, "mapping.json"))
+}
@@ -0,0 +1,108 @@
+package mapping
+
+// Model
+// the resource model with the NerdGraph entity's fields copied in. Input object properties are filled from the fields
+// flattened onto the entity, e.g. Channel.Name <- name, the identifier goes to every identifier property. Write-only
+// properties are left out
+func (m *Mapping) Model(entity map[string]interface{}, current map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(current))
+	for k, v := range current {
+		if !contains(m.WriteOnlyProperties, k) {
+			result[k] = v
+		}
+	}
+	if entity == nil {
+		return result
+	}
+
+	for _, name := range sortedKeys(m.Properties) {
+		field := m.Properties[name]
+		if contains(m.WriteOnlyProperties, name) {
+			continue
+		}
+		if name == m.TagProperty && m.TagStrategy == TagsEntity {
+			if tags, ok := entity[field.Name]; ok {
+				result[name] = fromTags(tags)
+			}
+			continue
+		}
+		if v, ok := entity[field.Name]; ok {
+			result[name] = m.fromGraphQL(field, v)
+			continue
+		}
+		if field.Definition == "" || field.Array {
+			continue
+		}
+		object := make(map[string]interface{})
+		if existing, ok := result[name].(map[string]interface{}); ok {
+			for k, v := range existing {
+				object[k] = v
+			}
+		}
+		found := false
+		fields := m.Definitions[field.Definition]
+		for _, propertyName := range sortedKeys(fields) {
+			f := fields[propertyName]
+			if v, ok := entity[f.Name]; ok {
+				object[propertyName] = m.fromGraphQL(f, v)
+				found = true
+			}
+		}
+		if found {
+			result[name] = object
+		}
+	}
+
+	if identifier, ok := entity[m.IdentifierField]; ok && identifier != nil {
+		for _, name := range m.IdentifierProperties {
+			result[name] = identifier
+		}
+	}
+	return result
+}
+
+func (m *Mapping) fromGraphQL(field *Field, value interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			result = append(result, m.fromGraphQL(field, e))
+		}
+		return result
+	case map[string]interface{}:
+		if field.Definition == "" {
+			return convertKeys(v, upperFirst)
+		}
+		names := m.fieldNames(field.Definition)
+		fields := m.Definitions[field.Definition]
+		result := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			name, known := names[k]
+			if !known {
+				result[upperFirst(k)] = convertKeys(e, upperFirst)
+				continue
+			}
+			result[name] = m.fromGraphQL(fields[name], e)
+		}
+		return result
+	}
+	return value
+}
+
+// fromTags
+// NerdGraph [{key, values[]}] as [{Key, Value}], a tag per value
+func fromTags(value interface{}) []interface{} {
+	tags, _ := value.([]interface{})
+	result := make([]interface{}, 0, len(tags))
+	for _, t := range tags {
+		tag, _ := t.(map[string]interface{})
+		if tag == nil {
+			continue
+		}
+		values, _ := tag["values"].([]interface{})
+		for _, v := range values {
+			result = append(result, map[string]interface{}{"Key": tag["key"], "Value": v})
+		}
+	}
+	return result
+}
@@ -0,0 +1,163 @@
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+Mapping between a generated CloudFormation resource model and the NerdGraph GraphQL it was translated from. It's built
+from the Document at generation time (nerdgraph's Service.Mapping), written next to the schema, and loaded by handlers
+at runtime to:
+
+- turn a resource model into create/update mutation variables (Variables)
+- turn a NerdGraph entity back into the resource model (Model)
+
+The rules mirror the translation: property names are the argument/field names with an uppercase first letter, enums keep
+the GraphQL values, input object fields the entity returns are flattened onto the entity. Entity tags are [{Key, Value}]
+in CloudFormation and [{key, values[]}] in NerdGraph, tags a mutation argument takes are converted like any other property.
+
+The package only uses the standard library, generated handlers carry a copy.
+*/
+
+// Tag strategies, how the TagProperty reaches NerdGraph
+const (
+	TagsEntity   = "entity"   // The tagging mutations, after the resource's own
+	TagsArgument = "argument" // A mutation argument, in whatever shape its input type has
+)
+
+// Field the GraphQL side of a CloudFormation property
+type Field struct {
+	Name       string   `json:"name"`                 // GraphQL argument or field name
+	Arguments  []string `json:"arguments,omitempty"`  // mutation.argument paths, top-level properties only
+	Definition string   `json:"definition,omitempty"` // Object definition the property refers to
+	Array      bool     `json:"array,omitempty"`
+	Enum       []string `json:"enum,omitempty"`
+}
+
+type Mapping struct {
+	TypeName             string                       `json:"typeName"`
+	Properties           map[string]*Field            `json:"properties"`
+	Definitions          map[string]map[string]*Field `json:"definitions"`
+	IdentifierField      string                       `json:"identifierField"`               // Entity field holding the identifier
+	IdentifierProperties []string                     `json:"identifierProperties"`          // Properties the identifier is copied to
+	WriteOnlyProperties  []string                     `json:"writeOnlyProperties,omitempty"` // Properties returned models leave out
+	TagProperty          string                       `json:"tagProperty,omitempty"`
+	TagStrategy          string                       `json:"tagStrategy,omitempty"`         // TagsEntity | TagsArgument, with TagProperty
+	ArgumentDefinitions  map[string]string            `json:"argumentDefinitions,omitempty"` // mutation.argument -> its input type when it's a definition
+	InputFields          map[string][]string          `json:"inputFields,omitempty"`         // Input type -> the fields it accepts, definitions can have more after merging
+}
+
+// Load
+// read a mapping written by Write
+func Load(file string) (*Mapping, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("mapping: %w", err)
+	}
+	m, err := Parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("mapping: %s: %w", file, err)
+	}
+	return m, nil
+}
+
+// Parse
+// a mapping from its JSON, e.g. the one handlers embed
+func Parse(b []byte) (*Mapping, error) {
+	m := &Mapping{}
+	if err := json.Unmarshal(b, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Write
+// the mapping as JSON
+func (m *Mapping) Write(file string) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("mapping: %w", err)
+	}
+	return os.WriteFile(file, b, 0644)
+}
+
+// AddArgumentDefinition
+// the argument's input type, when it differs from the property's definition only its fields are sent
+func (m *Mapping) AddArgumentDefinition(path string, definition string) {
+	if m.ArgumentDefinitions == nil {
+		m.ArgumentDefinitions = make(map[string]string)
+	}
+	m.ArgumentDefinitions[path] = definition
+}
+
+// AddIdentifierProperty
+// another property the identifier is copied to, e.g. ChannelId, the argument update and delete identify the entity by
+func (m *Mapping) AddIdentifierProperty(name string) {
+	if !contains(m.IdentifierProperties, name) {
+		m.IdentifierProperties = append(m.IdentifierProperties, name)
+	}
+}
+
+// AddInputFields
+// the GraphQL fields the input type accepts, other fields of its definition aren't sent
+func (m *Mapping) AddInputFields(definition string, fields []string) {
+	if m.InputFields == nil {
+		m.InputFields = make(map[string][]string)
+	}
+	m.InputFields[definition] = fields
+}
+
+// Arguments
+// the mutation's arguments, by the property feeding each
+func (m *Mapping) Arguments(mutation string) map[string]string {
+	arguments := make(map[string]string)
+	for name, field := range m.Properties {
+		for _, path := range field.Arguments {
+			if argument, found := strings.CutPrefix(path, mutation+"."); found {
+				arguments[name] = argument
+			}
+		}
+	}
+	return arguments
+}
+
+// fieldNames
+// GraphQL name -> property name, for the definition's fields or the top-level properties when definition is ""
+func (m *Mapping) fieldNames(definition string) map[string]string {
+	fields := m.Properties
+	if definition != "" {
+		fields = m.Definitions[definition]
+	}
+	names := make(map[string]string, len(fields))
+	for name, field := range fields {
+		names[field.Name] = name
+	}
+	return names
+}
+
+func sortedKeys(m map[string]*Field) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
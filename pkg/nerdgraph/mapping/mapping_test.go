@@ -0,0 +1,238 @@
+package mapping
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	create = "aiNotificationsCreateChannel"
+	update = "aiNotificationsUpdateChannel"
+)
+
+func load(t *testing.T) *Mapping {
+	t.Helper()
+	m, err := Load("testdata/channel.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// object the JSON as the map a resource model or NerdGraph response decodes to
+func object(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	if s == "" {
+		return nil
+	}
+	m := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestVariables(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutation string
+		current  string
+		previous string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "create",
+			mutation: create,
+			current:  `{"AccountId": 1, "Channel": {"Name": "n", "Type": "EMAIL", "Product": "IINT", "DestinationId": "d", "Properties": [{"Key": "k", "Value": "v"}]}}`,
+			want:     `{"accountId": 1, "channel": {"name": "n", "type": "EMAIL", "product": "IINT", "destinationId": "d", "properties": [{"key": "k", "value": "v"}]}}`,
+		},
+		{
+			name:     "enum casing",
+			mutation: create,
+			current:  `{"AccountId": 1, "Channel": {"Name": "n", "Type": "email", "Product": "Iint", "DestinationId": "d", "Properties": []}}`,
+			want:     `{"accountId": 1, "channel": {"name": "n", "type": "EMAIL", "product": "IINT", "destinationId": "d", "properties": []}}`,
+		},
+		{
+			name:     "unknown enum value",
+			mutation: create,
+			current:  `{"AccountId": 1, "Channel": {"Name": "n", "Type": "PAGER", "Product": "IINT", "DestinationId": "d", "Properties": []}}`,
+			wantErr:  "expected one of EMAIL, SLACK, WEBHOOK, got PAGER",
+		},
+		{
+			name:     "enum that isn't a string",
+			mutation: create,
+			current:  `{"AccountId": 1, "Channel": {"Name": "n", "Type": 1, "Product": "IINT", "DestinationId": "d", "Properties": []}}`,
+			wantErr:  "expected one of EMAIL, SLACK, WEBHOOK, got 1",
+		},
+		{
+			// The update input has no type, product or destinationId, they're create-only
+			name:     "update input fields",
+			mutation: update,
+			current:  `{"AccountId": 1, "ChannelId": "c", "Channel": {"Name": "m", "Type": "EMAIL", "Product": "IINT", "DestinationId": "d", "Active": true}}`,
+			previous: `{"AccountId": 1, "ChannelId": "c", "Channel": {"Name": "n", "Type": "EMAIL", "Product": "IINT", "DestinationId": "d", "Active": true}}`,
+			want:     `{"accountId": 1, "channelId": "c", "channel": {"name": "m", "active": true}}`,
+		},
+		{
+			name:     "explicit null for a cleared field",
+			mutation: update,
+			current:  `{"AccountId": 1, "ChannelId": "c", "Channel": {"Name": "n"}}`,
+			previous: `{"AccountId": 1, "ChannelId": "c", "Channel": {"Name": "n", "Active": true, "Properties": [{"Key": "k", "Value": "v"}]}}`,
+			want:     `{"accountId": 1, "channelId": "c", "channel": {"name": "n", "active": null, "properties": null}}`,
+		},
+		{
+			name:     "explicit null for a cleared property",
+			mutation: update,
+			current:  `{"AccountId": 1, "ChannelId": "c"}`,
+			previous: `{"AccountId": 1, "ChannelId": "c", "Channel": {"Name": "n"}}`,
+			want:     `{"accountId": 1, "channelId": "c", "channel": null}`,
+		},
+		{
+			name:     "identifier from the previous model",
+			mutation: update,
+			current:  `{"AccountId": 1, "Channel": {"Name": "n"}}`,
+			previous: `{"AccountId": 1, "ChannelId": "c", "Channel": {"Name": "m"}}`,
+			want:     `{"accountId": 1, "channelId": "c", "channel": {"name": "n"}}`,
+		},
+		{
+			name:     "no nulls on create",
+			mutation: create,
+			current:  `{"AccountId": 1, "Channel": {"Name": "n", "Type": "EMAIL", "Product": "IINT", "DestinationId": "d"}}`,
+			want:     `{"accountId": 1, "channel": {"name": "n", "type": "EMAIL", "product": "IINT", "destinationId": "d"}}`,
+		},
+		{
+			name:     "unknown mutation",
+			mutation: "aiNotificationsTestChannel",
+			current:  `{}`,
+			wantErr:  "no arguments for aiNotificationsTestChannel",
+		},
+	}
+	m := load(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Variables(tt.mutation, object(t, tt.current), object(t, tt.previous))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := object(t, tt.want); !reflect.DeepEqual(normalize(t, got), want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestModel(t *testing.T) {
+	tests := []struct {
+		name    string
+		entity  string
+		current string
+		want    string
+	}{
+		{
+			// The entity has the input object's fields at the top, they go back into Channel
+			name:    "flattened input object",
+			entity:  `{"guid": "c", "name": "n", "type": "EMAIL", "product": "IINT", "destinationId": "d", "active": true, "properties": [{"key": "k", "value": "v", "label": null}], "createdAt": "2024-01-01"}`,
+			current: `{"AccountId": 1, "Channel": {"Name": "old"}}`,
+			want:    `{"AccountId": 1, "Guid": "c", "ChannelId": "c", "Channel": {"Name": "n", "Type": "EMAIL", "Product": "IINT", "DestinationId": "d", "Active": true, "Properties": [{"Key": "k", "Value": "v", "Label": null}]}}`,
+		},
+		{
+			name:    "fields the entity doesn't have are kept",
+			entity:  `{"guid": "c", "name": "n"}`,
+			current: `{"AccountId": 1, "Channel": {"Name": "old", "Type": "SLACK"}}`,
+			want:    `{"AccountId": 1, "Guid": "c", "ChannelId": "c", "Channel": {"Name": "n", "Type": "SLACK"}}`,
+		},
+		{
+			name:    "entity tags",
+			entity:  `{"guid": "c", "tags": [{"key": "team", "values": ["a", "b"]}, {"key": "env", "values": ["prod"]}]}`,
+			current: `{"AccountId": 1}`,
+			want:    `{"AccountId": 1, "Guid": "c", "ChannelId": "c", "Tags": [{"Key": "team", "Value": "a"}, {"Key": "team", "Value": "b"}, {"Key": "env", "Value": "prod"}]}`,
+		},
+		{
+			name:    "no entity",
+			current: `{"AccountId": 1}`,
+			want:    `{"AccountId": 1}`,
+		},
+	}
+	m := load(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := m.Model(object(t, tt.entity), object(t, tt.current))
+			if want := object(t, tt.want); !reflect.DeepEqual(normalize(t, got), want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestModelWriteOnly(t *testing.T) {
+	m := load(t)
+	m.WriteOnlyProperties = []string{"Channel", "AccountId"}
+	entity := object(t, `{"guid": "c", "name": "n", "active": true}`)
+	for _, current := range []string{`{"AccountId": 1, "Channel": {"Name": "n"}}`, `{}`} {
+		got := m.Model(entity, object(t, current))
+		if want := object(t, `{"Guid": "c", "ChannelId": "c"}`); !reflect.DeepEqual(normalize(t, got), want) {
+			t.Errorf("%s: got %v, want %v", current, got, want)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	m := load(t)
+	current := object(t, `{"Tags": [{"Key": "team", "Value": "a"}, {"Key": "env", "Value": "prod"}, {"Key": "team", "Value": "b"}]}`)
+	want := object(t, `{"tags": [{"key": "team", "values": ["a", "b"]}, {"key": "env", "values": ["prod"]}]}`)["tags"]
+	if got := m.Tags(current); !reflect.DeepEqual(normalize(t, map[string]interface{}{"tags": got})["tags"], want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestArgumentTags(t *testing.T) {
+	// The tags are a mutation argument, e.g. -tagging argument -tagArgument tags, in the argument's own shape
+	m := &Mapping{
+		TypeName: "NewRelic::Observability::dashboard",
+		Properties: map[string]*Field{
+			"Name": {Name: "name", Arguments: []string{"dashboardCreate.name"}},
+			"Tags": {Name: "tags", Arguments: []string{"dashboardCreate.tags"}, Definition: "TaggingTagInput", Array: true},
+		},
+		Definitions: map[string]map[string]*Field{
+			"TaggingTagInput": {"Key": {Name: "key"}, "Values": {Name: "values", Array: true}},
+		},
+		IdentifierField:      "guid",
+		IdentifierProperties: []string{"Guid"},
+		TagProperty:          "Tags",
+		TagStrategy:          TagsArgument,
+	}
+	current := object(t, `{"Name": "n", "Tags": [{"Key": "team", "Values": ["a", "b"]}]}`)
+	variables, err := m.Variables("dashboardCreate", current, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := object(t, `{"name": "n", "tags": [{"key": "team", "values": ["a", "b"]}]}`); !reflect.DeepEqual(normalize(t, variables), want) {
+		t.Errorf("variables %v, want %v", variables, want)
+	}
+	if tags := m.Tags(current); tags != nil {
+		t.Errorf("entity tags %v for argument tags", tags)
+	}
+	model := m.Model(object(t, `{"guid": "g", "tags": [{"key": "team", "values": ["c"]}]}`), current)
+	if want := object(t, `{"Name": "n", "Guid": "g", "Tags": [{"Key": "team", "Values": ["c"]}]}`); !reflect.DeepEqual(normalize(t, model), want) {
+		t.Errorf("model %v, want %v", model, want)
+	}
+}
+
+// normalize
+// the value as it round trips through JSON, numbers are float64
+func normalize(t *testing.T, v map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return object(t, string(b))
+}
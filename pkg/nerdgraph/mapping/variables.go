@@ -0,0 +1,174 @@
+package mapping
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Variables
+// the mutation's variables from the resource model (CloudFormation property name -> value). previous is the model before
+// an update: whatever it has that current no longer does is sent as an explicit null, clearing it in NerdGraph, except
+// identifier properties which come from previous. It's nil for create
+func (m *Mapping) Variables(mutation string, current map[string]interface{}, previous map[string]interface{}) (map[string]interface{}, error) {
+	arguments := m.Arguments(mutation)
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("mapping: %s: no arguments for %s", m.TypeName, mutation)
+	}
+	variables := make(map[string]interface{}, len(arguments))
+	for name, argument := range arguments {
+		value, ok := current[name]
+		if (!ok || value == nil) && contains(m.IdentifierProperties, name) {
+			// Read-only, the desired state doesn't have to repeat it
+			value, ok = previous[name]
+		}
+		if !ok || value == nil {
+			if _, cleared := previous[name]; cleared {
+				variables[argument] = nil
+			}
+			continue
+		}
+		field := m.Properties[name]
+		if definition, ok := m.ArgumentDefinitions[mutation+"."+argument]; ok && field != nil && field.Definition != "" {
+			f := *field
+			f.Definition = definition
+			field = &f
+		}
+		v, err := m.toGraphQL(field, value, previous[name])
+		if err != nil {
+			return nil, fmt.Errorf("mapping: %s: %s: %w", m.TypeName, name, err)
+		}
+		variables[argument] = v
+	}
+	return variables, nil
+}
+
+// Tags
+// the model's [{Key, Value}] tags as NerdGraph entity tags [{key, values[]}], values of repeated keys are grouped in the
+// order they appear. nil unless the tags are the entity's, argument tags are in the mutation's Variables
+func (m *Mapping) Tags(current map[string]interface{}) []interface{} {
+	if m.TagStrategy != TagsEntity {
+		return nil
+	}
+	tags, _ := current[m.TagProperty].([]interface{})
+	values := make(map[string][]interface{})
+	keys := make([]string, 0)
+	for _, t := range tags {
+		tag, _ := t.(map[string]interface{})
+		if tag == nil {
+			continue
+		}
+		key := fmt.Sprint(tag["Key"])
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = append(values[key], tag["Value"])
+	}
+	input := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		input = append(input, map[string]interface{}{"key": key, "values": values[key]})
+	}
+	return input
+}
+
+func (m *Mapping) toGraphQL(field *Field, value interface{}, previous interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if field == nil {
+		return convertKeys(value, lowerFirst), nil
+	}
+	if list, ok := value.([]interface{}); ok {
+		// Lists are replaced as a whole, there's nothing to clear inside them
+		element := *field
+		element.Array = false
+		result := make([]interface{}, 0, len(list))
+		for _, e := range list {
+			v, err := m.toGraphQL(&element, e, nil)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, v)
+		}
+		return result, nil
+	}
+	if len(field.Enum) > 0 {
+		return enumValue(field.Enum, value)
+	}
+	if field.Definition == "" {
+		return convertKeys(value, lowerFirst), nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected an object, got %T", field.Definition, value)
+	}
+	fields := m.Definitions[field.Definition]
+	if fields == nil {
+		return convertKeys(value, lowerFirst), nil
+	}
+	previousObject, _ := previous.(map[string]interface{})
+	inputFields, restricted := m.InputFields[field.Definition]
+	result := make(map[string]interface{}, len(object))
+	for _, name := range sortedKeys(fields) {
+		f := fields[name]
+		if restricted && !contains(inputFields, f.Name) {
+			continue
+		}
+		v, ok := object[name]
+		if !ok || v == nil {
+			if _, cleared := previousObject[name]; cleared {
+				result[f.Name] = nil
+			}
+			continue
+		}
+		converted, err := m.toGraphQL(f, v, previousObject[name])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		result[f.Name] = converted
+	}
+	// Properties the input type doesn't have are dropped, e.g. create-only ones on update
+	return result, nil
+}
+
+// enumValue
+// the enum value whatever the template's casing, e.g. email -> EMAIL
+func enumValue(enum []string, value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected one of %s, got %v", strings.Join(enum, ", "), value)
+	}
+	for _, e := range enum {
+		if strings.EqualFold(e, s) {
+			return e, nil
+		}
+	}
+	return nil, fmt.Errorf("expected one of %s, got %s", strings.Join(enum, ", "), s)
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func convertKeys(v interface{}, convert func(string) string) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, e := range value {
+			m[convert(k)] = convertKeys(e, convert)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, 0, len(value))
+		for _, e := range value {
+			l = append(l, convertKeys(e, convert))
+		}
+		return l
+	}
+	return v
+}
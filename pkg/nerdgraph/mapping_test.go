@@ -0,0 +1,72 @@
+package nerdgraph_test
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mapping"
+   "reflect"
+   "testing"
+)
+
+func TestMappingGolden(t *testing.T) {
+   service, err := fixture.Entity("aiNotificationsChannel", nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   want, err := mapping.Load("mapping/testdata/channel.json")
+   if err != nil {
+      t.Fatal(err)
+   }
+   got := service.Mapping()
+   if !reflect.DeepEqual(got, want) {
+      file := t.TempDir() + "/channel.json"
+      _ = got.Write(file)
+      t.Errorf("mapping differs from mapping/testdata/channel.json, got %s", file)
+   }
+}
+
+func TestMappingIdentifierProperties(t *testing.T) {
+   service, err := fixture.Service("aiNotificationsChannel", nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   // Read-only, but not an identifier
+   service.Document().AddReadOnlyProperty("createdAt")
+   want := []string{"Guid", "ChannelId"}
+   if got := service.Mapping().IdentifierProperties; !reflect.DeepEqual(got, want) {
+      t.Errorf("got %v, want %v", got, want)
+   }
+}
+
+func TestMappingTagStrategy(t *testing.T) {
+   tests := []struct {
+      name        string
+      strategy    nerdgraph.TaggingStrategy
+      argument    string
+      tagProperty string
+      want        string
+   }{
+      {"entity", nerdgraph.TaggingEntity, "", "Tags", mapping.TagsEntity},
+      {"argument", nerdgraph.TaggingArgument, "channel", "Channel", mapping.TagsArgument},
+      {"none", nerdgraph.TaggingNone, "", "", ""},
+   }
+   for _, tt := range tests {
+      t.Run(tt.name, func(t *testing.T) {
+         config := nerdgraph.NewConfig()
+         config.Tagging.Strategy = tt.strategy
+         config.Tagging.Argument = tt.argument
+         service, err := fixture.Entity("aiNotificationsChannel", config)
+         if err != nil {
+            t.Fatal(err)
+         }
+         m := service.Mapping()
+         if m.TagProperty != tt.tagProperty || m.TagStrategy != tt.want {
+            t.Errorf("tagProperty %q strategy %q, want %q %q", m.TagProperty, m.TagStrategy, tt.tagProperty, tt.want)
+         }
+         // Argument tags keep their input type
+         if tt.strategy == nerdgraph.TaggingArgument && m.Properties[tt.tagProperty].Definition == "" {
+            t.Errorf("%s lost its definition", tt.tagProperty)
+         }
+      })
+   }
+}
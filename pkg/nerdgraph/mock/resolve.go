@@ -0,0 +1,209 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/vektah/gqlparser/v2/ast"
+	"math"
+)
+
+// resolver
+// completes the selection against stored values, filling non-null fields nothing was stored for with zero values,
+// and records anything that wouldn't validate against the schema
+type resolver struct {
+	schema    *ast.Schema
+	fragments ast.FragmentDefinitionList
+	errors    []string
+}
+
+// object
+// the selection set of the object type on source
+func (r *resolver) object(set ast.SelectionSet, def *ast.Definition, source map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, field := range r.collect(set, def) {
+		if field.Name == "__typename" {
+			result[field.Alias] = def.Name
+			continue
+		}
+		fieldDef := def.Fields.ForName(field.Name)
+		if fieldDef == nil {
+			r.errors = append(r.errors, fmt.Sprintf("%s has no field %s", def.Name, field.Name))
+			continue
+		}
+		result[field.Alias] = r.complete(field.SelectionSet, fieldDef.Type, source[field.Name], def.Name+"."+field.Name)
+	}
+	return result
+}
+
+// collect
+// the fields selected on def, through fragments whose type condition applies
+func (r *resolver) collect(set ast.SelectionSet, def *ast.Definition) []*ast.Field {
+	fields := make([]*ast.Field, 0, len(set))
+	for _, selection := range set {
+		switch s := selection.(type) {
+		case *ast.Field:
+			fields = append(fields, s)
+		case *ast.InlineFragment:
+			if r.applies(s.TypeCondition, def) {
+				fields = append(fields, r.collect(s.SelectionSet, def)...)
+			}
+		case *ast.FragmentSpread:
+			if fragment := r.fragments.ForName(s.Name); fragment != nil && r.applies(fragment.TypeCondition, def) {
+				fields = append(fields, r.collect(fragment.SelectionSet, def)...)
+			}
+		}
+	}
+	return fields
+}
+
+func (r *resolver) applies(typeCondition string, def *ast.Definition) bool {
+	if typeCondition == "" || typeCondition == def.Name {
+		return true
+	}
+	for _, possible := range r.schema.GetPossibleTypes(r.schema.Types[typeCondition]) {
+		if possible.Name == def.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// complete
+// the value as t, path is for error messages
+func (r *resolver) complete(set ast.SelectionSet, t *ast.Type, value interface{}, path string) interface{} {
+	if value == nil {
+		if !t.NonNull {
+			return nil
+		}
+		value = r.zero(t)
+	}
+	if t.Elem != nil {
+		list, ok := value.([]interface{})
+		if !ok {
+			r.errors = append(r.errors, fmt.Sprintf("%s: expected a list, got %T", path, value))
+			return nil
+		}
+		result := make([]interface{}, 0, len(list))
+		for i, e := range list {
+			result = append(result, r.complete(set, t.Elem, e, fmt.Sprintf("%s[%d]", path, i)))
+		}
+		return result
+	}
+
+	def := r.schema.Types[t.NamedType]
+	if def == nil {
+		r.errors = append(r.errors, fmt.Sprintf("%s: unknown type %s", path, t.NamedType))
+		return nil
+	}
+	switch def.Kind {
+	case ast.Scalar:
+		return r.scalar(def, value, path)
+	case ast.Enum:
+		if s, ok := value.(string); ok && def.EnumValues.ForName(s) != nil {
+			return s
+		}
+		r.errors = append(r.errors, fmt.Sprintf("%s: %v isn't a %s value", path, value, def.Name))
+		return nil
+	}
+	source, ok := value.(map[string]interface{})
+	if !ok {
+		r.errors = append(r.errors, fmt.Sprintf("%s: expected a %s object, got %T", path, def.Name, value))
+		return nil
+	}
+	if def.Kind == ast.Interface || def.Kind == ast.Union {
+		def = r.concrete(def, source)
+		if def == nil {
+			r.errors = append(r.errors, fmt.Sprintf("%s: no concrete type for %s", path, t.NamedType))
+			return nil
+		}
+	}
+	return r.object(set, def, source)
+}
+
+// concrete
+// the source's __typename, otherwise the first possible type
+func (r *resolver) concrete(def *ast.Definition, source map[string]interface{}) *ast.Definition {
+	possible := r.schema.GetPossibleTypes(def)
+	if name, ok := source["__typename"].(string); ok {
+		for _, p := range possible {
+			if p.Name == name {
+				return p
+			}
+		}
+	}
+	if len(possible) == 0 {
+		return nil
+	}
+	return possible[0]
+}
+
+func (r *resolver) scalar(def *ast.Definition, value interface{}, path string) interface{} {
+	valid := true
+	switch def.Name {
+	case "Int":
+		switch v := value.(type) {
+		case int, int32, int64:
+		case float64:
+			valid = v == math.Trunc(v)
+		case json.Number:
+			_, err := v.Int64()
+			valid = err == nil
+		default:
+			valid = false
+		}
+	case "Float":
+		switch value.(type) {
+		case int, int32, int64, float64, json.Number:
+		default:
+			valid = false
+		}
+	case "Boolean":
+		_, valid = value.(bool)
+	case "String":
+		_, valid = value.(string)
+	case "ID":
+		switch value.(type) {
+		case string, int, int64, float64, json.Number:
+		default:
+			valid = false
+		}
+	}
+	// Custom scalars are whatever was stored
+	if !valid {
+		r.errors = append(r.errors, fmt.Sprintf("%s: %v isn't a %s", path, value, def.Name))
+		return nil
+	}
+	return value
+}
+
+// zero
+// the value for a non-null field nothing was stored for
+func (r *resolver) zero(t *ast.Type) interface{} {
+	if t.Elem != nil {
+		return []interface{}{}
+	}
+	def := r.schema.Types[t.NamedType]
+	if def == nil {
+		return nil
+	}
+	switch def.Kind {
+	case ast.Enum:
+		if len(def.EnumValues) > 0 {
+			return def.EnumValues[0].Name
+		}
+		return nil
+	case ast.Scalar:
+		switch def.Name {
+		case "Int":
+			return 0
+		case "Float":
+			return 0.0
+		case "Boolean":
+			return false
+		case "ID":
+			return newID(def.Name)
+		}
+		return ""
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,473 @@
+package mock
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/validator"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/*
+Server a stand-in for NerdGraph, serving the services' operations from an in-memory store per resource:
+
+- create inserts the mutation's input, flattened, under a new identifier
+- update patches it, explicit nulls clear fields
+- delete removes it
+- the service's list query path returns the stored entities, filtered by identifier and paged with nextCursor
+- taggingAddTagsToEntity/taggingReplaceTagsOnEntity set the entity's tags, when the services are tagged with the entity
+  tagging strategy. With the argument strategy the tags are stored like any other input
+
+Documents and variables are validated against the schema, and so is every response. Failures come back the way NerdGraph
+reports them: payload errors when the mutation has an errors field, otherwise errors[].extensions.errorClass.
+*/
+
+// Server
+// safe for concurrent use
+type Server struct {
+	PageSize  int // Entities per list page, 0 is all of them
+	schema    *ast.Schema
+	mutations map[string]*binding
+	services  []*nerdgraph.Service
+	stores    map[*nerdgraph.Service]*store
+	mu        sync.Mutex
+}
+
+// binding
+// a root mutation and the service and handler it belongs to
+type binding struct {
+	service *nerdgraph.Service
+	handler string // create | update | delete | tag
+	replace bool   // Tag replacement rather than addition
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type response struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []*responseError       `json:"errors,omitempty"`
+}
+
+type responseError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// failure
+// a resolver failure, Class is NerdGraph's errorClass
+type failure struct {
+	Class   string
+	Message string
+}
+
+func (f *failure) Error() string {
+	return f.Message
+}
+
+// New
+// a server for the services, which must come from the same schema document
+func New(services map[string]*nerdgraph.Service) (*Server, error) {
+	s := &Server{
+		mutations: make(map[string]*binding),
+		services:  make([]*nerdgraph.Service, 0, len(services)),
+		stores:    make(map[*nerdgraph.Service]*store),
+	}
+	tagging := false
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		service := services[name]
+		if s.schema == nil {
+			schema, err := service.GetSchema()
+			if err != nil {
+				return nil, fmt.Errorf("mock: %w", err)
+			}
+			s.schema = schema
+		}
+		s.services = append(s.services, service)
+		s.stores[service] = newStore()
+		tagging = tagging || service.GetConfig().Tagging.Strategy == nerdgraph.TaggingEntity
+		for _, handler := range []string{"create", "update", "delete"} {
+			if field := service.GetOperation(handler); field != nil {
+				s.mutations[field.Name] = &binding{service: service, handler: handler}
+			}
+		}
+	}
+	if s.schema == nil {
+		return nil, fmt.Errorf("mock: no services")
+	}
+	if tagging {
+		s.mutations[nerdgraph.TagAddMutation] = &binding{handler: "tag"}
+		s.mutations[nerdgraph.TagReplaceMutation] = &binding{handler: "tag", replace: true}
+	}
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST a GraphQL request", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get("API-Key") == "" {
+		writeJSON(w, http.StatusUnauthorized, &response{Errors: []*responseError{newResponseError(&failure{Class: "UNAUTHENTICATED", Message: "missing API-Key header"}, nil)}})
+		return
+	}
+	req := request{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, &response{Errors: []*responseError{newResponseError(&failure{Class: "BAD_USER_INPUT", Message: err.Error()}, nil)}})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.execute(req.Query, req.OperationName, req.Variables))
+}
+
+// execute
+// the request's response
+func (s *Server) execute(query string, operationName string, variables map[string]interface{}) *response {
+	doc, errs := gqlparser.LoadQuery(s.schema, query)
+	if len(errs) > 0 {
+		result := &response{}
+		for _, e := range errs {
+			result.Errors = append(result.Errors, newResponseError(&failure{Class: "GRAPHQL_VALIDATION_FAILED", Message: e.Message}, nil))
+		}
+		return result
+	}
+	op := doc.Operations.ForName(operationName)
+	if op == nil {
+		return &response{Errors: []*responseError{newResponseError(&failure{Class: "BAD_USER_INPUT", Message: fmt.Sprintf("no operation %q", operationName)}, nil)}}
+	}
+	vars, err := validator.VariableValues(s.schema, op, variables)
+	if err != nil {
+		return &response{Errors: []*responseError{newResponseError(&failure{Class: "BAD_USER_INPUT", Message: err.Error()}, nil)}}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := &resolver{schema: s.schema, fragments: doc.Fragments}
+	result := &response{Data: make(map[string]interface{})}
+	root := s.schema.Query
+	if op.Operation == ast.Mutation {
+		root = s.schema.Mutation
+	}
+	for _, field := range r.collect(op.SelectionSet, root) {
+		if field.Name == "__typename" {
+			result.Data[field.Alias] = root.Name
+			continue
+		}
+		var value interface{}
+		var err error
+		if op.Operation == ast.Mutation {
+			value, err = s.mutate(field, vars)
+		} else {
+			value, err = s.query(field, vars, nil)
+		}
+		if err != nil {
+			result.Data[field.Alias] = nil
+			result.Errors = append(result.Errors, newResponseError(err, []interface{}{field.Alias}))
+			continue
+		}
+		result.Data[field.Alias] = r.complete(field.SelectionSet, field.Definition.Type, value, field.Alias)
+	}
+	// The response is the contract the handlers are tested against, a mock bug mustn't look like NerdGraph behaviour
+	for _, e := range r.errors {
+		log.Errorf("mock: invalid response: %s", e)
+		result.Errors = append(result.Errors, newResponseError(&failure{Class: "INTERNAL_SERVER_ERROR", Message: "mock: invalid response: " + e}, nil))
+	}
+	return result
+}
+
+// mutate
+// the payload source for a root mutation field
+func (s *Server) mutate(field *ast.Field, vars map[string]interface{}) (interface{}, error) {
+	b := s.mutations[field.Name]
+	if b == nil {
+		return nil, &failure{Class: "BAD_USER_INPUT", Message: fmt.Sprintf("mock: %s isn't a generated resource's mutation", field.Name)}
+	}
+	args := field.ArgumentMap(vars)
+	if b.handler == "tag" {
+		return s.tag(args, b.replace)
+	}
+
+	service := b.service
+	st := s.stores[service]
+	identifierField := service.IdentifierField()
+	definition := field.Definition
+	var entity map[string]interface{}
+	id := ""
+	switch b.handler {
+	case "create":
+		id = newID(service.GetName())
+		entity = map[string]interface{}{identifierField: id}
+		if entityType := service.EntityType(); entityType != nil {
+			// Both identifiers when the entity has them
+			for _, name := range []string{"id", "guid"} {
+				if entityType.Fields.ForName(name) != nil {
+					entity[name] = id
+				}
+			}
+		}
+		patch(entity, args)
+		st.insert(id, entity)
+	case "update", "delete":
+		id = fmt.Sprint(args[service.IdentifierArgument(definition)])
+		entity = st.get(id)
+		if entity == nil {
+			return s.payloadFailure(service, definition, &failure{Class: "NOT_FOUND", Message: fmt.Sprintf("%s %s not found", service.GetName(), id)})
+		}
+		if b.handler == "update" {
+			input := make(map[string]interface{}, len(args))
+			for k, v := range args {
+				if k != service.IdentifierArgument(definition) {
+					input[k] = v
+				}
+			}
+			patch(entity, input)
+		} else {
+			st.remove(id)
+		}
+	}
+	return s.payload(service, definition, entity, id), nil
+}
+
+// payload
+// the mutation's payload: the entity, or the entity's field when the payload wraps it, no errors, and the identifier for ID fields
+func (s *Server) payload(service *nerdgraph.Service, definition *ast.FieldDefinition, entity map[string]interface{}, id string) map[string]interface{} {
+	entityField := service.EntityField(definition)
+	payload := make(map[string]interface{})
+	if entityField == "" {
+		for k, v := range entity {
+			payload[k] = v
+		}
+	} else {
+		payload[entityField] = entity
+	}
+	if errorsField := service.ErrorsField(definition); errorsField != "" {
+		payload[errorsField] = nil
+	}
+	if payloadType := s.schema.Types[definition.Type.Name()]; payloadType != nil {
+		for _, f := range payloadType.Fields {
+			if _, set := payload[f.Name]; set || (f.Type.Name() != "ID" && f.Type.Name() != "EntityGuid") {
+				continue
+			}
+			if f.Type.Elem != nil {
+				payload[f.Name] = []interface{}{id}
+			} else {
+				payload[f.Name] = id
+			}
+		}
+	}
+	return payload
+}
+
+// payloadFailure
+// the failure in the payload's errors field, as a top-level error when there isn't one
+func (s *Server) payloadFailure(service *nerdgraph.Service, definition *ast.FieldDefinition, f *failure) (interface{}, error) {
+	errorsField := service.ErrorsField(definition)
+	payloadType := s.schema.Types[definition.Type.Name()]
+	if errorsField == "" || payloadType == nil {
+		return nil, f
+	}
+	fieldDef := payloadType.Fields.ForName(errorsField)
+	errorType := s.errorType(s.schema.Types[fieldDef.Type.Name()])
+	if errorType == nil {
+		return nil, f
+	}
+	e := map[string]interface{}{"__typename": errorType.Name}
+	for _, name := range []string{"type", "errorType", "errorClass", "code"} {
+		if field := errorType.Fields.ForName(name); field != nil {
+			if enum := s.schema.Types[field.Type.Name()]; enum != nil && enum.Kind == ast.Enum && enum.EnumValues.ForName(f.Class) == nil {
+				continue
+			}
+			e[name] = f.Class
+		}
+	}
+	for _, name := range []string{"message", "description", "details"} {
+		if errorType.Fields.ForName(name) != nil {
+			e[name] = f.Message
+		}
+	}
+	if fieldDef.Type.Elem != nil {
+		return map[string]interface{}{errorsField: []interface{}{e}}, nil
+	}
+	return map[string]interface{}{errorsField: e}, nil
+}
+
+// errorType
+// the object type describing a failure: the type itself, or the union member with a type/code field
+func (s *Server) errorType(def *ast.Definition) *ast.Definition {
+	if def == nil {
+		return nil
+	}
+	if def.Kind == ast.Object {
+		return def
+	}
+	possible := s.schema.GetPossibleTypes(def)
+	for _, p := range possible {
+		if p.Fields.ForName("type") != nil || p.Fields.ForName("code") != nil {
+			return p
+		}
+	}
+	if len(possible) > 0 {
+		return possible[0]
+	}
+	return nil
+}
+
+// tag
+// add or replace the entity's tags, the entity is found by identifier across the stores
+func (s *Server) tag(args map[string]interface{}, replace bool) (interface{}, error) {
+	guid := fmt.Sprint(args["guid"])
+	for _, service := range s.services {
+		entity := s.stores[service].get(guid)
+		if entity == nil {
+			continue
+		}
+		tags := make([]interface{}, 0)
+		if existing, ok := entity["tags"].([]interface{}); ok && !replace {
+			tags = append(tags, existing...)
+		}
+		if input, ok := args["tags"].([]interface{}); ok {
+			tags = append(tags, input...)
+		}
+		entity["tags"] = tags
+		return map[string]interface{}{"errors": []interface{}{}}, nil
+	}
+	return nil, &failure{Class: "NOT_FOUND", Message: fmt.Sprintf("entity %s not found", guid)}
+}
+
+// query
+// resolve a query field, descending the service list query paths that go through it. prefix is the path so far
+func (s *Server) query(field *ast.Field, vars map[string]interface{}, prefix []string) (interface{}, error) {
+	path := append(append([]string{}, prefix...), field.Name)
+	for _, service := range s.services {
+		q := service.ListQuery()
+		if q == nil || len(q.Fields) < len(path) || !hasPrefix(q.Fields, path) {
+			continue
+		}
+		if len(q.Fields) == len(path) {
+			return s.list(service, q, field.ArgumentMap(vars))
+		}
+		// Not there yet, an object holding the next fields down
+		source := make(map[string]interface{})
+		def := s.schema.Types[field.Definition.Type.Name()]
+		r := &resolver{schema: s.schema}
+		for _, child := range r.collect(field.SelectionSet, def) {
+			if child.Name == "__typename" || child.Definition == nil {
+				continue
+			}
+			value, err := s.query(child, vars, path)
+			if err != nil {
+				return nil, err
+			}
+			source[child.Name] = value
+		}
+		return source, nil
+	}
+	return nil, nil
+}
+
+// list
+// the stored entities for the query path's last field, filtered by identifier and paged
+func (s *Server) list(service *nerdgraph.Service, q *nerdgraph.QueryPath, args map[string]interface{}) (interface{}, error) {
+	entities := s.stores[service].list()
+	if filter, ok := args[q.FilterArgument].(map[string]interface{}); ok && q.FilterArgument != "" {
+		if id, ok := filter[q.FilterField]; ok && id != nil {
+			filtered := make([]map[string]interface{}, 0, 1)
+			for _, e := range entities {
+				if fmt.Sprint(e[q.FilterField]) == fmt.Sprint(id) {
+					filtered = append(filtered, e)
+				}
+			}
+			entities = filtered
+		}
+	}
+	total := len(entities)
+
+	start := 0
+	if cursor, ok := args[q.CursorArgument].(string); ok && q.CursorArgument != "" && cursor != "" {
+		n, err := strconv.Atoi(cursor)
+		if err != nil || n < 0 || n > len(entities) {
+			return nil, &failure{Class: "BAD_USER_INPUT", Message: fmt.Sprintf("invalid cursor %s", cursor)}
+		}
+		start = n
+	}
+	end := len(entities)
+	if s.PageSize > 0 && q.CursorArgument != "" && start+s.PageSize < end {
+		end = start + s.PageSize
+	}
+	list := make([]interface{}, 0, end-start)
+	for _, e := range entities[start:end] {
+		list = append(list, e)
+	}
+	if q.EntitiesField == "" {
+		return list, nil
+	}
+	result := map[string]interface{}{q.EntitiesField: list, "totalCount": total}
+	if q.NextCursorField != "" && end < len(entities) {
+		result[q.NextCursorField] = strconv.Itoa(end)
+	}
+	return result, nil
+}
+
+func hasPrefix(fields []*ast.FieldDefinition, path []string) bool {
+	for i, name := range path {
+		if fields[i].Name != name {
+			return false
+		}
+	}
+	return true
+}
+
+func newResponseError(err error, path []interface{}) *responseError {
+	class := "INTERNAL_SERVER_ERROR"
+	if f, ok := err.(*failure); ok {
+		class = f.Class
+	}
+	return &responseError{Message: err.Error(), Path: path, Extensions: map[string]interface{}{"errorClass": class}}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Errorf("mock: %v", err)
+	}
+}
+
+// Describe
+// a line per resource: the mutations it answers, its query path and tagging strategy
+func (s *Server) Describe() []string {
+	lines := make([]string, 0, len(s.services))
+	for _, service := range s.services {
+		operations := make([]string, 0, 4)
+		for _, handler := range []string{"create", "update", "delete"} {
+			if field := service.GetOperation(handler); field != nil {
+				operations = append(operations, handler+"="+field.Name)
+			}
+		}
+		if q := service.ListQuery(); q != nil {
+			path := make([]string, 0, len(q.Fields))
+			for _, f := range q.Fields {
+				path = append(path, f.Name)
+			}
+			operations = append(operations, "list="+strings.Join(path, "."))
+		}
+		operations = append(operations, "tagging="+string(service.GetConfig().Tagging.Strategy))
+		lines = append(lines, service.GetName()+": "+strings.Join(operations, " "))
+	}
+	return lines
+}
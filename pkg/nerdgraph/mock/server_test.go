@@ -0,0 +1,129 @@
+package mock_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/client"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/mock"
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+)
+
+const tagDocument = `mutation Tag($guid: EntityGuid!, $tags: [TaggingTagInput!]!) {
+  taggingAddTagsToEntity(guid: $guid, tags: $tags) {
+    errors {
+      message
+      type
+    }
+  }
+}`
+
+// serve
+// the fixture's services on a test server, and a client for it
+func serve(t *testing.T, config *nerdgraph.Config) (map[string]*nerdgraph.Service, *client.Client) {
+	t.Helper()
+	services, err := fixture.Services(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := mock.New(services)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.PageSize = 1
+	ts := httptest.NewServer(server)
+	t.Cleanup(ts.Close)
+	c, err := client.New(client.Access{ApiKey: "key", Endpoint: ts.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return services, c
+}
+
+func TestServerLifecycle(t *testing.T) {
+	services, c := serve(t, nil)
+	documents, err := services["aiNotificationsChannel"].OperationDocuments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	channels := client.Page{Path: []string{"actor", "account", "aiNotifications", "channels"}, EntitiesField: "entities", CursorArgument: "cursor", NextCursorField: "nextCursor"}
+	list := func(document string, variables map[string]interface{}) []map[string]interface{} {
+		entities := make([]map[string]interface{}, 0)
+		err := c.Paginate(ctx, document, variables, channels, func(page []map[string]interface{}) bool {
+			entities = append(entities, page...)
+			return true
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entities
+	}
+
+	ids := make([]string, 0)
+	for i := 0; i < 3; i++ {
+		channel := map[string]interface{}{"name": fmt.Sprintf("c%d", i), "type": "EMAIL", "product": "IINT", "destinationId": "d", "properties": []interface{}{}}
+		entity, err := c.Mutate(ctx, documents[nerdgraph.CreateOperation], map[string]interface{}{"accountId": 1, "channel": channel}, "aiNotificationsCreateChannel", "channel", "errors")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entity["name"] != channel["name"] || entity["id"] == nil {
+			t.Fatalf("created %v", entity)
+		}
+		ids = append(ids, fmt.Sprint(entity["id"]))
+	}
+	// A page at a time
+	if entities := list(documents[nerdgraph.ListOperation], map[string]interface{}{"accountId": 1}); len(entities) != 3 {
+		t.Errorf("listed %v", entities)
+	}
+
+	update := map[string]interface{}{"accountId": 1, "channelId": ids[0], "channel": map[string]interface{}{"name": "renamed", "active": false}}
+	if _, err = c.Mutate(ctx, documents[nerdgraph.UpdateOperation], update, "aiNotificationsUpdateChannel", "channel", "errors"); err != nil {
+		t.Fatal(err)
+	}
+	read := list(documents[nerdgraph.ReadOperation], map[string]interface{}{"accountId": 1, "id": ids[0]})
+	if len(read) != 1 || read[0]["name"] != "renamed" || read[0]["type"] != "EMAIL" {
+		t.Errorf("read %v", read)
+	}
+	if _, err = c.Mutate(ctx, tagDocument, map[string]interface{}{"guid": ids[0], "tags": []interface{}{map[string]interface{}{"key": "k", "values": []interface{}{"v"}}}}, "taggingAddTagsToEntity", "", "errors"); err != nil {
+		t.Errorf("tag: %v", err)
+	}
+
+	remove := map[string]interface{}{"accountId": 1, "channelId": ids[0]}
+	if _, err = c.Mutate(ctx, documents[nerdgraph.DeleteOperation], remove, "aiNotificationsDeleteChannel", "", "error"); err != nil {
+		t.Fatal(err)
+	}
+	if read = list(documents[nerdgraph.ReadOperation], map[string]interface{}{"accountId": 1, "id": ids[0]}); len(read) != 0 {
+		t.Errorf("read after delete %v", read)
+	}
+	if _, err = c.Mutate(ctx, documents[nerdgraph.DeleteOperation], remove, "aiNotificationsDeleteChannel", "", "error"); client.ErrorCode(err) != client.NotFound {
+		t.Errorf("delete again: %v", err)
+	}
+}
+
+func TestServerTagging(t *testing.T) {
+	tags := map[string]interface{}{"guid": "nope", "tags": []interface{}{}}
+	tests := []struct {
+		strategy nerdgraph.TaggingStrategy
+		code     string
+	}{
+		// Bound, the entity just doesn't exist
+		{nerdgraph.TaggingEntity, client.NotFound},
+		// Not one of the services' mutations
+		{nerdgraph.TaggingNone, client.InvalidRequest},
+		{nerdgraph.TaggingArgument, client.InvalidRequest},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			config := nerdgraph.NewConfig()
+			config.Tagging.Strategy = tt.strategy
+			_, c := serve(t, config)
+			_, err := c.Mutate(context.Background(), tagDocument, tags, "taggingAddTagsToEntity", "", "errors")
+			if code := client.ErrorCode(err); code != tt.code {
+				t.Errorf("code %q, want %q: %v", code, tt.code, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package mock
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// store
+// a resource's entities by identifier, in insertion order
+type store struct {
+	ids      []string
+	entities map[string]map[string]interface{}
+}
+
+func newStore() *store {
+	return &store{ids: make([]string, 0), entities: make(map[string]map[string]interface{})}
+}
+
+func (s *store) insert(id string, entity map[string]interface{}) {
+	if _, ok := s.entities[id]; !ok {
+		s.ids = append(s.ids, id)
+	}
+	s.entities[id] = entity
+}
+
+func (s *store) get(id string) map[string]interface{} {
+	return s.entities[id]
+}
+
+func (s *store) remove(id string) bool {
+	if _, ok := s.entities[id]; !ok {
+		return false
+	}
+	delete(s.entities, id)
+	for i, e := range s.ids {
+		if e == id {
+			s.ids = append(s.ids[:i], s.ids[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (s *store) list() []map[string]interface{} {
+	entities := make([]map[string]interface{}, 0, len(s.ids))
+	for _, id := range s.ids {
+		entities = append(entities, s.entities[id])
+	}
+	return entities
+}
+
+// patch
+// set the input's fields on the entity, input objects are flattened (NerdGraph entities don't nest their input),
+// explicit nulls remove the field
+func patch(entity map[string]interface{}, input map[string]interface{}) {
+	for k, v := range input {
+		switch value := v.(type) {
+		case nil:
+			delete(entity, k)
+		case map[string]interface{}:
+			patch(entity, value)
+		default:
+			entity[k] = value
+		}
+	}
+}
+
+// newID
+// a GUID-like identifier, base64 as NerdGraph's entity GUIDs are
+func newID(prefix string) string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("mock: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(append([]byte(prefix+"|"), b...))
+}
@@ -0,0 +1,475 @@
+package nerdgraph
+
+import (
+   "fmt"
+   "github.com/vektah/gqlparser/v2/ast"
+   "strings"
+)
+
+// DefaultSelectionDepth how many object levels a selection set follows
+const DefaultSelectionDepth = 3
+
+// QueryPath the query fields from the query root down to the resource's entities, used by read and list
+type QueryPath struct {
+   Fields          []*ast.FieldDefinition // Root first, the last returns the entities or an object wrapping them
+   EntitiesField   string                 // Wrapper's list field, e.g. entities, "" when the last field is the list
+   EntityType      *ast.Definition
+   CursorArgument  string // Last field's pagination argument, "" if not paginated
+   NextCursorField string // Wrapper's next page field
+   FilterArgument  string // Last field's input argument able to filter by identifier, "" if none
+   FilterField     string // The identifier field within FilterArgument
+   FilterType      *ast.Type
+}
+
+// Variable an operation variable, for query paths the argument it's passed to
+type Variable struct {
+   Name     string
+   Type     *ast.Type
+   Field    string
+   Argument string
+}
+
+func (s *Service) GetSchemaDocument() *ast.SchemaDocument {
+   return s.schemaDocument
+}
+
+func (s *Service) GetConfig() *Config {
+   return s.config
+}
+
+// GetOperation
+// the mutation implementing the handler: create | update | delete, nil if the service has none
+func (s *Service) GetOperation(handler string) *ast.FieldDefinition {
+   switch handler {
+   case "create":
+      return s.createDefinition
+   case "update":
+      return s.updateDefinition
+   case "delete":
+      return s.deleteDefinition
+   }
+   return nil
+}
+
+// EntityType
+// the object the mutations create/update, either the payload itself or the payload's object field (e.g. channel)
+func (s *Service) EntityType() *ast.Definition {
+   for _, handler := range []string{"create", "update"} {
+      field := s.GetOperation(handler)
+      if field == nil {
+         continue
+      }
+      if entityField := s.EntityField(field); entityField != "" {
+         return s.schemaDocument.Definitions.ForName(s.schemaDocument.Definitions.ForName(field.Type.Name()).Fields.ForName(entityField).Type.Name())
+      }
+      if payload := s.schemaDocument.Definitions.ForName(field.Type.Name()); payload != nil && identifierField(payload) != "" {
+         return payload
+      }
+   }
+   return nil
+}
+
+// EntityField
+// the payload field holding the entity, "" when the payload is the entity or there's no payload object
+func (s *Service) EntityField(field *ast.FieldDefinition) string {
+   payload := s.schemaDocument.Definitions.ForName(field.Type.Name())
+   if payload == nil || identifierField(payload) != "" {
+      return ""
+   }
+   for _, f := range payload.Fields {
+      if f.Type.Elem != nil || isErrorsField(f.Name) {
+         continue
+      }
+      def := s.schemaDocument.Definitions.ForName(f.Type.Name())
+      if def != nil && def.Kind == ast.Object && identifierField(def) != "" {
+         return f.Name
+      }
+   }
+   return ""
+}
+
+// ErrorsField
+// the payload field reporting failures, "" if none
+func (s *Service) ErrorsField(field *ast.FieldDefinition) string {
+   payload := s.schemaDocument.Definitions.ForName(field.Type.Name())
+   if payload == nil {
+      return ""
+   }
+   for _, f := range payload.Fields {
+      if isErrorsField(f.Name) {
+         return f.Name
+      }
+   }
+   return ""
+}
+
+// IdentifierField
+// the entity field identifying the resource: guid, otherwise id
+func (s *Service) IdentifierField() string {
+   entity := s.EntityType()
+   if entity == nil {
+      return "id"
+   }
+   if field := identifierField(entity); field != "" {
+      return field
+   }
+   return "id"
+}
+
+// IdentifierArgument
+// the update/delete argument carrying the resource identifier: a required ID argument create doesn't have
+func (s *Service) IdentifierArgument(field *ast.FieldDefinition) string {
+   if field == nil {
+      return ""
+   }
+   for _, arg := range field.Arguments {
+      if !arg.Type.NonNull || arg.Type.Elem != nil || (arg.Type.NamedType != "ID" && arg.Type.NamedType != "EntityGuid") {
+         continue
+      }
+      if s.createDefinition != nil && s.createDefinition.Arguments.ForName(arg.Name) != nil {
+         continue
+      }
+      return arg.Name
+   }
+   return ""
+}
+
+// ListQuery
+// breadth first search from the query root for a list of EntityType, nil if there's none
+func (s *Service) ListQuery() *QueryPath {
+   entity := s.EntityType()
+   root := s.queryRoot()
+   if entity == nil || root == nil {
+      return nil
+   }
+
+   type node struct {
+      def  *ast.Definition
+      path []*ast.FieldDefinition
+   }
+   visited := map[string]bool{root.Name: true}
+   queue := []node{{def: root}}
+   for len(queue) > 0 {
+      current := queue[0]
+      queue = queue[1:]
+      if len(current.path) > 6 {
+         break
+      }
+      for _, f := range current.def.Fields {
+         path := append(append(make([]*ast.FieldDefinition, 0, len(current.path)+1), current.path...), f)
+         // The field is the list
+         if f.Type.Elem != nil && f.Type.Name() == entity.Name {
+            return s.newQueryPath(path, "", entity)
+         }
+         def := s.schemaDocument.Definitions.ForName(f.Type.Name())
+         if def == nil || def.Kind != ast.Object || f.Type.Elem != nil {
+            continue
+         }
+         // The field wraps the list, e.g. {entities, nextCursor}
+         for _, wrapped := range def.Fields {
+            if wrapped.Type.Elem != nil && wrapped.Type.Name() == entity.Name {
+               queryPath := s.newQueryPath(path, wrapped.Name, entity)
+               if def.Fields.ForName("nextCursor") != nil {
+                  queryPath.NextCursorField = "nextCursor"
+               }
+               return queryPath
+            }
+         }
+         if !visited[def.Name] {
+            visited[def.Name] = true
+            queue = append(queue, node{def: def, path: path})
+         }
+      }
+   }
+   return nil
+}
+
+func (s *Service) newQueryPath(path []*ast.FieldDefinition, entitiesField string, entity *ast.Definition) *QueryPath {
+   queryPath := &QueryPath{Fields: path, EntitiesField: entitiesField, EntityType: entity}
+   last := path[len(path)-1]
+   if last.Arguments.ForName("cursor") != nil {
+      queryPath.CursorArgument = "cursor"
+   }
+   identifier := s.IdentifierField()
+   for _, arg := range last.Arguments {
+      def := s.schemaDocument.Definitions.ForName(arg.Type.Name())
+      if def == nil || def.Kind != ast.InputObject {
+         continue
+      }
+      if f := def.Fields.ForName(identifier); f != nil {
+         queryPath.FilterArgument = arg.Name
+         queryPath.FilterField = identifier
+         queryPath.FilterType = &ast.Type{NamedType: f.Type.Name(), NonNull: true}
+         break
+      }
+   }
+   return queryPath
+}
+
+// Variables
+// the query path's required arguments, account(id:) is named accountId to line up with the mutation arguments
+func (q *QueryPath) Variables() []*Variable {
+   variables := make([]*Variable, 0)
+   for _, f := range q.Fields {
+      for _, arg := range f.Arguments {
+         if !arg.Type.NonNull {
+            continue
+         }
+         name := arg.Name
+         if name == "id" || name == "guid" {
+            name = f.Name + strings.ToUpper(name[:1]) + name[1:]
+         }
+         variables = append(variables, &Variable{Name: name, Type: arg.Type, Field: f.Name, Argument: arg.Name})
+      }
+   }
+   return variables
+}
+
+// MutationDocument
+// the mutation with a variable per argument, selecting the payload
+func (s *Service) MutationDocument(field *ast.FieldDefinition, depth int) string {
+   var b strings.Builder
+   b.WriteString("mutation " + operationName(field.Name))
+   if len(field.Arguments) > 0 {
+      vars := make([]string, 0, len(field.Arguments))
+      args := make([]string, 0, len(field.Arguments))
+      for _, arg := range field.Arguments {
+         vars = append(vars, fmt.Sprintf("$%s: %s", arg.Name, arg.Type.String()))
+         args = append(args, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+      }
+      b.WriteString("(" + strings.Join(vars, ", ") + ")")
+      b.WriteString(" {\n  " + field.Name + "(" + strings.Join(args, ", ") + ")")
+   } else {
+      b.WriteString(" {\n  " + field.Name)
+   }
+   b.WriteString(s.selectionSet(field.Type.Name(), depth, "  ", map[string]bool{}))
+   b.WriteString("\n}\n")
+   return b.String()
+}
+
+// ListQueryDocument
+// the query down the path selecting the entities, with the cursor when paginated
+func (s *Service) ListQueryDocument(q *QueryPath, depth int) string {
+   return s.queryDocument(q, depth, false)
+}
+
+// ReadQueryDocument
+// the list query filtered by identifier when the API supports it
+func (s *Service) ReadQueryDocument(q *QueryPath, depth int) string {
+   return s.queryDocument(q, depth, true)
+}
+
+func (s *Service) queryDocument(q *QueryPath, depth int, read bool) string {
+   variables := q.Variables()
+   vars := make([]string, 0, len(variables)+1)
+   for _, v := range variables {
+      vars = append(vars, fmt.Sprintf("$%s: %s", v.Name, v.Type.String()))
+   }
+   filter := read && q.FilterArgument != ""
+   if filter {
+      vars = append(vars, fmt.Sprintf("$%s: %s", q.FilterField, q.FilterType.String()))
+   }
+   if !read && q.CursorArgument != "" {
+      vars = append(vars, "$cursor: String")
+   }
+
+   name := "List"
+   if read {
+      name = "Read"
+   }
+   var b strings.Builder
+   b.WriteString("query " + name + q.EntityType.Name)
+   if len(vars) > 0 {
+      b.WriteString("(" + strings.Join(vars, ", ") + ")")
+   }
+   b.WriteString(" {\n")
+
+   indent := "  "
+   last := len(q.Fields) - 1
+   for i, f := range q.Fields {
+      args := make([]string, 0)
+      for _, v := range variables {
+         if v.Field == f.Name {
+            args = append(args, fmt.Sprintf("%s: $%s", v.Argument, v.Name))
+         }
+      }
+      if i == last && filter {
+         args = append(args, fmt.Sprintf("%s: {%s: $%s}", q.FilterArgument, q.FilterField, q.FilterField))
+      }
+      if i == last && !read && q.CursorArgument != "" {
+         args = append(args, q.CursorArgument+": $cursor")
+      }
+      b.WriteString(indent + f.Name)
+      if len(args) > 0 {
+         b.WriteString("(" + strings.Join(args, ", ") + ")")
+      }
+      if i < last {
+         b.WriteString(" {\n")
+         indent += "  "
+      }
+   }
+
+   if q.EntitiesField == "" {
+      b.WriteString(s.selectionSet(q.EntityType.Name, depth, indent, map[string]bool{}))
+   } else {
+      b.WriteString(" {\n" + indent + "  " + q.EntitiesField)
+      b.WriteString(s.selectionSet(q.EntityType.Name, depth, indent+"  ", map[string]bool{}))
+      if q.NextCursorField != "" {
+         b.WriteString("\n" + indent + "  " + q.NextCursorField)
+      }
+      b.WriteString("\n" + indent + "}")
+   }
+   for i := last; i > 0; i-- {
+      indent = indent[2:]
+      b.WriteString("\n" + indent + "}")
+   }
+   b.WriteString("\n}\n")
+   return b.String()
+}
+
+// selectionSet
+// " { ... }" selecting typeName's fields without required arguments, objects down to depth. The entity only selects
+// the fields backing CloudFormation properties, unions select each member through an inline fragment
+func (s *Service) selectionSet(typeName string, depth int, indent string, visited map[string]bool) string {
+   def := s.schemaDocument.Definitions.ForName(typeName)
+   if def == nil || (def.Kind != ast.Object && def.Kind != ast.Interface && def.Kind != ast.Union) {
+      return ""
+   }
+   lines := make([]string, 0, len(def.Fields))
+   visited[typeName] = true
+   defer delete(visited, typeName)
+
+   if def.Kind == ast.Union {
+      lines = append(lines, indent+"  __typename")
+      for _, member := range def.Types {
+         if visited[member] {
+            continue
+         }
+         if sub := s.selectionSet(member, depth, indent+"  ", visited); sub != "" {
+            lines = append(lines, indent+"  ... on "+member+sub)
+         }
+      }
+      return " {\n" + strings.Join(lines, "\n") + "\n" + indent + "}"
+   }
+
+   var include map[string]bool
+   if entity := s.EntityType(); entity != nil && entity.Name == typeName {
+      include = s.entityFields()
+   }
+   for _, f := range def.Fields {
+      if hasRequiredArguments(f) || (include != nil && !include[f.Name]) {
+         continue
+      }
+      fieldDef := s.schemaDocument.Definitions.ForName(f.Type.Name())
+      if fieldDef == nil || fieldDef.Kind == ast.Scalar || fieldDef.Kind == ast.Enum {
+         lines = append(lines, indent+"  "+f.Name)
+         continue
+      }
+      // Objects past the depth limit, and cycles, aren't selected
+      if depth <= 1 || visited[fieldDef.Name] {
+         continue
+      }
+      if sub := s.selectionSet(fieldDef.Name, depth-1, indent+"  ", visited); sub != "" {
+         lines = append(lines, indent+"  "+f.Name+sub)
+      }
+   }
+   if len(lines) == 0 {
+      return ""
+   }
+   return " {\n" + strings.Join(lines, "\n") + "\n" + indent + "}"
+}
+
+// entityFields
+// the entity's fields backing a CloudFormation property: top-level properties, the flattened input object
+// properties, and the identifier. nil, select everything, if there are none
+func (s *Service) entityFields() map[string]bool {
+   entity := s.EntityType()
+   doc := s.Document()
+   fields := map[string]bool{s.IdentifierField(): true}
+   add := func(name string) {
+      name = strings.ToLower(name[:1]) + name[1:]
+      if entity.Fields.ForName(name) != nil {
+         fields[name] = true
+      }
+   }
+   for name, property := range doc.Properties {
+      add(name)
+      ref, found := strings.CutPrefix(property.Ref, "#/definitions/")
+      if !found {
+         continue
+      }
+      if def := doc.Definitions[ref]; def != nil {
+         for field := range def.Properties {
+            add(field)
+         }
+      }
+   }
+   if len(fields) == 1 {
+      return nil
+   }
+   return fields
+}
+
+// RootMutation
+// a mutation outside the service, e.g. taggingAddTagsToEntity, nil if the schema doesn't have it
+func (s *Service) RootMutation(name string) *ast.FieldDefinition {
+   for _, sd := range s.schemaDocument.Schema {
+      for _, op := range sd.OperationTypes {
+         if op.Operation != ast.Mutation {
+            continue
+         }
+         if def := s.schemaDocument.Definitions.ForName(op.Type); def != nil {
+            if field := def.Fields.ForName(name); field != nil {
+               return field
+            }
+         }
+      }
+   }
+   return nil
+}
+
+func (s *Service) queryRoot() *ast.Definition {
+   name := "Query"
+   for _, sd := range s.schemaDocument.Schema {
+      for _, op := range sd.OperationTypes {
+         if op.Operation == ast.Query {
+            name = op.Type
+         }
+      }
+   }
+   return s.schemaDocument.Definitions.ForName(name)
+}
+
+func identifierField(def *ast.Definition) string {
+   for _, name := range []string{"guid", "id"} {
+      if f := def.Fields.ForName(name); f != nil && f.Type.Elem == nil && isIdentifierType(f.Type.NamedType) {
+         return name
+      }
+   }
+   return ""
+}
+
+func isIdentifierType(name string) bool {
+   return name == "ID" || name == "EntityGuid" || name == "String" || name == "Int"
+}
+
+func isErrorsField(name string) bool {
+   return name == "errors" || name == "error"
+}
+
+func hasRequiredArguments(f *ast.FieldDefinition) bool {
+   for _, arg := range f.Arguments {
+      if arg.Type.NonNull && arg.DefaultValue == nil {
+         return true
+      }
+   }
+   return false
+}
+
+// operationName aiNotificationsCreateChannel -> AiNotificationsCreateChannel
+func operationName(name string) string {
+   if name == "" {
+      return name
+   }
+   return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -12,6 +12,7 @@ func recurseFieldTypes(document *ast.SchemaDocument, jsonDocument *model.Documen
    def := document.Definitions.ForName(fieldDef.Type.NamedType)
    for _, field := range def.Fields {
       // need for if field.Arguments == nil or != nil, then different (note: if nil, carry on as usual)
+      description := field.Description
       field.Description = ""
 
       def := handleDefinition(document, field.Type)
@@ -28,6 +29,7 @@ func recurseFieldTypes(document *ast.SchemaDocument, jsonDocument *model.Documen
          log.Errorf("error processing Definition: %v", err)
          continue
       }
+      property.GraphQLDescription = description
       // Recursively travel down the field.Type
       jsonDocument.SplunkTypeDefinitions(field.Type, document)
       // Add the property to the output model
@@ -39,7 +41,9 @@ func recurseFieldTypes(document *ast.SchemaDocument, jsonDocument *model.Documen
 // handle recursion through definition arguments
 func recurseArgTypes(document *ast.SchemaDocument, jsonDocument *model.Document, def *ast.FieldDefinition) {
    // NOTE: args go in JSON properties!
+   mutationName := def.Name
    for _, argDef := range def.Arguments { // for each argument under this target mutation
+      description := argDef.Description
       argDef.Description = ""
       log.Printf("main: argDef: %+v", argDef)
 
@@ -57,10 +61,12 @@ func recurseArgTypes(document *ast.SchemaDocument, jsonDocument *model.Document,
          log.Errorf("error processing Definition: %v", err)
          continue
       }
+      property.GraphQLDescription = description
       // Recursively travel down the argDef.Type
       jsonDocument.SplunkTypeDefinitions(argDef.Type, document)
       // Add the property to the output model
       jsonDocument.AddProperty(argDef.Name, property.AsSchemaProperty())
+      jsonDocument.AddArgumentPath(argDef.Name, mutationName+"."+argDef.Name)
    }
 }
 
@@ -0,0 +1,92 @@
+package nerdgraph
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   log "github.com/sirupsen/logrus"
+   "sort"
+   "strings"
+)
+
+// LinkRelationships
+// cross-resource pass, set relationshipRef on ID properties that hold another generated resource's identifier.
+// Config relationships win, otherwise by naming convention: DestinationId -> the service whose name ends with Destination
+func LinkRelationships(services map[string]*Service) {
+   // Deterministic order, the same input always gives the same output
+   names := make([]string, 0, len(services))
+   for name := range services {
+      names = append(names, name)
+   }
+   sort.Strings(names)
+
+   for _, name := range names {
+      s := services[name]
+      doc := s.Document()
+      for propertyName, property := range doc.Properties {
+         s.linkProperty(propertyName, property, services, names)
+      }
+      for _, definition := range doc.Definitions {
+         for propertyName, property := range definition.Properties {
+            s.linkProperty(propertyName, property, services, names)
+         }
+      }
+   }
+}
+
+func (s *Service) linkProperty(propertyName string, property *model.Property, services map[string]*Service, names []string) {
+   if !property.IsIDType() {
+      return
+   }
+   targetName, found := s.config.Relationship(s.serviceName, propertyName)
+   if !found {
+      targetName = conventionTarget(s.serviceName, propertyName, names)
+   }
+   if targetName == "" {
+      return
+   }
+   target := services[targetName]
+   if target == nil {
+      log.Warnf("linkProperty: %s.%s: unknown relationship target: %s", s.serviceName, propertyName, targetName)
+      return
+   }
+   targetDoc := target.Document()
+   if len(targetDoc.PrimaryIdentifier) == 0 {
+      log.Warnf("linkProperty: %s.%s: %s has no primaryIdentifier", s.serviceName, propertyName, targetName)
+      return
+   }
+   property.RelationshipRef = &model.RelationshipRef{
+      TypeName:     targetDoc.TypeName,
+      PropertyPath: targetDoc.PrimaryIdentifier[0],
+   }
+   log.Debugf("linkProperty: %s.%s -> %s", s.serviceName, propertyName, targetDoc.TypeName)
+}
+
+// conventionTarget
+// XxxId -> the other service ending with Xxx, the one sharing the longest prefix (namespace) with serviceName wins
+func conventionTarget(serviceName string, propertyName string, names []string) string {
+   noun, found := strings.CutSuffix(propertyName, "Id")
+   if !found || noun == "" {
+      return ""
+   }
+   noun = strings.ToLower(noun)
+   best := ""
+   bestPrefix := -1
+   for _, name := range names {
+      // Its own id isn't a relationship
+      if name == serviceName || !strings.HasSuffix(strings.ToLower(name), noun) {
+         continue
+      }
+      if prefix := commonPrefixLength(serviceName, name); prefix > bestPrefix {
+         best = name
+         bestPrefix = prefix
+      }
+   }
+   return best
+}
+
+func commonPrefixLength(a string, b string) int {
+   i := 0
+   for i < len(a) && i < len(b) && a[i] == b[i] {
+      i++
+   }
+   return i
+}
@@ -0,0 +1,59 @@
+package nerdgraph
+
+import (
+   "embed"
+   "fmt"
+   "io/fs"
+   "os"
+   "path"
+   "path/filepath"
+   "strings"
+)
+
+/*
+The client and mapping packages generated handlers import. A generated project is its own module, it can't import this
+one, so they're copied into <project>/internal/nerdgraph. Both only use the standard library to keep the copy standalone.
+*/
+
+//go:embed client/*.go mapping/*.go
+var runtime embed.FS
+
+// RuntimePackages the packages EmitRuntime copies
+var RuntimePackages = []string{"client", "mapping"}
+
+const runtimeHeader = "// Code copied by gqlparser from pkg/nerdgraph/%s. DO NOT EDIT.\n\n"
+
+// RuntimeImport
+// the import path of a runtime package in the generated module, e.g. <module>/internal/nerdgraph/client
+func RuntimeImport(module string, pkg string) string {
+   return path.Join(module, "internal", "nerdgraph", pkg)
+}
+
+// EmitRuntime
+// copy the runtime packages, tests left out, into dir/internal/nerdgraph
+func EmitRuntime(dir string) error {
+   for _, pkg := range RuntimePackages {
+      files, err := fs.Glob(runtime, pkg+"/*.go")
+      if err != nil {
+         return fmt.Errorf("runtime: %w", err)
+      }
+      pkgDir := filepath.Join(dir, "internal", "nerdgraph", pkg)
+      if err = os.MkdirAll(pkgDir, 0755); err != nil {
+         return fmt.Errorf("runtime: %w", err)
+      }
+      for _, file := range files {
+         if strings.HasSuffix(file, "_test.go") {
+            continue
+         }
+         b, err := runtime.ReadFile(file)
+         if err != nil {
+            return fmt.Errorf("runtime: %w", err)
+         }
+         source := append([]byte(fmt.Sprintf(runtimeHeader, pkg)), b...)
+         if err = os.WriteFile(filepath.Join(pkgDir, path.Base(file)), source, 0644); err != nil {
+            return fmt.Errorf("runtime: %w", err)
+         }
+      }
+   }
+   return nil
+}
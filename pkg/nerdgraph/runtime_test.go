@@ -0,0 +1,48 @@
+package nerdgraph_test
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+   "go/parser"
+   "go/token"
+   "os"
+   "path/filepath"
+   "strconv"
+   "strings"
+   "testing"
+)
+
+func TestEmitRuntime(t *testing.T) {
+   dir := t.TempDir()
+   if err := nerdgraph.EmitRuntime(dir); err != nil {
+      t.Fatal(err)
+   }
+   for _, pkg := range nerdgraph.RuntimePackages {
+      files, err := filepath.Glob(filepath.Join(dir, "internal", "nerdgraph", pkg, "*.go"))
+      if err != nil || len(files) == 0 {
+         t.Fatalf("%s: no files %v", pkg, err)
+      }
+      for _, file := range files {
+         if strings.HasSuffix(file, "_test.go") {
+            t.Errorf("%s: test copied", file)
+         }
+         b, err := os.ReadFile(file)
+         if err != nil {
+            t.Fatal(err)
+         }
+         if !strings.HasPrefix(string(b), "// Code copied by gqlparser from pkg/nerdgraph/"+pkg+". DO NOT EDIT.") {
+            t.Errorf("%s: no header", file)
+         }
+         // The copy has to build in the generated module, so the standard library only
+         f, err := parser.ParseFile(token.NewFileSet(), file, b, parser.ImportsOnly)
+         if err != nil {
+            t.Fatal(err)
+         }
+         for _, spec := range f.Imports {
+            path, _ := strconv.Unquote(spec.Path.Value)
+            if strings.Contains(strings.Split(path, "/")[0], ".") || strings.HasPrefix(path, "GraphQLSchema-to-CloudFormationSchema") {
+               t.Errorf("%s imports %s", file, path)
+            }
+         }
+      }
+   }
+}
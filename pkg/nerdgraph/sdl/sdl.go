@@ -0,0 +1,296 @@
+package sdl
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"sort"
+	"strings"
+)
+
+/*
+GraphQL SDL from resource schemas, the reverse of the generator: SDL -> Document -> SDL is structurally the same for the
+types a resource uses
+
+- Definitions become enums, unions, custom scalars and input types, or object types when they're union members or only
+  read-only properties use them. Field names are the GraphQL ones (Property.OriginalName), required is non-null
+- The resource's writable properties become input <Resource>Properties, the readOnlyProperties type <Resource>Attributes
+- List items are non-null, the schema doesn't say, and IDs come back as String. The typeConfiguration's definitions
+  aren't part of the API, they're left out
+
+The same definition from several documents is written once, the first one wins when they differ.
+*/
+
+// New
+// the documents' types as SDL, validated. The SDL comes back with the validation error too, for review
+func New(docs ...*model.Document) (string, error) {
+	types := make(map[string]string)
+	origins := make(map[string]string)
+	names := make([]string, 0)
+	add := func(doc *model.Document, name string, s string) {
+		if previous, ok := types[name]; ok {
+			if previous != s {
+				log.Warnf("sdl: %s differs between %s and %s, keeping the first", name, origins[name], doc.TypeName)
+			}
+			return
+		}
+		types[name] = s
+		origins[name] = doc.TypeName
+		names = append(names, name)
+	}
+
+	for _, doc := range docs {
+		r := &reverser{doc: doc, output: make(map[string]bool), input: make(map[string]bool)}
+		r.classify()
+		defNames := make([]string, 0, len(doc.Definitions))
+		for name := range doc.Definitions {
+			if r.input[name] || r.output[name] {
+				defNames = append(defNames, name)
+			}
+		}
+		sort.Strings(defNames)
+		for _, name := range defNames {
+			add(doc, name, r.definition(name, doc.Definitions[name]))
+		}
+		resource := resourceName(doc)
+		properties, attributes := r.resource(resource)
+		if properties != "" {
+			add(doc, resource+"Properties", properties)
+		}
+		if attributes != "" {
+			add(doc, resource+"Attributes", attributes)
+		}
+	}
+
+	var b strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(types[name])
+	}
+	s := b.String()
+	if _, err := gqlparser.LoadSchema(&ast.Source{Name: "reverse", Input: s}); err != nil {
+		return s, fmt.Errorf("sdl: %w", err)
+	}
+	return s, nil
+}
+
+// resourceName
+// the last part of TypeName with an uppercase first letter, NewRelic::Observability::aiNotificationsChannel ->
+// AiNotificationsChannel
+func resourceName(doc *model.Document) string {
+	parts := strings.Split(doc.TypeName, "::")
+	name := parts[len(parts)-1]
+	if name == "" {
+		return "Resource"
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+type reverser struct {
+	doc    *model.Document
+	output map[string]bool // Definitions written as object types
+	input  map[string]bool // Definitions written as input types
+}
+
+// classify
+// the definitions writable properties reach are inputs, the ones read-only properties or union members reach outputs
+func (r *reverser) classify() {
+	readOnly := make(map[string]bool)
+	for _, path := range r.doc.ReadOnlyProperties {
+		readOnly[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	for name, property := range r.doc.Properties {
+		if readOnly[name] {
+			r.reach(property, r.output)
+		} else {
+			r.reach(property, r.input)
+		}
+	}
+	for name := range r.input {
+		if r.output[name] && len(r.doc.Definitions[name].Properties) > 0 {
+			log.Warnf("sdl: %s: %s is both an input and an output type, it's written as an input", r.doc.TypeName, name)
+		}
+	}
+}
+
+// reach
+// mark the definitions the property references, union members as outputs
+func (r *reverser) reach(property *model.Property, seen map[string]bool) {
+	refs := []string{property.Ref}
+	if property.Items != nil {
+		refs = append(refs, property.Items.Ref)
+	}
+	for _, ref := range refs {
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		def := r.doc.Definitions[name]
+		if ref == "" || def == nil || seen[name] {
+			continue
+		}
+		seen[name] = true
+		r.reach(def, seen)
+	}
+	for _, p := range property.Properties {
+		r.reach(p, seen)
+	}
+	for _, member := range append(append([]*model.Item{}, property.AnyOf...), property.OneOf...) {
+		r.reach(&model.Property{Ref: member.Ref}, r.output)
+	}
+}
+
+// definition
+// the SDL for a definition
+func (r *reverser) definition(name string, def *model.Property) string {
+	var b strings.Builder
+	writeDescription(&b, "", description(def))
+	members := append(append([]*model.Item{}, def.AnyOf...), def.OneOf...)
+	switch {
+	case len(def.Enum) > 0:
+		b.WriteString("enum " + name + " {\n")
+		for _, value := range def.Enum {
+			b.WriteString("  " + value + "\n")
+		}
+		b.WriteString("}\n")
+	case len(members) > 0:
+		refs := make([]string, 0, len(members))
+		for _, member := range members {
+			refs = append(refs, strings.TrimPrefix(member.Ref, "#/definitions/"))
+		}
+		b.WriteString("union " + name + " = " + strings.Join(refs, " | ") + "\n")
+	case len(def.Properties) > 0 || def.Type == "object":
+		kind := "input"
+		if r.output[name] && !r.input[name] {
+			kind = "type"
+		}
+		r.fields(&b, kind, name, def.Properties, nil)
+	default:
+		b.WriteString("scalar " + name + "\n")
+	}
+	return b.String()
+}
+
+// resource
+// the input for the writable top-level properties and the type for the read-only ones
+func (r *reverser) resource(name string) (string, string) {
+	readOnly := make(map[string]bool)
+	for _, path := range r.doc.ReadOnlyProperties {
+		readOnly[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	writable := make(map[string]*model.Property)
+	attributes := make(map[string]*model.Property)
+	for n, property := range r.doc.Properties {
+		if readOnly[n] {
+			attributes[n] = property
+		} else {
+			writable[n] = property
+		}
+	}
+	var properties, attrs strings.Builder
+	if len(writable) > 0 {
+		writeDescription(&properties, "", strings.TrimSpace(r.doc.TypeName+" properties. "+r.doc.Description))
+		r.fields(&properties, "input", name+"Properties", writable, r.doc.Required)
+	}
+	if len(attributes) > 0 {
+		writeDescription(&attrs, "", r.doc.TypeName+" read-only properties, Fn::GetAtt")
+		r.fields(&attrs, "type", name+"Attributes", attributes, nil)
+	}
+	return properties.String(), attrs.String()
+}
+
+// fields
+// kind name { field: Type ... }, non-null when IsRequired or in required (the top level keeps it in the document)
+func (r *reverser) fields(b *strings.Builder, kind string, name string, properties map[string]*model.Property, required []string) {
+	isRequired := make(map[string]bool)
+	for _, n := range required {
+		isRequired[n] = true
+	}
+	b.WriteString(kind + " " + name + " {\n")
+	keys := make([]string, 0, len(properties))
+	for n := range properties {
+		keys = append(keys, n)
+	}
+	sort.Strings(keys)
+	for _, n := range keys {
+		property := properties[n]
+		writeDescription(b, "  ", description(property))
+		t := r.typeOf(property)
+		if property.IsRequired || isRequired[n] {
+			t += "!"
+		}
+		b.WriteString("  " + property.OriginalName(n) + ": " + t)
+		if kind == "input" && property.Default != nil {
+			b.WriteString(" = " + r.value(property, property.Default))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
+}
+
+// typeOf
+// the GraphQL type, without the top-level non-null
+func (r *reverser) typeOf(property *model.Property) string {
+	if property.Type == "array" && property.Items != nil {
+		item := strings.TrimPrefix(property.Items.Ref, "#/definitions/")
+		if item == "" {
+			item = model.BuiltInName(property.Items.Type)
+		}
+		return "[" + item + "!]"
+	}
+	if property.Ref != "" {
+		return strings.TrimPrefix(property.Ref, "#/definitions/")
+	}
+	if name := model.BuiltInName(property.Type); name != "" {
+		return name
+	}
+	if property.Name != "" {
+		return property.Name
+	}
+	return "String"
+}
+
+// value
+// a default as a GraphQL value, enum values bare
+func (r *reverser) value(property *model.Property, v interface{}) string {
+	if s, ok := v.(string); ok {
+		if def := r.doc.Definitions[strings.TrimPrefix(property.Ref, "#/definitions/")]; def != nil && len(def.Enum) > 0 {
+			return s
+		}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "null"
+	}
+	return string(b)
+}
+
+// description
+// the schema's description, the GraphQL one when there's none
+func description(p *model.Property) string {
+	if p.Description != "" {
+		return p.Description
+	}
+	return p.GraphQLDescription
+}
+
+// writeDescription
+// a block string, nothing when it's empty
+func writeDescription(b *strings.Builder, indent string, s string) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return
+	}
+	s = strings.ReplaceAll(s, `"""`, `\"""`)
+	if !strings.Contains(s, "\n") {
+		b.WriteString(indent + `"""` + s + `"""` + "\n")
+		return
+	}
+	b.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(s, "\n") {
+		b.WriteString(strings.TrimRight(indent+line, " ") + "\n")
+	}
+	b.WriteString(indent + `"""` + "\n")
+}
@@ -0,0 +1,197 @@
+package sdl_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph/sdl"
+	"encoding/json"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// documents
+// the fixture's resource schemas, as generated or as they're written and read back (the reverse command's input), and
+// the create mutations by resource name
+func documents(t *testing.T, published bool) ([]*model.Document, map[string]*ast.FieldDefinition) {
+	t.Helper()
+	services, err := fixture.Services(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	docs := make([]*model.Document, 0, len(names))
+	creates := make(map[string]*ast.FieldDefinition)
+	for _, name := range names {
+		doc := services[name].Document()
+		if published {
+			b, err := json.Marshal(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc = &model.Document{}
+			if err = json.Unmarshal(b, doc); err != nil {
+				t.Fatal(err)
+			}
+		}
+		docs = append(docs, doc)
+		creates[strings.ToUpper(name[:1])+name[1:]] = services[name].GetOperation("create")
+	}
+	return docs, creates
+}
+
+// typeName
+// the GraphQL type as the reverse writes it, IDs come back as String
+func typeName(t *ast.Type) string {
+	if t.Elem != nil {
+		s := "[" + typeName(t.Elem) + "]"
+		if t.NonNull {
+			s += "!"
+		}
+		return s
+	}
+	s := t.NamedType
+	if s == "ID" {
+		s = "String"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// compareFields
+// the fields in want against the ones in got, by name. extra are the fields only got is expected to have
+func compareFields(t *testing.T, name string, got ast.FieldList, want ast.FieldList, descriptions bool, extra ...string) {
+	t.Helper()
+	expected := make(map[string]bool)
+	for _, f := range extra {
+		expected[f] = true
+	}
+	for _, w := range want {
+		g := got.ForName(w.Name)
+		if g == nil {
+			t.Errorf("%s: no field %s", name, w.Name)
+			continue
+		}
+		if typeName(g.Type) != typeName(w.Type) {
+			t.Errorf("%s.%s: got %s, want %s", name, w.Name, typeName(g.Type), typeName(w.Type))
+		}
+		if descriptions && g.Description != w.Description {
+			t.Errorf("%s.%s: got description %q, want %q", name, w.Name, g.Description, w.Description)
+		}
+	}
+	for _, g := range got {
+		if want.ForName(g.Name) == nil && !expected[g.Name] {
+			t.Errorf("%s: unexpected field %s", name, g.Name)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	// SDL -> Document -> SDL is structurally the same. The published schema doesn't keep the GraphQL descriptions, only
+	// the generated documents come back with them
+	tests := []struct {
+		name         string
+		published    bool
+		descriptions bool
+	}{
+		{"generated", false, true},
+		{"published", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs, creates := documents(t, tt.published)
+			reversed, err := sdl.New(docs...)
+			if err != nil {
+				t.Fatalf("%v\n%s", err, reversed)
+			}
+			got, gqlErr := gqlparser.LoadSchema(&ast.Source{Name: "reverse", Input: reversed})
+			if gqlErr != nil {
+				t.Fatal(gqlErr)
+			}
+			want, gqlErr := gqlparser.LoadSchema(&ast.Source{Name: "schema.graphql", Input: fixture.Schema})
+			if gqlErr != nil {
+				t.Fatal(gqlErr)
+			}
+
+			// The input types the resources use are the API's, merged ones can have another input type's fields too, e.g.
+			// ChannelInput gets ChannelUpdate's active
+			merged := map[string][]string{"AiNotificationsChannelInput": {"active"}}
+			// Types the schema adds: the tag definition and each resource's properties and attributes
+			generated := map[string]bool{"Tag": true}
+			for resource := range creates {
+				generated[resource+"Properties"] = true
+				generated[resource+"Attributes"] = true
+			}
+			for name, def := range got.Types {
+				if def.BuiltIn || generated[name] {
+					continue
+				}
+				w := want.Types[name]
+				if w == nil {
+					t.Errorf("%s isn't in the API", name)
+					continue
+				}
+				if def.Kind != w.Kind {
+					t.Errorf("%s: got %s, want %s", name, def.Kind, w.Kind)
+				}
+				if tt.descriptions && def.Description != w.Description {
+					t.Errorf("%s: got description %q, want %q", name, def.Description, w.Description)
+				}
+				switch def.Kind {
+				case ast.Enum:
+					gotValues, wantValues := make([]string, 0), make([]string, 0)
+					for _, v := range def.EnumValues {
+						gotValues = append(gotValues, v.Name)
+					}
+					for _, v := range w.EnumValues {
+						wantValues = append(wantValues, v.Name)
+					}
+					sort.Strings(wantValues)
+					if strings.Join(gotValues, ",") != strings.Join(wantValues, ",") {
+						t.Errorf("%s: got %v, want %v", name, gotValues, wantValues)
+					}
+				case ast.InputObject:
+					compareFields(t, name, def.Fields, w.Fields, tt.descriptions, merged[name]...)
+				}
+			}
+
+			// The properties are the create mutation's arguments, the API's descriptions included
+			for resource, create := range creates {
+				properties := got.Types[resource+"Properties"]
+				if properties == nil {
+					t.Errorf("no %sProperties", resource)
+					continue
+				}
+				mutation := want.Mutation.Fields.ForName(create.Name)
+				arguments := make(ast.FieldList, 0, len(mutation.Arguments))
+				for _, arg := range mutation.Arguments {
+					arguments = append(arguments, &ast.FieldDefinition{Name: arg.Name, Type: arg.Type, Description: arg.Description})
+				}
+				compareFields(t, resource+"Properties", properties.Fields, arguments, tt.descriptions, "tags")
+				if attributes := got.Types[resource+"Attributes"]; attributes == nil || attributes.Fields.ForName("guid") == nil {
+					t.Errorf("%sAttributes has no guid", resource)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	// The SDL comes back with the error, for review
+	doc := &model.Document{}
+	if err := json.Unmarshal([]byte(`{"typeName": "NewRelic::Observability::thing", "properties": {"Thing": {"$ref": "#/definitions/Missing"}}, "definitions": {}}`), doc); err != nil {
+		t.Fatal(err)
+	}
+	s, err := sdl.New(doc)
+	if err == nil || !strings.Contains(s, "thing: Missing") {
+		t.Errorf("got %v for\n%s", err, s)
+	}
+}
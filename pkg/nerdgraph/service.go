@@ -6,6 +6,7 @@ import (
    log "github.com/sirupsen/logrus"
    "github.com/vektah/gqlparser/v2/ast"
    "os"
+   "path/filepath"
    "strings"
 )
 
@@ -15,35 +16,23 @@ type Service struct {
    updateDefinition *ast.FieldDefinition
    deleteDefinition *ast.FieldDefinition
    schemaDocument   *ast.SchemaDocument
+   config           *Config
+   document         *model.Document
 }
 
-var services = make(map[string]*Service)
+// Services by schema document and name, a service collects its create/update/delete mutations over NewService calls.
+// Each document has its own, diff loads two snapshots
+var services = make(map[*ast.SchemaDocument]map[string]*Service)
 
-func NewService(definition *ast.FieldDefinition, document *ast.SchemaDocument) *Service {
+func NewService(definition *ast.FieldDefinition, document *ast.SchemaDocument, config *Config) *Service {
    serviceName := ParseServiceName(definition.Name)
-   service := services[serviceName]
+   if services[document] == nil {
+      services[document] = make(map[string]*Service)
+   }
+   service := services[document][serviceName]
    if service == nil {
       service = &Service{serviceName: serviceName}
-      services[serviceName] = service
-   }
-
-   // Insert the Tags property
-   entity := document.Definitions.ForName("Entity")
-   if entity != nil {
-      tags := entity.Fields.ForName("tags")
-      if tags != nil {
-         tagArg := ast.ArgumentDefinition{
-            Description:              "",
-            Name:                     "tags",
-            DefaultValue:             nil,
-            Type:                     tags.Type,
-            Directives:               nil,
-            Position:                 nil,
-            BeforeDescriptionComment: nil,
-            AfterDescriptionComment:  nil,
-         }
-         definition.Arguments = append(definition.Arguments, &tagArg)
-      }
+      services[document][serviceName] = service
    }
 
    if strings.Contains(definition.Name, "Create") {
@@ -57,6 +46,7 @@ func NewService(definition *ast.FieldDefinition, document *ast.SchemaDocument) *
       return nil
    }
    service.schemaDocument = document
+   service.config = config
    return service
 }
 
@@ -67,19 +57,76 @@ func ParseServiceName(name string) string {
    return name
 }
 
-func (s *Service) Emit() {
+func (s *Service) Emit(outDir string) {
+   s.toFile(s.Document(), outDir)
+}
+
+// Document
+// the CloudFormation resource schema, built on first use
+func (s *Service) Document() *model.Document {
+   if s.document != nil {
+      return s.document
+   }
    doc := model.NewDocument()
    doc.TypeName = "NewRelic::Observability::" + s.serviceName
+   for _, definition := range []*ast.FieldDefinition{s.createDefinition, s.updateDefinition, s.deleteDefinition} {
+      if definition != nil && definition.Description != "" {
+         doc.GraphQLDescription = definition.Description
+         break
+      }
+   }
 
    // Create GOES First!
    if s.createDefinition != nil {
       s.parse(s.createDefinition, doc)
+      s.addHandler(doc, "create", s.createDefinition.Name)
+      // read and list both go through the query that lists the entities, without it there's nothing to run
+      if s.ListQuery() != nil {
+         s.addHandler(doc, "read")
+         s.addHandler(doc, "list")
+      } else {
+         log.Warnf("Document: %s: no list query for %s, no read or list handler", s.serviceName, s.createDefinition.Name)
+      }
    }
    if s.updateDefinition != nil {
       s.parse(s.updateDefinition, doc)
+      s.addHandler(doc, "update", s.updateDefinition.Name)
    }
    if s.deleteDefinition != nil {
       s.parse(s.deleteDefinition, doc)
+      s.addHandler(doc, "delete", s.deleteDefinition.Name)
+   }
+
+   // Identifier arguments, e.g. channelId, come back from create
+   for _, handler := range []string{"update", "delete"} {
+      if arg := s.IdentifierArgument(s.GetOperation(handler)); arg != "" {
+         doc.AddReadOnlyProperty(arg)
+      }
+   }
+
+   // Arguments NerdGraph doesn't return, the handlers leave them out of the models they return
+   for _, arg := range s.config.WriteOnlyArguments(s.serviceName) {
+      if doc.Properties[strings.TrimPrefix(model.PropertyPath(arg), "/properties/")] == nil {
+         log.Warnf("Document: %s: writeOnly %s isn't a mutation argument", s.serviceName, arg)
+         continue
+      }
+      doc.AddWriteOnlyProperty(arg)
+   }
+
+   // Create arguments update doesn't take can only change by replacement
+   if s.createDefinition != nil && s.updateDefinition != nil {
+      for _, arg := range s.createDefinition.Arguments {
+         if s.updateDefinition.Arguments.ForName(arg.Name) == nil {
+            doc.AddCreateOnlyProperty(arg.Name)
+         }
+      }
+   }
+
+   s.applyTagging(doc)
+
+   // After the handlers, they all need permission to read it
+   if enabled, endpoint := s.config.TypeConfigurationConfig(s.serviceName); enabled {
+      doc.SetTypeConfiguration(model.NewTypeConfiguration(), model.NewAccessDefinition(endpoint))
    }
 
    // required must contain unique values
@@ -92,24 +139,38 @@ func (s *Service) Emit() {
       doc.Required = append(doc.Required, k)
    }
 
-   s.toFile(doc)
+   s.document = doc
+   return doc
+}
+
+// addHandler
+// add the configured handler along with the NerdGraph operations that implement it
+func (s *Service) addHandler(doc *model.Document, name string, operations ...string) {
+   hc := s.config.HandlerConfig(s.serviceName, name)
+   handler := model.NewHandler(hc.Permissions, hc.TimeoutInMinutes)
+   handler.Operations = append(handler.Operations, operations...)
+   doc.AddHandler(name, handler)
 }
 
 func (s *Service) GetName() string {
    return s.serviceName
 }
 
-func (s *Service) toFile(doc *model.Document) {
+func (s *Service) toFile(doc *model.Document, outDir string) {
    b, err := json.MarshalIndent(doc, "", "   ")
    if err != nil {
       log.Errorf("toFile: error: %v", err)
+      return
    }
 
-   fileName := strings.ToLower(doc.TypeName)
-   fileName = strings.ReplaceAll(fileName, "::", "-")
-   f, err := os.Create(fileName + ".json")
+   if err = os.MkdirAll(outDir, 0755); err != nil {
+      log.Errorf("toFile: error: %v", err)
+      return
+   }
+   f, err := os.Create(filepath.Join(outDir, doc.BaseName()+".json"))
    if err != nil {
       log.Errorf("toFile: error: %v", err)
+      return
    }
    defer f.Close()
 
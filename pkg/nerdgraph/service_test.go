@@ -0,0 +1,98 @@
+package nerdgraph_test
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+   "encoding/json"
+   "os"
+   "path/filepath"
+   "sort"
+   "strings"
+   "testing"
+)
+
+func TestServiceHandlers(t *testing.T) {
+   noChannelQuery := strings.Replace(fixture.Schema, "channels(cursor: String, filters: AiNotificationsChannelFilter): AiNotificationsChannelsResponse", "", 1)
+   tests := []struct {
+      name    string
+      sdl     string
+      service string
+      want    []string
+   }{
+      {"channel", fixture.Schema, "aiNotificationsChannel", []string{"create", "delete", "list", "read", "update"}},
+      {"no update", fixture.Schema, "aiNotificationsDestination", []string{"create", "delete", "list", "read"}},
+      {"no list query", noChannelQuery, "aiNotificationsChannel", []string{"create", "delete", "update"}},
+   }
+   for _, tt := range tests {
+      t.Run(tt.name, func(t *testing.T) {
+         services, err := fixture.ServicesFrom(tt.sdl, nil)
+         if err != nil {
+            t.Fatal(err)
+         }
+         got := make([]string, 0)
+         for name := range services[tt.service].Document().Handlers {
+            got = append(got, name)
+         }
+         sort.Strings(got)
+         if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+            t.Errorf("handlers %v, want %v", got, tt.want)
+         }
+      })
+   }
+}
+
+func TestEmitCreatesOutDir(t *testing.T) {
+   service, err := fixture.Service("aiNotificationsChannel", nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   dir := filepath.Join(t.TempDir(), "out", "schemas")
+   service.Emit(dir)
+   b, err := os.ReadFile(filepath.Join(dir, service.Document().BaseName()+".json"))
+   if err != nil {
+      t.Fatal(err)
+   }
+   doc := make(map[string]interface{})
+   if err = json.Unmarshal(b, &doc); err != nil {
+      t.Fatal(err)
+   }
+   if doc["typeName"] != "NewRelic::Observability::aiNotificationsChannel" {
+      t.Errorf("typeName %v", doc["typeName"])
+   }
+}
+
+func TestEmitUnwritableDir(t *testing.T) {
+   service, err := fixture.Service("aiNotificationsChannel", nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   // A file where the directory should be, logged rather than a panic writing to a nil file
+   file := filepath.Join(t.TempDir(), "file")
+   if err = os.WriteFile(file, nil, 0644); err != nil {
+      t.Fatal(err)
+   }
+   service.Emit(filepath.Join(file, "out"))
+}
+
+func TestServiceWriteOnly(t *testing.T) {
+   config := nerdgraph.NewConfig()
+   config.Services[fixture.Prefix] = &nerdgraph.ServiceConfig{WriteOnly: []string{"channel", "secret"}}
+   service, err := fixture.Service("aiNotificationsChannel", config)
+   if err != nil {
+      t.Fatal(err)
+   }
+   // Only the mutation's arguments
+   if got := service.Document().WriteOnlyProperties; len(got) != 1 || got[0] != "/properties/Channel" {
+      t.Errorf("writeOnlyProperties %v", got)
+   }
+   if got := service.Mapping().WriteOnlyProperties; len(got) != 1 || got[0] != "Channel" {
+      t.Errorf("mapping writeOnlyProperties %v", got)
+   }
+   other, err := fixture.Service("aiNotificationsChannel", nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   if got := other.Document().WriteOnlyProperties; len(got) != 0 {
+      t.Errorf("writeOnlyProperties %v without the config", got)
+   }
+}
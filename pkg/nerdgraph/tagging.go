@@ -0,0 +1,69 @@
+package nerdgraph
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+   log "github.com/sirupsen/logrus"
+   "github.com/vektah/gqlparser/v2/ast"
+)
+
+// applyTagging
+// set the document's tagging block, and any tag property, per the configured strategy
+func (s *Service) applyTagging(doc *model.Document) {
+   tagging := s.config.Tagging
+   switch tagging.Strategy {
+   case TaggingEntity:
+      if !s.hasGuid() {
+         log.Warnf("applyTagging: %s: the entity has no guid for the tagging mutations, resource is not taggable", s.serviceName)
+         doc.Tagging = model.NewTagging()
+         return
+      }
+      // NerdGraph entity tags are {key, values[]}, CloudFormation wants [{Key, Value}]
+      doc.Definitions[model.TagDefinitionName] = model.NewTagDefinition()
+      doc.AddProperty("tags", model.NewTagListProperty())
+      doc.Tagging = &model.Tagging{
+         Taggable:                 true,
+         TagOnCreate:              s.createDefinition != nil,
+         TagUpdatable:             s.updateDefinition != nil,
+         CloudFormationSystemTags: tagging.SystemTags,
+         TagProperty:              model.PropertyPath("tags"),
+      }
+      // Tags are applied to the entity after the resource's own mutation
+      if s.createDefinition != nil {
+         doc.Handlers["create"].Operations = append(doc.Handlers["create"].Operations, TagAddMutation)
+      }
+      if s.updateDefinition != nil {
+         doc.Handlers["update"].Operations = append(doc.Handlers["update"].Operations, TagReplaceMutation)
+      }
+   case TaggingArgument:
+      onCreate := hasArgument(s.createDefinition, tagging.Argument)
+      onUpdate := hasArgument(s.updateDefinition, tagging.Argument)
+      if !onCreate && !onUpdate {
+         log.Warnf("applyTagging: %s: no mutation has tag argument: %s, resource is not taggable", s.serviceName, tagging.Argument)
+         doc.Tagging = model.NewTagging()
+         return
+      }
+      doc.Tagging = &model.Tagging{
+         Taggable:                 true,
+         TagOnCreate:              onCreate,
+         TagUpdatable:             onUpdate,
+         CloudFormationSystemTags: tagging.SystemTags,
+         TagProperty:              model.PropertyPath(tagging.Argument),
+      }
+   default:
+      doc.Tagging = model.NewTagging()
+   }
+}
+
+// hasGuid
+// the tagging mutations take the entity's guid, an id isn't one
+func (s *Service) hasGuid() bool {
+   entity := s.EntityType()
+   return entity != nil && entity.Fields.ForName("guid") != nil
+}
+
+func hasArgument(field *ast.FieldDefinition, name string) bool {
+   if field == nil || name == "" {
+      return false
+   }
+   return field.Arguments.ForName(name) != nil
+}
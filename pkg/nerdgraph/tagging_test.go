@@ -0,0 +1,81 @@
+package nerdgraph_test
+
+import (
+   "GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+   "GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+   "encoding/json"
+   "testing"
+)
+
+func TestTaggingFlagsAreWritten(t *testing.T) {
+   tests := []struct {
+      name       string
+      service    string
+      systemTags bool
+      want       map[string]interface{}
+   }{
+      {"entity", "aiNotificationsChannel", true, map[string]interface{}{"taggable": true, "tagOnCreate": true, "tagUpdatable": true, "cloudFormationSystemTags": true}},
+      // No update mutation, the tags can't change without replacing the destination
+      {"no update", "aiNotificationsDestination", true, map[string]interface{}{"taggable": true, "tagOnCreate": true, "tagUpdatable": false, "cloudFormationSystemTags": true}},
+      {"no system tags", "aiNotificationsChannel", false, map[string]interface{}{"taggable": true, "tagOnCreate": true, "tagUpdatable": true, "cloudFormationSystemTags": false}},
+   }
+   for _, tt := range tests {
+      t.Run(tt.name, func(t *testing.T) {
+         config := nerdgraph.NewConfig()
+         config.Tagging.SystemTags = tt.systemTags
+         service, err := fixture.Entity(tt.service, config)
+         if err != nil {
+            t.Fatal(err)
+         }
+         b, err := json.Marshal(service.Document().Tagging)
+         if err != nil {
+            t.Fatal(err)
+         }
+         got := make(map[string]interface{})
+         if err = json.Unmarshal(b, &got); err != nil {
+            t.Fatal(err)
+         }
+         for key, want := range tt.want {
+            if v, ok := got[key]; !ok || v != want {
+               t.Errorf("%s: got %v (present %t), want %v", key, v, ok, want)
+            }
+         }
+      })
+   }
+}
+
+func TestEntityTaggingNeedsGuid(t *testing.T) {
+   // The fixture's channel has an id, the tagging mutations would get it as the guid
+   service, err := fixture.Service("aiNotificationsChannel", nil)
+   if err != nil {
+      t.Fatal(err)
+   }
+   doc := service.Document()
+   if doc.Tagging.Taggable || doc.Properties["Tags"] != nil {
+      t.Errorf("tagging %+v", doc.Tagging)
+   }
+   for _, handler := range []string{"create", "update"} {
+      for _, operation := range doc.Handlers[handler].Operations {
+         if operation == nerdgraph.TagAddMutation || operation == nerdgraph.TagReplaceMutation {
+            t.Errorf("%s runs %s", handler, operation)
+         }
+      }
+   }
+}
+
+func TestNotTaggableFlagsAreWritten(t *testing.T) {
+   config := nerdgraph.NewConfig()
+   config.Tagging.Strategy = nerdgraph.TaggingNone
+   service, err := fixture.Service("aiNotificationsChannel", config)
+   if err != nil {
+      t.Fatal(err)
+   }
+   b, err := json.Marshal(service.Document().Tagging)
+   if err != nil {
+      t.Fatal(err)
+   }
+   want := `{"taggable":false,"tagOnCreate":false,"tagUpdatable":false,"cloudFormationSystemTags":false}`
+   if string(b) != want {
+      t.Errorf("got %s, want %s", b, want)
+   }
+}
@@ -0,0 +1,396 @@
+package pulumi
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+/*
+Pulumi package schema (https://www.pulumi.com/docs/using-pulumi/pulumi-packages/schema/) for Services, from the same
+Documents the CloudFormation schemas come from, so a native provider's schema never drifts from them
+
+- A resource per Service, newrelic:<namespace>:<Name>. inputProperties/requiredInputs are the writable properties,
+  properties/required add the readOnlyProperties NerdGraph assigns
+- createOnlyProperties (every property when there's no update handler) are replaceOnChanges, writeOnlyProperties secret
+- Definitions are object types, enums enum types. A union property is a oneOf of its members, told apart by the
+  __typename discriminator each member gets
+- The type configuration's NerdGraph credentials are the provider's config, the tag property a map of strings
+*/
+
+// Name of the package, the first part of the resource tokens
+const Name = "newrelic"
+
+// Package the schema.json, just what's generated
+type Package struct {
+	Name        string                  `json:"name"`
+	DisplayName string                  `json:"displayName,omitempty"`
+	Description string                  `json:"description,omitempty"`
+	Config      *Config                 `json:"config,omitempty"`
+	Provider    *Resource               `json:"provider,omitempty"`
+	Resources   map[string]*Resource    `json:"resources"`
+	Types       map[string]*ComplexType `json:"types"`
+}
+
+type Config struct {
+	Variables map[string]*Property `json:"variables"`
+	Defaults  []string             `json:"defaults,omitempty"`
+}
+
+// Resource a resource, or the provider
+type Resource struct {
+	Description     string               `json:"description,omitempty"`
+	Properties      map[string]*Property `json:"properties,omitempty"`
+	Required        []string             `json:"required,omitempty"`
+	InputProperties map[string]*Property `json:"inputProperties,omitempty"`
+	RequiredInputs  []string             `json:"requiredInputs,omitempty"`
+}
+
+// ComplexType an object or enum type
+type ComplexType struct {
+	Description string               `json:"description,omitempty"`
+	Type        string               `json:"type"`
+	Properties  map[string]*Property `json:"properties,omitempty"`
+	Required    []string             `json:"required,omitempty"`
+	Enum        []*EnumValue         `json:"enum,omitempty"`
+}
+
+type EnumValue struct {
+	Name  string `json:"name,omitempty"`
+	Value string `json:"value"`
+}
+
+// Property a property's type and how it's handled
+type Property struct {
+	Description          string         `json:"description,omitempty"`
+	Type                 string         `json:"type,omitempty"`
+	Ref                  string         `json:"$ref,omitempty"`
+	Items                *Property      `json:"items,omitempty"`
+	AdditionalProperties *Property      `json:"additionalProperties,omitempty"`
+	OneOf                []*Property    `json:"oneOf,omitempty"`
+	Discriminator        *Discriminator `json:"discriminator,omitempty"`
+	Const                interface{}    `json:"const,omitempty"`
+	Default              interface{}    `json:"default,omitempty"`
+	Secret               bool           `json:"secret,omitempty"`
+	ReplaceOnChanges     bool           `json:"replaceOnChanges,omitempty"`
+}
+
+type Discriminator struct {
+	PropertyName string            `json:"propertyName"`
+	Mapping      map[string]string `json:"mapping"`
+}
+
+// discriminator the property union members are told apart by, GraphQL's
+const discriminator = "__typename"
+
+// Generate
+// write dir/schema.json for all the services
+func Generate(services []*nerdgraph.Service, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("pulumi: %w", err)
+	}
+	b, err := json.MarshalIndent(New(services), "", "   ")
+	if err != nil {
+		return fmt.Errorf("pulumi: %w", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "schema.json"), append(b, '\n'), 0644); err != nil {
+		return fmt.Errorf("pulumi: %w", err)
+	}
+	return nil
+}
+
+// New
+// the package, a resource per service and the types they use. The same GraphQL type in the same namespace is one type
+func New(services []*nerdgraph.Service) *Package {
+	pkg := &Package{
+		Name:        Name,
+		DisplayName: "New Relic",
+		Description: "New Relic resources managed through NerdGraph",
+		Resources:   make(map[string]*Resource),
+		Types:       make(map[string]*ComplexType),
+	}
+	sorted := append([]*nerdgraph.Service{}, services...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].GetName() < sorted[j].GetName() })
+	for _, service := range sorted {
+		doc := service.Document()
+		g := &generator{doc: doc, module: Module(doc), types: pkg.Types}
+		pkg.Resources[Token(doc)] = g.resource()
+		if pkg.Config == nil && doc.TypeConfiguration != nil {
+			pkg.Config, pkg.Provider = g.config()
+		}
+	}
+	return pkg
+}
+
+// Module
+// the namespace in the TypeName, NewRelic::Observability::aiNotificationsChannel -> observability
+func Module(doc *model.Document) string {
+	parts := strings.Split(doc.TypeName, "::")
+	if len(parts) < 3 {
+		return "index"
+	}
+	return strings.ToLower(strings.Join(parts[1:len(parts)-1], "/"))
+}
+
+// Token
+// the resource's token, NewRelic::Observability::aiNotificationsChannel -> newrelic:observability:AiNotificationsChannel
+func Token(doc *model.Document) string {
+	parts := strings.Split(doc.TypeName, "::")
+	return Name + ":" + Module(doc) + ":" + upperFirst(parts[len(parts)-1])
+}
+
+type generator struct {
+	doc    *model.Document
+	module string
+	types  map[string]*ComplexType
+}
+
+// resource
+// the inputs are the writable properties, the outputs all of them
+func (g *generator) resource() *Resource {
+	readOnly := paths(g.doc.ReadOnlyProperties)
+	writeOnly := paths(g.doc.WriteOnlyProperties)
+	createOnly := paths(g.doc.CreateOnlyProperties)
+	immutable := g.doc.Handlers["update"] == nil
+	tagProperty := ""
+	if g.doc.Tagging != nil {
+		tagProperty = strings.TrimPrefix(g.doc.Tagging.TagProperty, "/properties/")
+	}
+
+	r := &Resource{
+		Description:     description(g.doc.Description, g.doc.GraphQLDescription),
+		Properties:      make(map[string]*Property),
+		InputProperties: make(map[string]*Property),
+	}
+	for _, name := range sortedKeys(g.doc.Properties) {
+		property := g.doc.Properties[name]
+		field := property.OriginalName(name)
+		var p *Property
+		if name == tagProperty {
+			p = &Property{Type: "object", AdditionalProperties: &Property{Type: "string"}}
+		} else {
+			p = g.property(property)
+		}
+		p.Secret = p.Secret || writeOnly[name]
+		if readOnly[name] {
+			r.Properties[field] = p
+			r.Required = append(r.Required, field)
+			continue
+		}
+		p.ReplaceOnChanges = createOnly[name] || immutable
+		input := *p
+		r.InputProperties[field] = &input
+		if !writeOnly[name] {
+			r.Properties[field] = p
+		}
+		if contains(g.doc.Required, name) {
+			r.RequiredInputs = append(r.RequiredInputs, field)
+			if !writeOnly[name] {
+				r.Required = append(r.Required, field)
+			}
+		}
+	}
+	sort.Strings(r.Required)
+	return r
+}
+
+// config
+// the type configuration's access definition as config variables, and the provider's inputs
+func (g *generator) config() (*Config, *Resource) {
+	config := &Config{Variables: make(map[string]*Property)}
+	provider := &Resource{Description: "The NerdGraph credentials and endpoint", InputProperties: make(map[string]*Property)}
+	for _, name := range sortedKeys(g.doc.TypeConfiguration.Properties) {
+		def := g.resolve(g.doc.TypeConfiguration.Properties[name])
+		for _, field := range sortedKeys(def.Properties) {
+			property := def.Properties[field]
+			p := g.property(property)
+			p.Secret = property.WriteOnly || property.Sensitive
+			config.Variables[property.OriginalName(field)] = p
+			provider.InputProperties[property.OriginalName(field)] = p
+			if contains(def.Required, field) {
+				config.Defaults = append(config.Defaults, property.OriginalName(field))
+			}
+		}
+	}
+	return config, provider
+}
+
+// property
+// a property's type, definitions are added to types as they're referenced
+func (g *generator) property(property *model.Property) *Property {
+	p := &Property{Description: description(property.Description, property.GraphQLDescription), Default: property.Default, Secret: property.Sensitive}
+	switch {
+	case property.Type == "array":
+		p.Type = "array"
+		if property.Items != nil {
+			p.Items = g.item(property.Items)
+		} else {
+			p.Items = &Property{Ref: "pulumi.json#/Any"}
+		}
+	case property.Ref != "":
+		t := g.ref(property.Ref)
+		p.Ref, p.Type, p.OneOf, p.Discriminator = t.Ref, t.Type, t.OneOf, t.Discriminator
+	case len(property.AnyOf) > 0 || len(property.OneOf) > 0:
+		g.union(p, append(append([]*model.Item{}, property.AnyOf...), property.OneOf...))
+	case property.Type == "object" || property.Type == "":
+		p.Type = "object"
+		p.AdditionalProperties = &Property{Ref: "pulumi.json#/Any"}
+	default:
+		p.Type = property.Type
+	}
+	return p
+}
+
+// item
+// an array's items
+func (g *generator) item(item *model.Item) *Property {
+	switch {
+	case item.Ref != "":
+		return g.ref(item.Ref)
+	case len(item.AnyOf) > 0:
+		p := &Property{}
+		g.union(p, item.AnyOf)
+		return p
+	case item.Type == "" || item.Type == "object":
+		return &Property{Ref: "pulumi.json#/Any"}
+	}
+	return &Property{Type: item.Type}
+}
+
+// ref
+// a definition: scalars inline, unions a oneOf, objects and enums a $ref to their type
+func (g *generator) ref(ref string) *Property {
+	name := strings.TrimPrefix(ref, "#/definitions/")
+	def := g.doc.Definitions[name]
+	if def == nil {
+		return &Property{Ref: "pulumi.json#/Any"}
+	}
+	if len(def.AnyOf) > 0 || len(def.OneOf) > 0 {
+		p := &Property{}
+		g.union(p, append(append([]*model.Item{}, def.AnyOf...), def.OneOf...))
+		return p
+	}
+	if len(def.Enum) == 0 && len(def.Properties) == 0 {
+		if def.Type == "" || def.Type == "object" {
+			return &Property{Type: "object", AdditionalProperties: &Property{Ref: "pulumi.json#/Any"}}
+		}
+		return &Property{Type: def.Type}
+	}
+	token := g.typeToken(name)
+	if _, ok := g.types[token]; !ok {
+		g.addType(token, def)
+	}
+	return &Property{Ref: "#/types/" + token}
+}
+
+// addType
+// an enum or object type for the definition
+func (g *generator) addType(token string, def *model.Property) {
+	t := &ComplexType{Description: description(def.Description, def.GraphQLDescription)}
+	g.types[token] = t
+	if len(def.Enum) > 0 {
+		t.Type = "string"
+		for _, value := range def.Enum {
+			t.Enum = append(t.Enum, &EnumValue{Name: enumName(value), Value: value})
+		}
+		return
+	}
+	t.Type = "object"
+	t.Properties = make(map[string]*Property, len(def.Properties))
+	for _, field := range sortedKeys(def.Properties) {
+		property := def.Properties[field]
+		t.Properties[property.OriginalName(field)] = g.property(property)
+		if contains(def.Required, field) {
+			t.Required = append(t.Required, property.OriginalName(field))
+		}
+	}
+	sort.Strings(t.Required)
+}
+
+// union
+// oneOf the members' types, each member gets the __typename discriminator
+func (g *generator) union(p *Property, members []*model.Item) {
+	p.Type = "object"
+	p.Discriminator = &Discriminator{PropertyName: discriminator, Mapping: make(map[string]string)}
+	for _, member := range members {
+		m := g.item(member)
+		p.OneOf = append(p.OneOf, m)
+		name := strings.TrimPrefix(member.Ref, "#/definitions/")
+		if t := g.types[strings.TrimPrefix(m.Ref, "#/types/")]; t != nil && t.Type == "object" {
+			t.Properties[discriminator] = &Property{Type: "string", Const: name}
+			p.Discriminator.Mapping[name] = m.Ref
+		}
+	}
+}
+
+// resolve
+// the definition a property references, the property itself otherwise
+func (g *generator) resolve(property *model.Property) *model.Property {
+	if def := g.doc.Definitions[strings.TrimPrefix(property.Ref, "#/definitions/")]; property.Ref != "" && def != nil {
+		return def
+	}
+	return property
+}
+
+// typeToken
+// newrelic:<module>:<GraphQL type name>
+func (g *generator) typeToken(name string) string {
+	return Name + ":" + g.module + ":" + name
+}
+
+// enumName
+// the value in PascalCase, NOT_A_VALUE -> NotAValue
+func enumName(value string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		b.WriteString(upperFirst(strings.ToLower(part)))
+	}
+	return b.String()
+}
+
+func upperFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// description
+// the schema's description, the GraphQL one when there's none
+func description(schema string, graphQL string) string {
+	if schema != "" {
+		return schema
+	}
+	return strings.TrimSpace(graphQL)
+}
+
+func paths(p []string) map[string]bool {
+	m := make(map[string]bool, len(p))
+	for _, path := range p {
+		m[strings.TrimPrefix(path, "/properties/")] = true
+	}
+	return m
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]*model.Property) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+package pulumi_test
+
+import (
+	"GraphQLSchema-to-CloudFormationSchema/internal/fixture"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/aws/cloudformation/model"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/nerdgraph"
+	"GraphQLSchema-to-CloudFormationSchema/pkg/pulumi"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// services
+// the services built from the SDL in a list, as gqlparser passes them
+func services(t *testing.T, sdl string, config *nerdgraph.Config) []*nerdgraph.Service {
+	t.Helper()
+	m, err := fixture.ServicesFrom(sdl, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	l := make([]*nerdgraph.Service, 0, len(m))
+	for _, name := range names {
+		l = append(l, m[name])
+	}
+	return l
+}
+
+func TestGenerateGolden(t *testing.T) {
+	dir := t.TempDir()
+	if err := pulumi.Generate(services(t, fixture.Schema, nil), dir); err != nil {
+		t.Fatal(err)
+	}
+	fixture.GoldenFile(t, filepath.Join("testdata", "schema.json"), filepath.Join(dir, "schema.json"))
+}
+
+func TestNew(t *testing.T) {
+	config := nerdgraph.NewConfig()
+	config.Services[fixture.Prefix] = &nerdgraph.ServiceConfig{WriteOnly: []string{"accountId"}}
+	pkg := pulumi.New(services(t, fixture.Schema, config))
+
+	channel := pkg.Resources["newrelic:observability:AiNotificationsChannel"]
+	destination := pkg.Resources["newrelic:observability:AiNotificationsDestination"]
+	if channel == nil || destination == nil || len(pkg.Resources) != 2 {
+		t.Fatalf("resources %v", pkg.Resources)
+	}
+	// NerdGraph assigns guid, it's an output only
+	if _, ok := channel.InputProperties["guid"]; ok || channel.Properties["guid"] == nil {
+		t.Error("guid is an input")
+	}
+	// The secret goes in, never comes back
+	if p := channel.InputProperties["accountId"]; p == nil || !p.Secret {
+		t.Errorf("accountId input %+v", p)
+	}
+	if _, ok := channel.Properties["accountId"]; ok {
+		t.Error("writeOnly accountId is an output")
+	}
+	if strings.Join(channel.RequiredInputs, ",") != "accountId,channel" {
+		t.Errorf("requiredInputs %v", channel.RequiredInputs)
+	}
+	// The channel has an update mutation, the destination can only be replaced
+	for name, p := range channel.InputProperties {
+		if p.ReplaceOnChanges {
+			t.Errorf("channel %s is replaceOnChanges", name)
+		}
+	}
+	for name, p := range destination.InputProperties {
+		if !p.ReplaceOnChanges {
+			t.Errorf("destination %s isn't replaceOnChanges", name)
+		}
+	}
+	// NerdGraph's channel has no guid to tag
+	if _, ok := channel.InputProperties["tags"]; ok {
+		t.Error("the channel has tags")
+	}
+
+	// The type configuration's credentials are the provider's
+	if pkg.Config == nil || pkg.Provider == nil {
+		t.Fatal("no config")
+	}
+	if key := pkg.Config.Variables["apiKey"]; key == nil || !key.Secret || pkg.Provider.InputProperties["apiKey"] != key {
+		t.Errorf("apiKey %+v", key)
+	}
+	if strings.Join(pkg.Config.Defaults, ",") != "apiKey" {
+		t.Errorf("defaults %v", pkg.Config.Defaults)
+	}
+
+	// Every $ref is a type in the package, both resources share the ones they have in common
+	refs := 0
+	var check func(p *pulumi.Property)
+	check = func(p *pulumi.Property) {
+		if p == nil {
+			return
+		}
+		if strings.HasPrefix(p.Ref, "#/types/") {
+			refs++
+			if pkg.Types[strings.TrimPrefix(p.Ref, "#/types/")] == nil {
+				t.Errorf("$ref %s doesn't resolve", p.Ref)
+			}
+		}
+		check(p.Items)
+		check(p.AdditionalProperties)
+		for _, member := range p.OneOf {
+			check(member)
+		}
+	}
+	for _, r := range pkg.Resources {
+		for _, p := range r.Properties {
+			check(p)
+		}
+		for _, p := range r.InputProperties {
+			check(p)
+		}
+	}
+	for _, ct := range pkg.Types {
+		for _, p := range ct.Properties {
+			check(p)
+		}
+	}
+	if refs == 0 {
+		t.Error("no $refs")
+	}
+	if ct := pkg.Types["newrelic:observability:AiNotificationsChannelType"]; ct == nil || ct.Type != "string" || len(ct.Enum) != 3 || ct.Enum[0].Name != "Email" || ct.Enum[0].Value != "EMAIL" {
+		t.Errorf("AiNotificationsChannelType %+v", ct)
+	}
+}
+
+func TestToken(t *testing.T) {
+	tests := []struct {
+		typeName string
+		module   string
+		token    string
+	}{
+		{"NewRelic::Observability::aiNotificationsChannel", "observability", "newrelic:observability:AiNotificationsChannel"},
+		{"NewRelic::Cloud::Aws::thing", "cloud/aws", "newrelic:cloud/aws:Thing"},
+		{"thing", "index", "newrelic:index:Thing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.typeName, func(t *testing.T) {
+			doc := &model.Document{TypeName: tt.typeName}
+			if got := pulumi.Module(doc); got != tt.module {
+				t.Errorf("module %s, want %s", got, tt.module)
+			}
+			if got := pulumi.Token(doc); got != tt.token {
+				t.Errorf("token %s, want %s", got, tt.token)
+			}
+		})
+	}
+}
+
+func TestNewTags(t *testing.T) {
+	pkg := pulumi.New(services(t, fixture.EntitySchema, nil))
+	channel := pkg.Resources["newrelic:observability:AiNotificationsChannel"]
+	if channel == nil {
+		t.Fatalf("resources %v", pkg.Resources)
+	}
+	if p := channel.InputProperties["tags"]; p == nil || p.Type != "object" || p.AdditionalProperties == nil || p.AdditionalProperties.Type != "string" {
+		t.Errorf("tags %+v", p)
+	}
+}